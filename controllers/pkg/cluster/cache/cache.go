@@ -0,0 +1,184 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+const (
+	// DefaultIdleTimeout is the time after which an unused cluster client is
+	// evicted from the cache
+	DefaultIdleTimeout = 30 * time.Minute
+)
+
+var (
+	defaultCache *Cache
+	defaultOnce  sync.Once
+)
+
+// Default returns the process wide cluster client cache
+func Default() *Cache {
+	defaultOnce.Do(func() {
+		defaultCache = New(DefaultIdleTimeout)
+	})
+	return defaultCache
+}
+
+// ConfigFn returns the rest config used to build a cluster client
+type ConfigFn func() (*rest.Config, error)
+
+// Cache holds the clients of remote clusters, keyed by the secret that
+// provides the cluster credentials. An entry is rebuilt when the
+// resourceVersion of the secret changes and evicted when it is not used
+// during the idle timeout.
+type Cache struct {
+	m           sync.Mutex
+	entries     map[types.NamespacedName]*entry
+	idleTimeout time.Duration
+	// now and newClient can be overwritten for testing
+	now       func() time.Time
+	newClient func(*rest.Config) (client.Client, error)
+}
+
+type entry struct {
+	resourceVersion string
+	client          resource.APIPatchingApplicator
+	lastUsed        time.Time
+	// apiChecked is the time the api server last answered the client
+	apiChecked time.Time
+}
+
+func getKey(secret *corev1.Secret) types.NamespacedName {
+	return types.NamespacedName{Namespace: secret.GetNamespace(), Name: secret.GetName()}
+}
+
+// New returns a cluster client cache; an idleTimeout of 0 disables eviction
+func New(idleTimeout time.Duration) *Cache {
+	return &Cache{
+		entries:     map[types.NamespacedName]*entry{},
+		idleTimeout: idleTimeout,
+		now:         time.Now,
+		newClient:   newClient,
+	}
+}
+
+// Get returns the cached client for the cluster identified by the secret.
+// A new client is built using the configFn when no client is cached or when
+// the secret was updated since the cached client was built.
+func (r *Cache) Get(secret *corev1.Secret, configFn ConfigFn) (resource.APIPatchingApplicator, error) {
+	key := getKey(secret)
+
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	now := r.now()
+	r.evictIdle(now)
+
+	if e, ok := r.entries[key]; ok && e.resourceVersion == secret.GetResourceVersion() {
+		e.lastUsed = now
+		return e.client, nil
+	}
+	// the secret is new or was rotated, so we build a new client
+	delete(r.entries, key)
+	config, err := configFn()
+	if err != nil {
+		return resource.APIPatchingApplicator{}, err
+	}
+	c, err := r.newClient(config)
+	if err != nil {
+		return resource.APIPatchingApplicator{}, err
+	}
+	e := &entry{
+		resourceVersion: secret.GetResourceVersion(),
+		client:          resource.NewAPIPatchingApplicator(c),
+		lastUsed:        now,
+	}
+	r.entries[key] = e
+	return e.client, nil
+}
+
+// IsAPICheckDue returns true when the api server of the cluster identified by
+// the secret was not checked with its cached client within the interval, a
+// newly built client was not checked yet
+func (r *Cache) IsAPICheckDue(secret *corev1.Secret, interval time.Duration) bool {
+	r.m.Lock()
+	defer r.m.Unlock()
+	e, ok := r.entries[getKey(secret)]
+	return !ok || e.apiChecked.IsZero() || r.now().Sub(e.apiChecked) >= interval
+}
+
+// SetAPIChecked records that the api server of the cluster identified by the
+// secret answered its cached client
+func (r *Cache) SetAPIChecked(secret *corev1.Secret) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if e, ok := r.entries[getKey(secret)]; ok {
+		e.apiChecked = r.now()
+	}
+}
+
+// Invalidate removes the client of the cluster identified by the secret key
+func (r *Cache) Invalidate(key types.NamespacedName) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	delete(r.entries, key)
+}
+
+// Len returns the amount of cached clients
+func (r *Cache) Len() int {
+	r.m.Lock()
+	defer r.m.Unlock()
+	return len(r.entries)
+}
+
+func (r *Cache) evictIdle(now time.Time) {
+	if r.idleTimeout == 0 {
+		return
+	}
+	for key, e := range r.entries {
+		if now.Sub(e.lastUsed) > r.idleTimeout {
+			delete(r.entries, key)
+		}
+	}
+}
+
+// newClient builds a cluster client which shares one http client between the
+// client and its rest mapper; the rest mapper only runs discovery for an
+// api group when it is first used
+func newClient(config *rest.Config) (client.Client, error) {
+	httpClient, err := rest.HTTPClientFor(config)
+	if err != nil {
+		return nil, err
+	}
+	mapper, err := apiutil.NewDynamicRESTMapper(config, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	return client.New(config, client.Options{
+		HTTPClient: httpClient,
+		Mapper:     mapper,
+	})
+}
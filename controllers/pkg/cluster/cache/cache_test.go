@@ -0,0 +1,184 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func getSecret(name, resourceVersion string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "default",
+			Name:            name,
+			ResourceVersion: resourceVersion,
+		},
+	}
+}
+
+func TestGet(t *testing.T) {
+	type get struct {
+		secret  *corev1.Secret
+		advance time.Duration
+	}
+	cases := map[string]struct {
+		gets      []get
+		configErr bool
+		wantBuild int
+		wantLen   int
+		wantErr   bool
+	}{
+		"Single": {
+			gets:      []get{{secret: getSecret("a-kubeconfig", "1")}},
+			wantBuild: 1,
+			wantLen:   1,
+		},
+		"Cached": {
+			gets: []get{
+				{secret: getSecret("a-kubeconfig", "1")},
+				{secret: getSecret("a-kubeconfig", "1"), advance: time.Minute},
+			},
+			wantBuild: 1,
+			wantLen:   1,
+		},
+		"Rotated": {
+			gets: []get{
+				{secret: getSecret("a-kubeconfig", "1")},
+				{secret: getSecret("a-kubeconfig", "2")},
+			},
+			wantBuild: 2,
+			wantLen:   1,
+		},
+		"MultipleClusters": {
+			gets: []get{
+				{secret: getSecret("a-kubeconfig", "1")},
+				{secret: getSecret("b-kubeconfig", "1")},
+			},
+			wantBuild: 2,
+			wantLen:   2,
+		},
+		"Idle": {
+			gets: []get{
+				{secret: getSecret("a-kubeconfig", "1")},
+				{secret: getSecret("b-kubeconfig", "1"), advance: 2 * time.Hour},
+			},
+			wantBuild: 2,
+			wantLen:   1,
+		},
+		"ConfigError": {
+			gets:      []get{{secret: getSecret("a-kubeconfig", "1")}},
+			configErr: true,
+			wantBuild: 0,
+			wantLen:   0,
+			wantErr:   true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now()
+			builds := 0
+			c := New(time.Hour)
+			c.now = func() time.Time { return now }
+			c.newClient = func(*rest.Config) (client.Client, error) {
+				builds++
+				return fake.NewClientBuilder().Build(), nil
+			}
+			configFn := func() (*rest.Config, error) {
+				if tc.configErr {
+					return nil, fmt.Errorf("invalid kubeconfig")
+				}
+				return &rest.Config{}, nil
+			}
+
+			var err error
+			for _, g := range tc.gets {
+				now = now.Add(g.advance)
+				_, err = c.Get(g.secret, configFn)
+			}
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Get() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.wantBuild, builds); diff != "" {
+				t.Errorf("builds -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantLen, c.Len()); diff != "" {
+				t.Errorf("len -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestInvalidate(t *testing.T) {
+	c := New(0)
+	c.newClient = func(*rest.Config) (client.Client, error) {
+		return fake.NewClientBuilder().Build(), nil
+	}
+	configFn := func() (*rest.Config, error) { return &rest.Config{}, nil }
+	if _, err := c.Get(getSecret("a-kubeconfig", "1"), configFn); err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	c.Invalidate(types.NamespacedName{Namespace: "default", Name: "a-kubeconfig"})
+	if diff := cmp.Diff(0, c.Len()); diff != "" {
+		t.Errorf("len -want, +got:\n%s", diff)
+	}
+}
+
+func TestIsAPICheckDue(t *testing.T) {
+	now := time.Now()
+	c := New(0)
+	c.now = func() time.Time { return now }
+	c.newClient = func(*rest.Config) (client.Client, error) {
+		return fake.NewClientBuilder().Build(), nil
+	}
+	configFn := func() (*rest.Config, error) { return &rest.Config{}, nil }
+	secret := getSecret("a-kubeconfig", "1")
+	if _, err := c.Get(secret, configFn); err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	if !c.IsAPICheckDue(secret, time.Minute) {
+		t.Errorf("want check due for a new client")
+	}
+	c.SetAPIChecked(secret)
+	now = now.Add(30 * time.Second)
+	if c.IsAPICheckDue(secret, time.Minute) {
+		t.Errorf("want no check due within the interval")
+	}
+	now = now.Add(time.Minute)
+	if !c.IsAPICheckDue(secret, time.Minute) {
+		t.Errorf("want check due after the interval")
+	}
+	c.SetAPIChecked(secret)
+	// a rotated secret builds a new client, which is checked again
+	if _, err := c.Get(getSecret("a-kubeconfig", "2"), configFn); err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	if !c.IsAPICheckDue(secret, time.Minute) {
+		t.Errorf("want check due for a rebuilt client")
+	}
+}
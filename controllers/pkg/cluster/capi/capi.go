@@ -23,10 +23,14 @@ import (
 	"strings"
 
 	"github.com/go-logr/logr"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/cache"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/clustersecret"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/readiness"
 	"github.com/nephio-project/nephio/controllers/pkg/resource"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -35,12 +39,24 @@ import (
 
 const (
 	kubeConfigSuffix = "-kubeconfig"
+	secretType       = "cluster.x-k8s.io/secret"
 )
 
 type Capi struct {
 	client.Client
 	Secret *corev1.Secret
-	l      logr.Logger
+	// Cache holds the cluster clients, if nil a new client is build on every call
+	Cache *cache.Cache
+	// Readiness defines the checks performed on the cluster once cluster api
+	// reports the cluster as ready, the api server reachability of a cached
+	// cluster client is checked every minute
+	Readiness readiness.Options
+	l         logr.Logger
+}
+
+// IsCapiSecret returns true if the secret is a cluster api kubeconfig secret
+func IsCapiSecret(secret *corev1.Secret) bool {
+	return string(secret.Type) == secretType && strings.Contains(secret.GetName(), "kubeconfig")
 }
 
 func (r *Capi) GetClusterName() string {
@@ -50,56 +66,50 @@ func (r *Capi) GetClusterName() string {
 	return strings.TrimSuffix(r.Secret.GetName(), kubeConfigSuffix)
 }
 
-func (r *Capi) GetClusterClient(ctx context.Context) (resource.APIPatchingApplicator, bool, error) {
-	if !r.isCapiClusterReady(ctx) {
-		return resource.APIPatchingApplicator{}, false, nil
+func (r *Capi) GetClusterClient(ctx context.Context) (resource.APIPatchingApplicator, readiness.Status, error) {
+	status, err := r.isCapiClusterReady(ctx)
+	if err != nil || !status.Ready {
+		return resource.APIPatchingApplicator{}, status, err
 	}
-	return getCapiClusterClient(r.Secret)
+	return clustersecret.GetClusterClient(ctx, r.Secret, r.Cache, r.Readiness, func() (*rest.Config, error) {
+		//provide a rest config from the secret value
+		return clientcmd.RESTConfigFromKubeConfig(r.Secret.Data["value"])
+	})
 }
 
-func (r *Capi) isCapiClusterReady(ctx context.Context) bool {
+func (r *Capi) isCapiClusterReady(ctx context.Context) (readiness.Status, error) {
 	r.l = log.FromContext(ctx)
 	name := r.GetClusterName()
 
 	cl := resource.GetUnstructuredFromGVK(&schema.GroupVersionKind{Group: capiv1beta1.GroupVersion.Group, Version: capiv1beta1.GroupVersion.Version, Kind: reflect.TypeFor[capiv1beta1.Cluster]().Name()})
 	if err := r.Get(ctx, types.NamespacedName{Namespace: r.Secret.GetNamespace(), Name: name}, cl); err != nil {
-		r.l.Error(err, "cannot get cluster")
-		return false
+		if resource.IgnoreNotFound(err) != nil {
+			r.l.Error(err, "cannot get cluster")
+			return readiness.NotReady(readiness.ReasonNotProvisioned, "cannot get cluster: %s", err.Error()), err
+		}
+		return readiness.NotReady(readiness.ReasonNotProvisioned, "cluster %s not found", name), nil
 	}
 	b, err := json.Marshal(cl)
 	if err != nil {
 		r.l.Error(err, "cannot marshal cluster")
-		return false
+		return readiness.NotReady(readiness.ReasonNotProvisioned, "cannot marshal cluster: %s", err.Error()), err
 	}
 	cluster := &capiv1beta1.Cluster{}
 	if err := json.Unmarshal(b, cluster); err != nil {
 		r.l.Error(err, "cannot unmarshal cluster")
-		return false
+		return readiness.NotReady(readiness.ReasonNotProvisioned, "cannot unmarshal cluster: %s", err.Error()), err
 	}
-	return isReady(cluster.GetConditions())
+	return getReadyStatus(cluster.GetConditions()), nil
 }
 
-func isReady(cs capiv1beta1.Conditions) bool {
+func getReadyStatus(cs capiv1beta1.Conditions) readiness.Status {
 	for _, c := range cs {
 		if c.Type == capiv1beta1.ReadyCondition {
 			if c.Status == corev1.ConditionTrue {
-				return true
+				return readiness.Ready()
 			}
+			return readiness.NotReady(readiness.ReasonNotProvisioned, "cluster api reports not ready: %s %s", c.Reason, c.Message)
 		}
 	}
-	return false
-}
-
-func getCapiClusterClient(secret *corev1.Secret) (resource.APIPatchingApplicator, bool, error) {
-	//provide a rest config from the secret value
-	config, err := clientcmd.RESTConfigFromKubeConfig(secret.Data["value"])
-	if err != nil {
-		return resource.APIPatchingApplicator{}, false, err
-	}
-	// build a cluster client from the kube rest config
-	clClient, err := client.New(config, client.Options{})
-	if err != nil {
-		return resource.APIPatchingApplicator{}, false, err
-	}
-	return resource.NewAPIPatchingApplicator(clClient), true, nil
+	return readiness.NotReady(readiness.ReasonNotProvisioned, "cluster api reports no ready condition")
 }
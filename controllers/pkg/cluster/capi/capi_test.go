@@ -20,8 +20,10 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/readiness"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
 func TestGetClusterName(t *testing.T) {
@@ -61,3 +63,36 @@ func TestGetClusterName(t *testing.T) {
 		})
 	}
 }
+
+func TestGetReadyStatus(t *testing.T) {
+	cases := map[string]struct {
+		conditions capiv1beta1.Conditions
+		want       bool
+	}{
+		"NoConditions": {
+			conditions: capiv1beta1.Conditions{},
+			want:       false,
+		},
+		"Ready": {
+			conditions: capiv1beta1.Conditions{{Type: capiv1beta1.ReadyCondition, Status: corev1.ConditionTrue}},
+			want:       true,
+		},
+		"NotReady": {
+			conditions: capiv1beta1.Conditions{{Type: capiv1beta1.ReadyCondition, Status: corev1.ConditionFalse, Reason: "WaitingForControlPlane"}},
+			want:       false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := getReadyStatus(tc.conditions)
+
+			if diff := cmp.Diff(tc.want, got.Ready); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+			if !got.Ready && got.Reason != readiness.ReasonNotProvisioned {
+				t.Errorf("want reason %s, got: %s", readiness.ReasonNotProvisioned, got.Reason)
+			}
+		})
+	}
+}
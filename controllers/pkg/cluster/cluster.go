@@ -19,28 +19,101 @@ package cluster
 import (
 	"context"
 	"strings"
+	"sync"
 
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/cache"
 	"github.com/nephio-project/nephio/controllers/pkg/cluster/capi"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/clustersecret"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/kubeconfig"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/readiness"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/token"
 	"github.com/nephio-project/nephio/controllers/pkg/resource"
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+const (
+	// ClusterNameKey is the label key identifying the cluster of a secret
+	// that is not managed by cluster api
+	ClusterNameKey = clustersecret.ClusterNameKey
+)
+
 type Cluster struct {
 	client.Client
+	// Cache holds the cluster clients, if nil the process wide cache is used
+	Cache *cache.Cache
+	// Readiness defines the checks performed on top of the readiness reported
+	// by the cluster provider
+	Readiness readiness.Options
+}
+
+// A Provider returns a ClusterClient if it recognises the secret as the
+// credentials of a cluster
+type Provider func(c client.Client, cache *cache.Cache, opts readiness.Options, secret *corev1.Secret) (ClusterClient, bool)
+
+var (
+	providerLock = &sync.RWMutex{}
+	providers    = []Provider{capiProvider, kubeconfigProvider, tokenProvider}
+)
+
+// RegisterProvider adds a provider which is consulted after the providers
+// that are already registered
+func RegisterProvider(p Provider) {
+	providerLock.Lock()
+	defer providerLock.Unlock()
+	providers = append(providers, p)
 }
 
 func (r Cluster) GetClusterClient(secret *corev1.Secret) (ClusterClient, bool) {
-	switch string(secret.Type) {
-	case "cluster.x-k8s.io/secret":
-		if strings.Contains(secret.GetName(), "kubeconfig") {
-			return &capi.Capi{Client: r.Client, Secret: secret}, true
+	providerLock.RLock()
+	defer providerLock.RUnlock()
+	for _, p := range providers {
+		if clusterClient, ok := p(r.Client, r.getCache(), r.Readiness, secret); ok {
+			return clusterClient, true
 		}
 	}
 	return nil, false
 }
 
+// IsClusterSecret returns true if the secret could hold the credentials of the
+// cluster; capi secrets are identified by name, other secrets by the
+// cluster-name label
+func IsClusterSecret(secret *corev1.Secret, clusterName string) bool {
+	return strings.Contains(secret.GetName(), clusterName) ||
+		secret.GetLabels()[ClusterNameKey] == clusterName
+}
+
 type ClusterClient interface {
-	GetClusterClient(context.Context) (resource.APIPatchingApplicator, bool, error)
+	// GetClusterClient returns the client of the cluster if the readiness
+	// status is ready, the status reason explains why the cluster is not ready
+	GetClusterClient(context.Context) (resource.APIPatchingApplicator, readiness.Status, error)
 	GetClusterName() string
 }
+
+func (r Cluster) getCache() *cache.Cache {
+	if r.Cache != nil {
+		return r.Cache
+	}
+	return cache.Default()
+}
+
+func capiProvider(c client.Client, cache *cache.Cache, opts readiness.Options, secret *corev1.Secret) (ClusterClient, bool) {
+	if !capi.IsCapiSecret(secret) {
+		return nil, false
+	}
+	return &capi.Capi{Client: c, Secret: secret, Cache: cache, Readiness: opts}, true
+}
+
+func kubeconfigProvider(c client.Client, cache *cache.Cache, opts readiness.Options, secret *corev1.Secret) (ClusterClient, bool) {
+	if !kubeconfig.IsKubeconfigSecret(secret) {
+		return nil, false
+	}
+	return &kubeconfig.Kubeconfig{Client: c, Secret: secret, Cache: cache, Readiness: opts}, true
+}
+
+func tokenProvider(c client.Client, cache *cache.Cache, opts readiness.Options, secret *corev1.Secret) (ClusterClient, bool) {
+	if !token.IsTokenSecret(secret) {
+		return nil, false
+	}
+	return &token.Token{Client: c, Secret: secret, Cache: cache, Readiness: opts}, true
+}
@@ -42,6 +42,35 @@ func TestGetClusterClient(t *testing.T) {
 			},
 			want: true,
 		},
+		"Kubeconfig": {
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "a",
+					Labels: map[string]string{ClusterNameKey: "a"},
+				},
+				Type: corev1.SecretType("nephio.org/kubeconfig"),
+			},
+			want: true,
+		},
+		"KubeconfigWithoutLabel": {
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "a",
+				},
+				Type: corev1.SecretType("nephio.org/kubeconfig"),
+			},
+			want: false,
+		},
+		"Token": {
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "a",
+					Labels: map[string]string{ClusterNameKey: "a"},
+				},
+				Type: corev1.SecretType("nephio.org/cluster-token"),
+			},
+			want: true,
+		},
 	}
 
 	for name, tc := range cases {
@@ -55,3 +84,49 @@ func TestGetClusterClient(t *testing.T) {
 		})
 	}
 }
+
+func TestIsClusterSecret(t *testing.T) {
+	cases := map[string]struct {
+		secret      *corev1.Secret
+		clusterName string
+		want        bool
+	}{
+		"Name": {
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "a-kubeconfig"},
+			},
+			clusterName: "a",
+			want:        true,
+		},
+		"Label": {
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "credentials",
+					Labels: map[string]string{ClusterNameKey: "a"},
+				},
+			},
+			clusterName: "a",
+			want:        true,
+		},
+		"Other": {
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "b-kubeconfig",
+					Labels: map[string]string{ClusterNameKey: "b"},
+				},
+			},
+			clusterName: "a",
+			want:        false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := IsClusterSecret(tc.secret, tc.clusterName)
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clustersecret
+
+import (
+	"context"
+	"time"
+
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/cache"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/readiness"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// ClusterNameKey is the label key identifying the cluster of a secret
+	// that is not managed by cluster api
+	ClusterNameKey = "nephio.org/cluster-name"
+
+	// APICheckInterval is the interval after which the api server of a
+	// cached cluster client is checked again
+	APICheckInterval = time.Minute
+)
+
+// IsClusterSecret returns true if the secret has the secret type and
+// identifies its cluster with the cluster-name label
+func IsClusterSecret(secret *corev1.Secret, secretType string) bool {
+	return string(secret.Type) == secretType && GetClusterName(secret) != ""
+}
+
+// GetClusterName returns the cluster name of the cluster-name label
+func GetClusterName(secret *corev1.Secret) string {
+	if secret == nil {
+		return ""
+	}
+	return secret.GetLabels()[ClusterNameKey]
+}
+
+// GetClusterClient returns the client of the cluster built from the rest
+// config the provider derives from the secret. The client is cached when a
+// cache is provided, if nil a new client is build on every call. Credentials
+// from which no client can be built are reported as not ready without an
+// error, as the secret can be corrected later on, which triggers a new
+// reconcile.
+func GetClusterClient(ctx context.Context, secret *corev1.Secret, c *cache.Cache, opts readiness.Options, configFn cache.ConfigFn) (resource.APIPatchingApplicator, readiness.Status, error) {
+	clClient, err := getClient(secret, c, configFn)
+	if err != nil {
+		log.FromContext(ctx).Info("cannot get cluster client", "secret", secret.GetName(), "error", err.Error())
+		return resource.APIPatchingApplicator{}, readiness.NotReady(readiness.ReasonInvalidCredentials, "%s", err.Error()), nil
+	}
+	// the api server of a cached client is checked again after an interval,
+	// so a cached client does not add a request to the cluster on every call
+	// while a cluster that becomes unreachable is still reported
+	checkAPI := opts.APITimeout > 0 && (c == nil || c.IsAPICheckDue(secret, APICheckInterval))
+	if !checkAPI {
+		opts.APITimeout = 0
+	}
+	status := readiness.Check(ctx, clClient.Client, opts)
+	if checkAPI && c != nil {
+		if status.Reason == readiness.ReasonUnreachable {
+			// the client is rebuilt and checked again on the next call
+			c.Invalidate(client.ObjectKeyFromObject(secret))
+		} else {
+			c.SetAPIChecked(secret)
+		}
+	}
+	return clClient, status, nil
+}
+
+func getClient(secret *corev1.Secret, c *cache.Cache, configFn cache.ConfigFn) (resource.APIPatchingApplicator, error) {
+	if c != nil {
+		return c.Get(secret, configFn)
+	}
+	config, err := configFn()
+	if err != nil {
+		return resource.APIPatchingApplicator{}, err
+	}
+	clClient, err := client.New(config, client.Options{})
+	if err != nil {
+		return resource.APIPatchingApplicator{}, err
+	}
+	return resource.NewAPIPatchingApplicator(clClient), nil
+}
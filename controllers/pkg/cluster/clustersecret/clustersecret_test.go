@@ -0,0 +1,71 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clustersecret
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/cache"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/readiness"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+func TestGetClusterClient(t *testing.T) {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "a", ResourceVersion: "1"}}
+	opts := readiness.Options{APITimeout: time.Second}
+
+	cases := map[string]struct {
+		configFn   cache.ConfigFn
+		wantReason readiness.Reason
+		wantLen    int
+	}{
+		"InvalidCredentials": {
+			configFn: func() (*rest.Config, error) {
+				return nil, fmt.Errorf("invalid kubeconfig")
+			},
+			wantReason: readiness.ReasonInvalidCredentials,
+		},
+		"Unreachable": {
+			// the cached client is dropped, so it is rebuilt and checked again
+			configFn: func() (*rest.Config, error) {
+				return &rest.Config{Host: "https://127.0.0.1:1"}, nil
+			},
+			wantReason: readiness.ReasonUnreachable,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := cache.New(0)
+			_, status, err := GetClusterClient(context.Background(), secret, c, opts, tc.configFn)
+			if err != nil {
+				t.Fatalf("GetClusterClient() unexpected error: %v", err)
+			}
+			if status.Reason != tc.wantReason {
+				t.Errorf("want reason %s, got %s", tc.wantReason, status)
+			}
+			if c.Len() != tc.wantLen {
+				t.Errorf("want %d cached clients, got %d", tc.wantLen, c.Len())
+			}
+		})
+	}
+}
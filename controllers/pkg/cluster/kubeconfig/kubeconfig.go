@@ -0,0 +1,67 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"context"
+
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/cache"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/clustersecret"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/readiness"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// SecretType is the type of a secret holding a generic kubeconfig
+	SecretType = "nephio.org/kubeconfig"
+	// kubeConfigKey is the data key holding the kubeconfig
+	kubeConfigKey = "kubeconfig"
+)
+
+// Kubeconfig is a cluster client for clusters that are not managed by
+// cluster api, e.g. kind or pre-existing clusters. The secret holds the
+// kubeconfig and identifies the cluster with the cluster-name label.
+type Kubeconfig struct {
+	client.Client
+	Secret *corev1.Secret
+	// Cache holds the cluster clients, if nil a new client is build on every call
+	Cache *cache.Cache
+	// Readiness defines the checks performed on the cluster, the api server
+	// reachability of a cached cluster client is checked every minute
+	Readiness readiness.Options
+}
+
+// IsKubeconfigSecret returns true if the secret is handled by this provider
+func IsKubeconfigSecret(secret *corev1.Secret) bool {
+	return clustersecret.IsClusterSecret(secret, SecretType)
+}
+
+func (r *Kubeconfig) GetClusterName() string {
+	return clustersecret.GetClusterName(r.Secret)
+}
+
+func (r *Kubeconfig) GetClusterClient(ctx context.Context) (resource.APIPatchingApplicator, readiness.Status, error) {
+	return clustersecret.GetClusterClient(ctx, r.Secret, r.Cache, r.Readiness, r.getRESTConfig)
+}
+
+func (r *Kubeconfig) getRESTConfig() (*rest.Config, error) {
+	return clientcmd.RESTConfigFromKubeConfig(r.Secret.Data[kubeConfigKey])
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/clustersecret"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/readiness"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: a
+  cluster:
+    server: https://10.0.0.1:6443
+contexts:
+- name: a
+  context:
+    cluster: a
+    user: a
+current-context: a
+users:
+- name: a
+  user:
+    token: abc
+`
+
+func getTestSecret(labels map[string]string, kubeconfig string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "a",
+			Labels: labels,
+		},
+		Type: corev1.SecretType(SecretType),
+		Data: map[string][]byte{kubeConfigKey: []byte(kubeconfig)},
+	}
+}
+
+func TestIsKubeconfigSecret(t *testing.T) {
+	cases := map[string]struct {
+		secret *corev1.Secret
+		want   bool
+	}{
+		"Labeled": {
+			secret: getTestSecret(map[string]string{clustersecret.ClusterNameKey: "a"}, testKubeconfig),
+			want:   true,
+		},
+		"NoLabel": {
+			secret: getTestSecret(nil, testKubeconfig),
+			want:   false,
+		},
+		"OtherType": {
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "a",
+					Labels: map[string]string{clustersecret.ClusterNameKey: "a"},
+				},
+				Type: corev1.SecretTypeOpaque,
+			},
+			want: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, IsKubeconfigSecret(tc.secret)); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetRESTConfig(t *testing.T) {
+	cases := map[string]struct {
+		kubeconfig string
+		wantHost   string
+		wantErr    bool
+	}{
+		"Valid": {
+			kubeconfig: testKubeconfig,
+			wantHost:   "https://10.0.0.1:6443",
+		},
+		"Invalid": {
+			kubeconfig: "invalid",
+			wantErr:    true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := Kubeconfig{
+				Secret: getTestSecret(map[string]string{clustersecret.ClusterNameKey: "a"}, tc.kubeconfig),
+			}
+			got, err := r.getRESTConfig()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("getRESTConfig() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.wantHost, got.Host); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff("a", r.GetClusterName()); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetClusterClientInvalidCredentials(t *testing.T) {
+	r := Kubeconfig{
+		Secret: getTestSecret(map[string]string{clustersecret.ClusterNameKey: "a"}, "invalid"),
+	}
+	_, status, err := r.GetClusterClient(context.Background())
+	if err != nil {
+		t.Fatalf("GetClusterClient() unexpected error: %v", err)
+	}
+	if status.Ready || status.Reason != readiness.ReasonInvalidCredentials {
+		t.Errorf("want status %s, got %s", readiness.ReasonInvalidCredentials, status)
+	}
+}
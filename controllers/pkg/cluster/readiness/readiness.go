@@ -0,0 +1,136 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package readiness
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Reason explains why a cluster is (not) ready
+type Reason string
+
+const (
+	ReasonReady Reason = "Ready"
+	// ReasonNotProvisioned is used when the cluster provider does not report
+	// the cluster as ready
+	ReasonNotProvisioned Reason = "NotProvisioned"
+	// ReasonInvalidCredentials is used when no client can be build from the
+	// cluster credentials
+	ReasonInvalidCredentials Reason = "InvalidCredentials"
+	// ReasonUnreachable is used when the api server does not answer in time
+	ReasonUnreachable Reason = "Unreachable"
+	// ReasonNodesNotReady is used when less nodes are ready than required
+	ReasonNodesNotReady Reason = "NodesNotReady"
+	// ReasonNamespaceMissing is used when a required namespace does not exist
+	ReasonNamespaceMissing Reason = "NamespaceMissing"
+	// ReasonCRDMissing is used when a required crd is not installed
+	ReasonCRDMissing Reason = "CRDMissing"
+)
+
+// Options define the checks that are performed on top of the readiness
+// reported by the cluster provider; the zero value disables all checks
+type Options struct {
+	// APITimeout enables the api server reachability check with the given timeout
+	APITimeout time.Duration
+	// MinReadyNodes is the minimum amount of nodes with a Ready condition
+	MinReadyNodes int
+	// Namespaces that must exist in the cluster
+	Namespaces []string
+	// CRDs that must be installed in the cluster, e.g. packagevariants.config.porch.kpt.dev
+	CRDs []string
+}
+
+// Status is the result of a readiness check
+type Status struct {
+	Ready   bool
+	Reason  Reason
+	Message string
+}
+
+// Ready returns a ready status
+func Ready() Status {
+	return Status{Ready: true, Reason: ReasonReady}
+}
+
+// NotReady returns a status which is not ready with the reason and message
+func NotReady(reason Reason, format string, a ...any) Status {
+	return Status{Ready: false, Reason: reason, Message: fmt.Sprintf(format, a...)}
+}
+
+func (r Status) String() string {
+	if r.Message == "" {
+		return string(r.Reason)
+	}
+	return fmt.Sprintf("%s: %s", r.Reason, r.Message)
+}
+
+var crdGVK = schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}
+
+// Check performs the checks defined in the options using the client of the
+// remote cluster and returns the first check that fails
+func Check(ctx context.Context, c client.Client, opts Options) Status {
+	if opts.APITimeout > 0 {
+		tctx, cancel := context.WithTimeout(ctx, opts.APITimeout)
+		defer cancel()
+		if err := c.List(tctx, &corev1.NamespaceList{}, client.Limit(1)); err != nil {
+			return NotReady(ReasonUnreachable, "api server not reachable within %s: %s", opts.APITimeout, err.Error())
+		}
+	}
+	if opts.MinReadyNodes > 0 {
+		nodes := &corev1.NodeList{}
+		if err := c.List(ctx, nodes); err != nil {
+			return NotReady(ReasonNodesNotReady, "cannot list nodes: %s", err.Error())
+		}
+		ready := 0
+		for _, n := range nodes.Items {
+			if isNodeReady(n) {
+				ready++
+			}
+		}
+		if ready < opts.MinReadyNodes {
+			return NotReady(ReasonNodesNotReady, "%d of %d required nodes ready", ready, opts.MinReadyNodes)
+		}
+	}
+	for _, name := range opts.Namespaces {
+		if err := c.Get(ctx, types.NamespacedName{Name: name}, &corev1.Namespace{}); err != nil {
+			return NotReady(ReasonNamespaceMissing, "namespace %s: %s", name, err.Error())
+		}
+	}
+	for _, name := range opts.CRDs {
+		if err := c.Get(ctx, types.NamespacedName{Name: name}, resource.GetUnstructuredFromGVK(&crdGVK)); err != nil {
+			return NotReady(ReasonCRDMissing, "crd %s: %s", name, err.Error())
+		}
+	}
+	return Ready()
+}
+
+func isNodeReady(n corev1.Node) bool {
+	for _, c := range n.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package readiness
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func getNode(name string, ready corev1.ConditionStatus) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+		},
+	}
+}
+
+func getCRD(name string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(crdGVK)
+	u.SetName(name)
+	return u
+}
+
+func TestCheck(t *testing.T) {
+	cases := map[string]struct {
+		objects []client.Object
+		opts    Options
+		want    Reason
+	}{
+		"NoChecks": {
+			opts: Options{},
+			want: ReasonReady,
+		},
+		"Reachable": {
+			opts: Options{APITimeout: time.Second},
+			want: ReasonReady,
+		},
+		"NodesReady": {
+			objects: []client.Object{getNode("a", corev1.ConditionTrue), getNode("b", corev1.ConditionTrue)},
+			opts:    Options{MinReadyNodes: 2},
+			want:    ReasonReady,
+		},
+		"NodesNotReady": {
+			objects: []client.Object{getNode("a", corev1.ConditionTrue), getNode("b", corev1.ConditionFalse)},
+			opts:    Options{MinReadyNodes: 2},
+			want:    ReasonNodesNotReady,
+		},
+		"NamespacePresent": {
+			objects: []client.Object{&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "config-management-system"}}},
+			opts:    Options{Namespaces: []string{"config-management-system"}},
+			want:    ReasonReady,
+		},
+		"NamespaceMissing": {
+			opts: Options{Namespaces: []string{"config-management-system"}},
+			want: ReasonNamespaceMissing,
+		},
+		"CRDPresent": {
+			objects: []client.Object{getCRD("rootsyncs.configsync.gke.io")},
+			opts:    Options{CRDs: []string{"rootsyncs.configsync.gke.io"}},
+			want:    ReasonReady,
+		},
+		"CRDMissing": {
+			opts: Options{CRDs: []string{"rootsyncs.configsync.gke.io"}},
+			want: ReasonCRDMissing,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mapper := meta.NewDefaultRESTMapper(nil)
+			mapper.Add(crdGVK, meta.RESTScopeRoot)
+			mapper.Add(corev1.SchemeGroupVersion.WithKind("Namespace"), meta.RESTScopeRoot)
+			mapper.Add(corev1.SchemeGroupVersion.WithKind("Node"), meta.RESTScopeRoot)
+			c := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithRESTMapper(mapper).
+				WithObjects(tc.objects...).
+				Build()
+
+			got := Check(context.Background(), c, tc.opts)
+
+			if diff := cmp.Diff(tc.want, got.Reason); diff != "" {
+				t.Errorf("-want, +got:\n%s\nstatus: %s", diff, got)
+			}
+			if diff := cmp.Diff(tc.want == ReasonReady, got.Ready); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
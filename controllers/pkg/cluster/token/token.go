@@ -0,0 +1,82 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/cache"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/clustersecret"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/readiness"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// SecretType is the type of a secret holding a service account token and
+	// the ca of a cluster
+	SecretType = "nephio.org/cluster-token"
+
+	serverKey = "server"
+	tokenKey  = "token"
+	caKey     = "ca.crt"
+)
+
+// Token is a cluster client for clusters that are accessed with a service
+// account token, e.g. managed cloud clusters. The secret holds the api server
+// address, the token and the ca and identifies the cluster with the
+// cluster-name label.
+type Token struct {
+	client.Client
+	Secret *corev1.Secret
+	// Cache holds the cluster clients, if nil a new client is build on every call
+	Cache *cache.Cache
+	// Readiness defines the checks performed on the cluster, the api server
+	// reachability of a cached cluster client is checked every minute
+	Readiness readiness.Options
+}
+
+// IsTokenSecret returns true if the secret is handled by this provider
+func IsTokenSecret(secret *corev1.Secret) bool {
+	return clustersecret.IsClusterSecret(secret, SecretType)
+}
+
+func (r *Token) GetClusterName() string {
+	return clustersecret.GetClusterName(r.Secret)
+}
+
+func (r *Token) GetClusterClient(ctx context.Context) (resource.APIPatchingApplicator, readiness.Status, error) {
+	return clustersecret.GetClusterClient(ctx, r.Secret, r.Cache, r.Readiness, r.getRESTConfig)
+}
+
+func (r *Token) getRESTConfig() (*rest.Config, error) {
+	for _, key := range []string{serverKey, tokenKey, caKey} {
+		if len(r.Secret.Data[key]) == 0 {
+			return nil, fmt.Errorf("secret %s/%s has no %s", r.Secret.GetNamespace(), r.Secret.GetName(), key)
+		}
+	}
+	return &rest.Config{
+		Host:        string(r.Secret.Data[serverKey]),
+		BearerToken: string(r.Secret.Data[tokenKey]),
+		TLSClientConfig: rest.TLSClientConfig{
+			CAData: r.Secret.Data[caKey],
+		},
+	}, nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/clustersecret"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetRESTConfig(t *testing.T) {
+	cases := map[string]struct {
+		data     map[string][]byte
+		wantHost string
+		wantErr  bool
+	}{
+		"Complete": {
+			data: map[string][]byte{
+				serverKey: []byte("https://10.0.0.1:6443"),
+				tokenKey:  []byte("abc"),
+				caKey:     []byte("ca"),
+			},
+			wantHost: "https://10.0.0.1:6443",
+		},
+		"MissingToken": {
+			data: map[string][]byte{
+				serverKey: []byte("https://10.0.0.1:6443"),
+				caKey:     []byte("ca"),
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := Token{
+				Secret: &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "a",
+						Labels: map[string]string{clustersecret.ClusterNameKey: "a"},
+					},
+					Type: corev1.SecretType(SecretType),
+					Data: tc.data,
+				},
+			}
+			got, err := r.getRESTConfig()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("getRESTConfig() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.wantHost, got.Host); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff("a", r.GetClusterName()); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
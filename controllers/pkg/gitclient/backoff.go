@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitclient
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type backoffRange struct {
+	base time.Duration
+	max  time.Duration
+}
+
+// backoffs are the retry delays per error class. Errors that need a change of
+// the credentials or the git server, like an invalid password, are retried
+// slowly so they do not flood the git server.
+var backoffs = map[gitprovider.ErrorClass]backoffRange{
+	gitprovider.ErrorClassUnauthorized: {base: time.Minute, max: 30 * time.Minute},
+	gitprovider.ErrorClassNotFound:     {base: 30 * time.Second, max: 10 * time.Minute},
+	gitprovider.ErrorClassConflict:     {base: 10 * time.Second, max: 5 * time.Minute},
+	gitprovider.ErrorClassRateLimited:  {base: time.Minute, max: 15 * time.Minute},
+	gitprovider.ErrorClassUnavailable:  {base: 5 * time.Second, max: 5 * time.Minute},
+	gitprovider.ErrorClassUnknown:      {base: 5 * time.Second, max: 5 * time.Minute},
+}
+
+// Backoff tracks the consecutive failures of the resources reconciled against
+// the git server and returns an exponential retry delay per error class
+type Backoff struct {
+	m        sync.Mutex
+	failures map[string]failure
+}
+
+type failure struct {
+	class gitprovider.ErrorClass
+	count int
+}
+
+func NewBackoff() *Backoff {
+	return &Backoff{failures: map[string]failure{}}
+}
+
+// Next records the failure of the resource with the key and returns the delay
+// before it is retried. The delay doubles with every consecutive failure of
+// the same class and starts over when the class changes.
+func (r *Backoff) Next(key string, err error) time.Duration {
+	class := gitprovider.ClassOf(err)
+	if class == "" {
+		class = gitprovider.ErrorClassUnknown
+	}
+	rng := backoffs[class]
+
+	r.m.Lock()
+	defer r.m.Unlock()
+	f := r.failures[key]
+	if f.class != class {
+		f = failure{class: class}
+	}
+	d := rng.base
+	for i := 0; i < f.count && d < rng.max; i++ {
+		d *= 2
+	}
+	if d > rng.max {
+		d = rng.max
+	}
+	f.count++
+	r.failures[key] = f
+	return d
+}
+
+// Reset forgets the failures of the resource with the key, it is called once
+// the resource is reconciled or deleted
+func (r *Backoff) Reset(key string) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	delete(r.failures, key)
+}
+
+// ConditionReason returns the class of the git server error as condition
+// reason, errors that do not come from the git server are reported as Failed
+func ConditionReason(err error) infrav1alpha1.ConditionReason {
+	var e *gitprovider.Error
+	if errors.As(err, &e) {
+		return infrav1alpha1.ConditionReason(e.Class)
+	}
+	return infrav1alpha1.ConditionReasonFailed
+}
+
+// Failed returns the ready condition of a resource that failed on the error
+// with the reason of the error. The message is kept as provided so that errors
+// carrying request details do not change the condition on every attempt.
+func Failed(msg string, err error) infrav1alpha1.Condition {
+	return infrav1alpha1.Condition{Condition: metav1.Condition{
+		Type:               string(infrav1alpha1.ConditionTypeReady),
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             string(ConditionReason(err)),
+		Message:            msg,
+	}}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitclient
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
+)
+
+func TestBackoff(t *testing.T) {
+	unauthorized := gitprovider.NewError(http.StatusUnauthorized, fmt.Errorf("401 Unauthorized"))
+	unavailable := gitprovider.NewError(http.StatusServiceUnavailable, fmt.Errorf("503 Service Unavailable"))
+
+	cases := map[string]struct {
+		errs []error
+		want []time.Duration
+	}{
+		"Unauthorized": {
+			errs: []error{unauthorized, unauthorized, unauthorized, unauthorized, unauthorized, unauthorized, unauthorized},
+			want: []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 16 * time.Minute, 30 * time.Minute, 30 * time.Minute},
+		},
+		"Unavailable": {
+			errs: []error{unavailable, unavailable, unavailable},
+			want: []time.Duration{5 * time.Second, 10 * time.Second, 20 * time.Second},
+		},
+		"ClassChanged": {
+			errs: []error{unavailable, unavailable, unauthorized, unavailable},
+			want: []time.Duration{5 * time.Second, 10 * time.Second, time.Minute, 5 * time.Second},
+		},
+		"NotGitServerError": {
+			errs: []error{fmt.Errorf("cannot get secret"), fmt.Errorf("cannot get secret")},
+			want: []time.Duration{5 * time.Second, 10 * time.Second},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			b := NewBackoff()
+			got := []time.Duration{}
+			for _, err := range tc.errs {
+				got = append(got, b.Next("default/edge01", err))
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Next() -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBackoffReset(t *testing.T) {
+	b := NewBackoff()
+	err := gitprovider.NewError(http.StatusTooManyRequests, fmt.Errorf("429 Too Many Requests"))
+	b.Next("default/edge01", err)
+	b.Next("default/edge02", err)
+	b.Reset("default/edge01")
+	if diff := cmp.Diff(time.Minute, b.Next("default/edge01", err)); diff != "" {
+		t.Errorf("Next() after Reset() -want, +got:\n%s", diff)
+	}
+	// the failures of other resources are kept
+	if diff := cmp.Diff(2*time.Minute, b.Next("default/edge02", err)); diff != "" {
+		t.Errorf("Next() -want, +got:\n%s", diff)
+	}
+}
+
+func TestFailed(t *testing.T) {
+	cases := map[string]struct {
+		err  error
+		want infrav1alpha1.ConditionReason
+	}{
+		"GitServerError": {
+			err:  fmt.Errorf("cannot create repo: %w", gitprovider.NewError(http.StatusConflict, fmt.Errorf("409 Conflict"))),
+			want: infrav1alpha1.ConditionReason(gitprovider.ErrorClassConflict),
+		},
+		"OtherError": {
+			err:  fmt.Errorf("invalid rotation period"),
+			want: infrav1alpha1.ConditionReasonFailed,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Failed("cannot create repo", tc.err)
+			if diff := cmp.Diff(string(tc.want), got.Reason); diff != "" {
+				t.Errorf("Failed() reason -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff("cannot create repo", got.Message); diff != "" {
+				t.Errorf("Failed() message -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
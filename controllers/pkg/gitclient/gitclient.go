@@ -0,0 +1,526 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/nephio-project/nephio/controllers/pkg/giteaclient"
+	"github.com/nephio-project/nephio/controllers/pkg/gitlabclient"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// NewFn builds a git provider for the url with the credentials
+type NewFn func(url string, creds gitprovider.Credentials) (gitprovider.GitProvider, error)
+
+var providers = map[gitprovider.Kind]NewFn{
+	gitprovider.KindGitea: func(url string, creds gitprovider.Credentials) (gitprovider.GitProvider, error) {
+		return giteaclient.New(url, creds)
+	},
+	gitprovider.KindGitLab: gitlabclient.New,
+}
+
+// errNotInitialized is retried like an unreachable git server
+var errNotInitialized = &gitprovider.Error{Class: gitprovider.ErrorClassUnavailable, Err: fmt.Errorf("git client not initialized")}
+
+// backoffKey is the key of the connection failures in the backoff
+const backoffKey = "connection"
+
+// pollInterval is the interval at which the configuration and the credentials
+// secret are checked for changes
+const pollInterval = 5 * time.Second
+
+// State is the connection state of the git client
+type State string
+
+const (
+	StateConnecting   State = "Connecting"
+	StateConnected    State = "Connected"
+	StateDisconnected State = "Disconnected"
+)
+
+// Status is the connection status of the git client
+type Status struct {
+	State   State
+	Message string
+	// Since is the time of the last state change
+	Since time.Time
+}
+
+func (r Status) String() string {
+	if r.Message == "" {
+		return fmt.Sprintf("git client %s", r.State)
+	}
+	return fmt.Sprintf("git client %s: %s", r.State, r.Message)
+}
+
+// statusProvider is implemented by git providers that report their
+// connection status
+type statusProvider interface {
+	Status() Status
+	RetryInterval() time.Duration
+}
+
+// GetStatus returns the connection status of the git provider
+func GetStatus(p gitprovider.GitProvider) Status {
+	if c, ok := p.(statusProvider); ok {
+		return c.Status()
+	}
+	if p.IsInitialized() {
+		return Status{State: StateConnected}
+	}
+	return Status{State: StateDisconnected}
+}
+
+// GetRetryInterval returns the interval after which callers should retry when
+// the git provider is not connected
+func GetRetryInterval(p gitprovider.GitProvider) time.Duration {
+	if c, ok := p.(statusProvider); ok {
+		return c.RetryInterval()
+	}
+	return pollInterval
+}
+
+var lock = &sync.Mutex{}
+
+var singleInstance *gc
+
+// GetClient returns the process wide git client, the provider is selected
+// by the GIT_PROVIDER environment variable
+func GetClient(ctx context.Context, client resource.APIPatchingApplicator) (gitprovider.GitProvider, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("failed creating git client, value of ctx cannot be nil")
+	}
+
+	if client.Client == nil {
+		return nil, fmt.Errorf("failed creating git client, value of client.Client cannot be nil")
+	}
+	// check if an instance is created using check-lock-check pattern implementation
+	if singleInstance == nil {
+		// Create a lock
+		lock.Lock()
+		defer lock.Unlock()
+		// Check instance is still null as another thread of execution may have initialized it before the lock was acquired.
+		if singleInstance == nil {
+			singleInstance = newClient(client)
+			log.FromContext(ctx).Info("Git Client Instance created now.")
+			go singleInstance.Start(ctx)
+		} else {
+			log.FromContext(ctx).Info("Git Client Instance already created.")
+		}
+	} else {
+		log.FromContext(ctx).Info("Git Client Instance already created.")
+	}
+	return singleInstance, nil
+}
+
+func newClient(client resource.APIPatchingApplicator) *gc {
+	return &gc{
+		client:    client,
+		providers: providers,
+		interval:  pollInterval,
+		status:    Status{State: StateConnecting, Since: time.Now()},
+		backoff:   NewBackoff(),
+		now:       time.Now,
+		l:         logr.Discard(),
+	}
+}
+
+// gc delegates to the git provider once it is connected and rebuilds the
+// provider when the configuration or the credentials change
+type gc struct {
+	client    resource.APIPatchingApplicator
+	providers map[gitprovider.Kind]NewFn
+	interval  time.Duration
+
+	m        sync.RWMutex
+	provider gitprovider.GitProvider
+	// fingerprint identifies the configuration and credentials the provider
+	// was built with
+	fingerprint string
+	status      Status
+	// retryAt is the time after which a connection that failed with the same
+	// configuration and credentials is retried
+	retryAt time.Time
+	backoff *Backoff
+	// now can be overwritten for testing
+	now func() time.Time
+	l   logr.Logger
+}
+
+// Start keeps the git provider in sync with the configuration and the
+// credentials secret until the context is cancelled.
+// The context is the one returned by ctrl.SetupSignalHandler().
+// The Idea for continuously retrying is for enabling the user to create or
+// rotate the secret at any time after the controllers are started.
+func (r *gc) Start(ctx context.Context) {
+	r.l = log.FromContext(ctx)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			r.l.Info("controller manager context cancelled: Exit")
+			return
+		case <-ticker.C:
+			r.reconnect(ctx)
+		}
+	}
+}
+
+// reconnect rebuilds the git provider when the configuration or the
+// credentials changed or when the provider is not connected. A connection that
+// failed is retried with the backoff of its error class as long as the
+// configuration and the credentials do not change, so invalid credentials do
+// not lock the account on the git server.
+func (r *gc) reconnect(ctx context.Context) {
+	cfg := gitprovider.GetConfig()
+	newFn, ok := r.providers[cfg.Kind]
+	if !ok {
+		r.disconnect(fmt.Errorf("unknown git provider %s", cfg.Kind), "")
+		return
+	}
+
+	// get secret that was created when installing the git server
+	secret := &corev1.Secret{}
+	if err := r.client.Get(ctx, cfg.Secret, secret); err != nil {
+		if r.IsInitialized() {
+			// keep the connection with the credentials we already have
+			r.l.Error(err, "cannot get git secret, keep using the current connection")
+			return
+		}
+		r.disconnect(errors.Wrap(err, "cannot get secret, please follow README and create the git secret"), "")
+		return
+	}
+	url, err := cfg.GetURL(secret)
+	if err != nil {
+		r.disconnect(err, "")
+		return
+	}
+	creds := gitprovider.GetCredentials(secret)
+	fingerprint := getFingerprint(cfg.Kind, url, creds)
+
+	r.m.RLock()
+	unchanged := r.fingerprint == fingerprint
+	connected := r.status.State == StateConnected
+	retryAt := r.retryAt
+	r.m.RUnlock()
+	if unchanged && (connected || r.now().Before(retryAt)) {
+		return
+	}
+	if !unchanged {
+		r.backoff.Reset(backoffKey)
+	}
+
+	provider, err := newFn(url, creds)
+	if err != nil {
+		r.disconnect(errors.Wrap(err, "cannot authenticate to git server"), fingerprint)
+		return
+	}
+	// not all providers contact the server when they are built, so we
+	// validate the credentials before using them
+	if _, err := provider.GetMyUserInfo(); err != nil {
+		r.disconnect(errors.Wrap(err, "cannot authenticate to git server"), fingerprint)
+		return
+	}
+
+	r.m.Lock()
+	r.provider = provider
+	r.fingerprint = fingerprint
+	r.retryAt = time.Time{}
+	r.setStatus(StateConnected, "")
+	r.m.Unlock()
+	r.backoff.Reset(backoffKey)
+	r.l.Info("git client connected", "provider", cfg.Kind, "url", url)
+}
+
+// disconnect drops the git provider so callers retry until the client is
+// connected again. The fingerprint is set when the git server refused the
+// connection, the connection is then retried after the backoff.
+func (r *gc) disconnect(err error, fingerprint string) {
+	var retryAt time.Time
+	if fingerprint != "" {
+		retryAt = r.now().Add(r.backoff.Next(backoffKey, err))
+	}
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.provider = nil
+	r.fingerprint = fingerprint
+	r.retryAt = retryAt
+	r.setStatus(StateDisconnected, err.Error())
+	r.l.Error(err, "cannot connect to git server")
+}
+
+// setStatus expects the caller to hold the lock
+func (r *gc) setStatus(state State, msg string) {
+	if r.status.State != state {
+		r.status.Since = time.Now()
+	}
+	r.status.State = state
+	r.status.Message = msg
+}
+
+func (r *gc) Status() Status {
+	r.m.RLock()
+	defer r.m.RUnlock()
+	return r.status
+}
+
+func (r *gc) RetryInterval() time.Duration {
+	return r.interval
+}
+
+func getFingerprint(kind gitprovider.Kind, url string, creds gitprovider.Credentials) string {
+	h := sha256.New()
+	for _, s := range []string{string(kind), url, creds.UserName, creds.Password, creds.Token} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (r *gc) get() (gitprovider.GitProvider, error) {
+	r.m.RLock()
+	defer r.m.RUnlock()
+	if r.provider == nil {
+		return nil, errNotInitialized
+	}
+	return r.provider, nil
+}
+
+func (r *gc) IsInitialized() bool {
+	p, err := r.get()
+	return err == nil && p.IsInitialized()
+}
+
+func (r *gc) GetMyUserInfo() (*gitprovider.User, error) {
+	p, err := r.get()
+	if err != nil {
+		return nil, err
+	}
+	return p.GetMyUserInfo()
+}
+
+func (r *gc) GetRepo(owner string, name string) (*gitprovider.Repository, error) {
+	p, err := r.get()
+	if err != nil {
+		return nil, err
+	}
+	return p.GetRepo(owner, name)
+}
+
+func (r *gc) CreateRepo(opts gitprovider.CreateRepoOptions) (*gitprovider.Repository, error) {
+	p, err := r.get()
+	if err != nil {
+		return nil, err
+	}
+	return p.CreateRepo(opts)
+}
+
+func (r *gc) MigrateRepo(opts gitprovider.MigrateRepoOptions) (*gitprovider.Repository, error) {
+	p, err := r.get()
+	if err != nil {
+		return nil, err
+	}
+	return p.MigrateRepo(opts)
+}
+
+func (r *gc) EditRepo(owner string, name string, opts gitprovider.EditRepoOptions) (*gitprovider.Repository, error) {
+	p, err := r.get()
+	if err != nil {
+		return nil, err
+	}
+	return p.EditRepo(owner, name, opts)
+}
+
+func (r *gc) DeleteRepo(owner string, name string) error {
+	p, err := r.get()
+	if err != nil {
+		return err
+	}
+	return p.DeleteRepo(owner, name)
+}
+
+func (r *gc) GetOrg(name string) (*gitprovider.Organization, error) {
+	p, err := r.get()
+	if err != nil {
+		return nil, err
+	}
+	return p.GetOrg(name)
+}
+
+func (r *gc) CreateOrg(opts gitprovider.CreateOrgOptions) (*gitprovider.Organization, error) {
+	p, err := r.get()
+	if err != nil {
+		return nil, err
+	}
+	return p.CreateOrg(opts)
+}
+
+func (r *gc) AddTeamRepo(opts gitprovider.AddTeamRepoOptions) error {
+	p, err := r.get()
+	if err != nil {
+		return err
+	}
+	return p.AddTeamRepo(opts)
+}
+
+func (r *gc) ListBranchProtections(owner string, repo string) ([]*gitprovider.BranchProtection, error) {
+	p, err := r.get()
+	if err != nil {
+		return nil, err
+	}
+	return p.ListBranchProtections(owner, repo)
+}
+
+func (r *gc) CreateBranchProtection(owner string, repo string, bp gitprovider.BranchProtection) error {
+	p, err := r.get()
+	if err != nil {
+		return err
+	}
+	return p.CreateBranchProtection(owner, repo, bp)
+}
+
+func (r *gc) EditBranchProtection(owner string, repo string, bp gitprovider.BranchProtection) error {
+	p, err := r.get()
+	if err != nil {
+		return err
+	}
+	return p.EditBranchProtection(owner, repo, bp)
+}
+
+func (r *gc) DeleteBranchProtection(owner string, repo string, branch string) error {
+	p, err := r.get()
+	if err != nil {
+		return err
+	}
+	return p.DeleteBranchProtection(owner, repo, branch)
+}
+
+func (r *gc) ListWebhooks(owner string, repo string) ([]*gitprovider.Webhook, error) {
+	p, err := r.get()
+	if err != nil {
+		return nil, err
+	}
+	return p.ListWebhooks(owner, repo)
+}
+
+func (r *gc) CreateWebhook(owner string, repo string, hook gitprovider.Webhook) error {
+	p, err := r.get()
+	if err != nil {
+		return err
+	}
+	return p.CreateWebhook(owner, repo, hook)
+}
+
+func (r *gc) EditWebhook(owner string, repo string, hook gitprovider.Webhook) error {
+	p, err := r.get()
+	if err != nil {
+		return err
+	}
+	return p.EditWebhook(owner, repo, hook)
+}
+
+func (r *gc) DeleteWebhook(owner string, repo string, id int64) error {
+	p, err := r.get()
+	if err != nil {
+		return err
+	}
+	return p.DeleteWebhook(owner, repo, id)
+}
+
+func (r *gc) ListCollaborators(owner string, repo string) ([]*gitprovider.Collaborator, error) {
+	p, err := r.get()
+	if err != nil {
+		return nil, err
+	}
+	return p.ListCollaborators(owner, repo)
+}
+
+func (r *gc) SetCollaborator(owner string, repo string, collaborator gitprovider.Collaborator) error {
+	p, err := r.get()
+	if err != nil {
+		return err
+	}
+	return p.SetCollaborator(owner, repo, collaborator)
+}
+
+func (r *gc) DeleteCollaborator(owner string, repo string, userName string) error {
+	p, err := r.get()
+	if err != nil {
+		return err
+	}
+	return p.DeleteCollaborator(owner, repo, userName)
+}
+
+func (r *gc) ListDeployKeys(owner string, repo string) ([]*gitprovider.DeployKey, error) {
+	p, err := r.get()
+	if err != nil {
+		return nil, err
+	}
+	return p.ListDeployKeys(owner, repo)
+}
+
+func (r *gc) CreateDeployKey(owner string, repo string, key gitprovider.DeployKey) error {
+	p, err := r.get()
+	if err != nil {
+		return err
+	}
+	return p.CreateDeployKey(owner, repo, key)
+}
+
+func (r *gc) DeleteDeployKey(owner string, repo string, id int64) error {
+	p, err := r.get()
+	if err != nil {
+		return err
+	}
+	return p.DeleteDeployKey(owner, repo, id)
+}
+
+func (r *gc) ListAccessTokens() ([]*gitprovider.AccessToken, error) {
+	p, err := r.get()
+	if err != nil {
+		return nil, err
+	}
+	return p.ListAccessTokens()
+}
+
+func (r *gc) CreateAccessToken(opts gitprovider.CreateAccessTokenOptions) (*gitprovider.AccessToken, error) {
+	p, err := r.get()
+	if err != nil {
+		return nil, err
+	}
+	return p.CreateAccessToken(opts)
+}
+
+func (r *gc) DeleteAccessToken(name string) error {
+	p, err := r.get()
+	if err != nil {
+		return err
+	}
+	return p.DeleteAccessToken(name)
+}
@@ -0,0 +1,157 @@
+package gitclient
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"reflect"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+	"time"
+)
+
+func TestClient(t *testing.T) {
+	ctx := ctrl.SetupSignalHandler()
+
+	type args struct {
+		ctx    context.Context
+		client resource.APIPatchingApplicator
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    gitprovider.GitProvider
+		wantErr bool
+	}{
+
+		{
+			name:    "ctx nil check",
+			args:    args{nil, resource.NewAPIPatchingApplicator(nil)},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "client nil check",
+			args:    args{ctx, resource.NewAPIPatchingApplicator(nil)},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetClient(tt.args.ctx, tt.args.client)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetClient() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetClient() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	t.Setenv("GIT_URL", "http://gitea:3000")
+	t.Setenv("GIT_NAMESPACE", "default")
+	t.Setenv("GIT_SECRET_NAME", "git-user-secret")
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "git-user-secret"},
+		Data: map[string][]byte{
+			"username": []byte("nephio"),
+			"password": []byte("secret"),
+		},
+	}
+	c := fake.NewClientBuilder().WithObjects(secret).Build()
+
+	// builds records the url and the password every provider was built with
+	builds := []string{}
+	r := newClient(resource.NewAPIPatchingApplicator(c))
+	r.providers = map[gitprovider.Kind]NewFn{
+		gitprovider.KindGitea: func(url string, creds gitprovider.Credentials) (gitprovider.GitProvider, error) {
+			builds = append(builds, url+" "+creds.Password)
+			p := gitprovider.NewMockGitProvider(t)
+			if creds.Password == "wrong" {
+				p.EXPECT().GetMyUserInfo().Return(nil, fmt.Errorf("unauthorized")).Maybe()
+			} else {
+				p.EXPECT().GetMyUserInfo().Return(&gitprovider.User{UserName: creds.UserName}, nil).Maybe()
+			}
+			p.EXPECT().IsInitialized().Return(true).Maybe()
+			return p, nil
+		},
+	}
+	now := time.Now()
+	r.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if diff := cmp.Diff(StateConnecting, GetStatus(r).State); diff != "" {
+		t.Errorf("GetStatus() -want, +got:\n%s", diff)
+	}
+
+	// the first reconnect builds the provider
+	r.reconnect(ctx)
+	if diff := cmp.Diff(StateConnected, GetStatus(r).State); diff != "" {
+		t.Errorf("GetStatus() -want, +got:\n%s", diff)
+	}
+	// an unchanged secret keeps the provider
+	r.reconnect(ctx)
+	if diff := cmp.Diff([]string{"http://gitea:3000 secret"}, builds); diff != "" {
+		t.Errorf("builds -want, +got:\n%s", diff)
+	}
+
+	// wrong credentials disconnect the client
+	secret.Data["password"] = []byte("wrong")
+	if err := c.Update(ctx, secret); err != nil {
+		t.Fatalf("Update() unexpected error: %v", err)
+	}
+	r.reconnect(ctx)
+	if diff := cmp.Diff(StateDisconnected, GetStatus(r).State); diff != "" {
+		t.Errorf("GetStatus() -want, +got:\n%s", diff)
+	}
+	if r.IsInitialized() {
+		t.Errorf("IsInitialized() want false for a disconnected client")
+	}
+	// the wrong credentials are not tried again before the backoff expired
+	r.reconnect(ctx)
+	now = now.Add(time.Hour)
+	r.reconnect(ctx)
+	if diff := cmp.Diff(StateDisconnected, GetStatus(r).State); diff != "" {
+		t.Errorf("GetStatus() -want, +got:\n%s", diff)
+	}
+
+	// the url in the secret overrides the configured url
+	secret.Data["password"] = []byte("rotated")
+	secret.Data["url"] = []byte("http://gitea.other:3000")
+	if err := c.Update(ctx, secret); err != nil {
+		t.Fatalf("Update() unexpected error: %v", err)
+	}
+	r.reconnect(ctx)
+	if diff := cmp.Diff(StateConnected, GetStatus(r).State); diff != "" {
+		t.Errorf("GetStatus() -want, +got:\n%s", diff)
+	}
+
+	// a deleted secret keeps the current connection
+	if err := c.Delete(ctx, secret); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	r.reconnect(ctx)
+	if !r.IsInitialized() {
+		t.Errorf("IsInitialized() want true when the secret is deleted")
+	}
+
+	want := []string{
+		"http://gitea:3000 secret",
+		"http://gitea:3000 wrong",
+		"http://gitea:3000 wrong",
+		"http://gitea.other:3000 rotated",
+	}
+	if diff := cmp.Diff(want, builds); diff != "" {
+		t.Errorf("builds -want, +got:\n%s", diff)
+	}
+}
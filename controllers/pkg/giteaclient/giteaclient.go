@@ -17,169 +17,465 @@ limitations under the License.
 package giteaclient
 
 import (
-	"context"
-	"fmt"
-	"os"
-	"sync"
-	"time"
-
 	"code.gitea.io/sdk/gitea"
-	"github.com/go-logr/logr"
-	"github.com/nephio-project/nephio/controllers/pkg/resource"
-	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/log"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
 )
 
-type GiteaClient interface {
-	Start(ctx context.Context)
-	IsInitialized() bool
-	Get() *gitea.Client
-	GetMyUserInfo() (*gitea.User, *gitea.Response, error)
-	DeleteRepo(owner string, repo string) (*gitea.Response, error)
-	GetRepo(userName string, repoCRName string) (*gitea.Repository, *gitea.Response, error)
-	CreateRepo(createRepoOption gitea.CreateRepoOption) (*gitea.Repository, *gitea.Response, error)
-	EditRepo(userName string, repoCRName string, editRepoOption gitea.EditRepoOption) (*gitea.Repository, *gitea.Response, error)
-	DeleteAccessToken(value interface{}) (*gitea.Response, error)
-	ListAccessTokens(opts gitea.ListAccessTokensOptions) ([]*gitea.AccessToken, *gitea.Response, error)
-	CreateAccessToken(opt gitea.CreateAccessTokenOption) (*gitea.AccessToken, *gitea.Response, error)
-}
-
-var lock = &sync.Mutex{}
-
-var singleInstance *gc
-
-func GetClient(ctx context.Context, client resource.APIPatchingApplicator) (GiteaClient, error) {
-	if ctx == nil {
-		return nil, fmt.Errorf("failed creating gitea client, value of ctx cannot be nil")
-	}
-
-	if client.Client == nil {
-		return nil, fmt.Errorf("failed creating gitea client, value of client.Client cannot be nil")
-	}
-	// check if an instance is created using check-lock-check pattern implementation
-	if singleInstance == nil {
-		// Create a lock
-		lock.Lock()
-		defer lock.Unlock()
-		// Check instance is still null as another thread of execution may have initialized it before the lock was acquired.
-		if singleInstance == nil {
-			singleInstance = &gc{client: client}
-			log.FromContext(ctx).Info("Gitea Client Instance created now.")
-			go singleInstance.Start(ctx)
-		} else {
-			log.FromContext(ctx).Info("Gitea Client Instance already created.")
-		}
-	} else {
-		log.FromContext(ctx).Info("Gitea Client Instance already created.")
+// pageSize is the number of items requested per page when listing
+const pageSize = 50
+
+// teamUnits are the repository units a team created by the provider has
+// access to
+var teamUnits = []gitea.RepoUnitType{
+	gitea.RepoUnitCode,
+	gitea.RepoUnitIssues,
+	gitea.RepoUnitPulls,
+	gitea.RepoUnitReleases,
+	gitea.RepoUnitWiki,
+}
+
+// New returns a git provider for a gitea server.
+// To create/list tokens we can only use basic authentication using username
+// and password
+func New(url string, creds gitprovider.Credentials, opts ...gitea.ClientOption) (gitprovider.GitProvider, error) {
+	opts = append([]gitea.ClientOption{gitea.SetBasicAuth(creds.UserName, creds.Password)}, opts...)
+	giteaClient, err := gitea.NewClient(url, opts...)
+	if err != nil {
+		return nil, err
 	}
-	return singleInstance, nil
+	return &gc{giteaClient: giteaClient}, nil
 }
 
 type gc struct {
-	client resource.APIPatchingApplicator
-
 	giteaClient *gitea.Client
-	l           logr.Logger
-}
-
-func (r *gc) Start(ctx context.Context) {
-	for {
-		select {
-		// The context is the one returned by ctrl.SetupSignalHandler().
-		// cancel() of this context will trigger <- ctx.Done().
-		// The Idea for continuously retrying is for enabling the user to
-		// create a secret eventually even after the controllers are started.
-		case <-ctx.Done():
-			fmt.Printf("controller manager context cancelled: Exit\n")
-			return
-		default:
-			r.l = log.FromContext(ctx)
-			//var err error
-			time.Sleep(5 * time.Second)
-
-			gitURL, ok := os.LookupEnv("GIT_URL")
-			if !ok {
-				r.l.Error(fmt.Errorf("git url not defined"), "cannot connect to git server")
-				break
-			}
+}
 
-			namespace := os.Getenv("POD_NAMESPACE")
-			if gitNamespace, ok := os.LookupEnv("GIT_NAMESPACE"); ok {
-				namespace = gitNamespace
-			}
-			secretName := "git-user-secret"
-			if gitSecretName, ok := os.LookupEnv("GIT_SECRET_NAME"); ok {
-				secretName = gitSecretName
-			}
+func (r *gc) IsInitialized() bool {
+	return r.giteaClient != nil
+}
 
-			// get secret that was created when installing gitea
-			secret := &corev1.Secret{}
-			if err := r.client.Get(ctx, types.NamespacedName{
-				Namespace: namespace,
-				Name:      secretName,
-			},
-				secret); err != nil {
-				r.l.Error(err, "Cannot get secret, please follow README and create the gitea secret")
-				break
-			}
+func (r *gc) GetMyUserInfo() (*gitprovider.User, error) {
+	u, resp, err := r.giteaClient.GetMyUserInfo()
+	if err != nil {
+		return nil, toError(resp, err)
+	}
+	return &gitprovider.User{ID: u.ID, UserName: u.UserName}, nil
+}
+
+func (r *gc) GetRepo(owner string, name string) (*gitprovider.Repository, error) {
+	repo, resp, err := r.giteaClient.GetRepo(owner, name)
+	if err != nil {
+		return nil, toError(resp, err)
+	}
+	return toRepository(repo), nil
+}
+
+func (r *gc) CreateRepo(opts gitprovider.CreateRepoOptions) (*gitprovider.Repository, error) {
+	createRepo := gitea.CreateRepoOption{
+		Name:          opts.Name,
+		Description:   opts.Description,
+		Private:       opts.Private,
+		IssueLabels:   opts.IssueLabels,
+		Gitignores:    opts.Gitignores,
+		License:       opts.License,
+		Readme:        opts.Readme,
+		DefaultBranch: opts.DefaultBranch,
+		TrustModel:    gitea.TrustModel(opts.TrustModel),
+		AutoInit:      opts.AutoInit,
+	}
+	var repo *gitea.Repository
+	var resp *gitea.Response
+	var err error
+	if opts.Owner != "" {
+		repo, resp, err = r.giteaClient.CreateOrgRepo(opts.Owner, createRepo)
+	} else {
+		repo, resp, err = r.giteaClient.CreateRepo(createRepo)
+	}
+	if err != nil {
+		return nil, toError(resp, err)
+	}
+	return toRepository(repo), nil
+}
+
+func (r *gc) MigrateRepo(opts gitprovider.MigrateRepoOptions) (*gitprovider.Repository, error) {
+	repo, resp, err := r.giteaClient.MigrateRepo(gitea.MigrateRepoOption{
+		RepoName:       opts.Name,
+		RepoOwner:      opts.Owner,
+		CloneAddr:      opts.CloneURL,
+		Service:        gitea.GitServicePlain,
+		AuthUsername:   opts.AuthUserName,
+		AuthPassword:   opts.AuthPassword,
+		AuthToken:      opts.AuthToken,
+		Mirror:         opts.Mirror,
+		Private:        opts.Private,
+		Description:    opts.Description,
+		MirrorInterval: opts.MirrorInterval,
+	})
+	if err != nil {
+		return nil, toError(resp, err)
+	}
+	return toRepository(repo), nil
+}
+
+func (r *gc) EditRepo(owner string, name string, opts gitprovider.EditRepoOptions) (*gitprovider.Repository, error) {
+	repo, resp, err := r.giteaClient.EditRepo(owner, name, gitea.EditRepoOption{
+		Name:           &name,
+		Description:    opts.Description,
+		Private:        opts.Private,
+		DefaultBranch:  opts.DefaultBranch,
+		MirrorInterval: opts.MirrorInterval,
+	})
+	if err != nil {
+		return nil, toError(resp, err)
+	}
+	return toRepository(repo), nil
+}
 
-			// To create/list tokens we can only use basic authentication using username and password
-			giteaClient, err := gitea.NewClient(
-				gitURL,
-				getClientAuth(secret))
-			if err != nil {
-				r.l.Error(err, "cannot authenticate to gitea")
-				break
+func (r *gc) DeleteRepo(owner string, name string) error {
+	resp, err := r.giteaClient.DeleteRepo(owner, name)
+	return toError(resp, err)
+}
+
+func (r *gc) GetOrg(name string) (*gitprovider.Organization, error) {
+	org, resp, err := r.giteaClient.GetOrg(name)
+	if err != nil {
+		return nil, toError(resp, err)
+	}
+	return toOrganization(org), nil
+}
+
+func (r *gc) CreateOrg(opts gitprovider.CreateOrgOptions) (*gitprovider.Organization, error) {
+	visibility := gitea.VisibleTypePublic
+	if opts.Private {
+		visibility = gitea.VisibleTypePrivate
+	}
+	org, resp, err := r.giteaClient.CreateOrg(gitea.CreateOrgOption{
+		Name:        opts.Name,
+		Description: opts.Description,
+		Visibility:  visibility,
+	})
+	if err != nil {
+		return nil, toError(resp, err)
+	}
+	return toOrganization(org), nil
+}
+
+// AddTeamRepo creates the team with the permission when it does not exist and
+// updates the permission of an existing team before adding the repository
+func (r *gc) AddTeamRepo(opts gitprovider.AddTeamRepoOptions) error {
+	team, err := r.getTeam(opts.Org, opts.Team)
+	if err != nil {
+		return err
+	}
+	permission := gitea.AccessMode(opts.Permission)
+	var resp *gitea.Response
+	if team == nil {
+		team, resp, err = r.giteaClient.CreateTeam(opts.Org, gitea.CreateTeamOption{
+			Name:       opts.Team,
+			Permission: permission,
+			Units:      teamUnits,
+		})
+		if err != nil {
+			return toError(resp, err)
+		}
+	} else if team.Permission != permission {
+		if resp, err := r.giteaClient.EditTeam(team.ID, gitea.EditTeamOption{
+			Name:       team.Name,
+			Permission: permission,
+			Units:      team.Units,
+		}); err != nil {
+			return toError(resp, err)
+		}
+	}
+	resp, err = r.giteaClient.AddTeamRepository(team.ID, opts.Org, opts.Repo)
+	return toError(resp, err)
+}
+
+// getTeam returns the team of the organization with the name or nil if the
+// team does not exist
+func (r *gc) getTeam(org, name string) (*gitea.Team, error) {
+	for page := 1; ; page++ {
+		teams, resp, err := r.giteaClient.ListOrgTeams(org, gitea.ListTeamsOptions{ListOptions: gitea.ListOptions{Page: page, PageSize: pageSize}})
+		if err != nil {
+			return nil, toError(resp, err)
+		}
+		for _, team := range teams {
+			if team.Name == name {
+				return team, nil
 			}
+		}
+		if len(teams) < pageSize {
+			return nil, nil
+		}
+	}
+}
 
-			r.giteaClient = giteaClient
-			r.l.Info("gitea init done")
-			return
+func (r *gc) ListBranchProtections(owner string, repo string) ([]*gitprovider.BranchProtection, error) {
+	bps, err := listAll(func(opts gitea.ListOptions) ([]*gitea.BranchProtection, *gitea.Response, error) {
+		return r.giteaClient.ListBranchProtections(owner, repo, gitea.ListBranchProtectionsOptions{ListOptions: opts})
+	})
+	if err != nil {
+		return nil, err
+	}
+	branchProtections := make([]*gitprovider.BranchProtection, 0, len(bps))
+	for _, bp := range bps {
+		branchProtections = append(branchProtections, &gitprovider.BranchProtection{
+			Branch:             bp.BranchName,
+			EnablePush:         bp.EnablePush,
+			PushAllowlistUsers: bp.PushWhitelistUsernames,
+			PushAllowlistTeams: bp.PushWhitelistTeams,
+			RequiredApprovals:  bp.RequiredApprovals,
+		})
+	}
+	return branchProtections, nil
+}
+
+func (r *gc) CreateBranchProtection(owner string, repo string, bp gitprovider.BranchProtection) error {
+	_, resp, err := r.giteaClient.CreateBranchProtection(owner, repo, gitea.CreateBranchProtectionOption{
+		BranchName:             bp.Branch,
+		EnablePush:             bp.EnablePush,
+		EnablePushWhitelist:    len(bp.PushAllowlistUsers) > 0 || len(bp.PushAllowlistTeams) > 0,
+		PushWhitelistUsernames: bp.PushAllowlistUsers,
+		PushWhitelistTeams:     bp.PushAllowlistTeams,
+		RequiredApprovals:      bp.RequiredApprovals,
+	})
+	return toError(resp, err)
+}
+
+func (r *gc) EditBranchProtection(owner string, repo string, bp gitprovider.BranchProtection) error {
+	enablePushAllowlist := len(bp.PushAllowlistUsers) > 0 || len(bp.PushAllowlistTeams) > 0
+	_, resp, err := r.giteaClient.EditBranchProtection(owner, repo, bp.Branch, gitea.EditBranchProtectionOption{
+		EnablePush:             &bp.EnablePush,
+		EnablePushWhitelist:    &enablePushAllowlist,
+		PushWhitelistUsernames: bp.PushAllowlistUsers,
+		PushWhitelistTeams:     bp.PushAllowlistTeams,
+		RequiredApprovals:      &bp.RequiredApprovals,
+	})
+	return toError(resp, err)
+}
+
+func (r *gc) DeleteBranchProtection(owner string, repo string, branch string) error {
+	resp, err := r.giteaClient.DeleteBranchProtection(owner, repo, branch)
+	return toError(resp, err)
+}
+
+func (r *gc) ListWebhooks(owner string, repo string) ([]*gitprovider.Webhook, error) {
+	hooks, err := listAll(func(opts gitea.ListOptions) ([]*gitea.Hook, *gitea.Response, error) {
+		return r.giteaClient.ListRepoHooks(owner, repo, gitea.ListHooksOptions{ListOptions: opts})
+	})
+	if err != nil {
+		return nil, err
+	}
+	webhooks := make([]*gitprovider.Webhook, 0, len(hooks))
+	for _, hook := range hooks {
+		webhooks = append(webhooks, &gitprovider.Webhook{
+			ID:          hook.ID,
+			URL:         hook.Config["url"],
+			ContentType: hook.Config["content_type"],
+			Events:      hook.Events,
+			Active:      hook.Active,
+		})
+	}
+	return webhooks, nil
+}
+
+func (r *gc) CreateWebhook(owner string, repo string, hook gitprovider.Webhook) error {
+	_, resp, err := r.giteaClient.CreateRepoHook(owner, repo, gitea.CreateHookOption{
+		Type:   gitea.HookTypeGitea,
+		Config: toHookConfig(hook),
+		Events: hook.Events,
+		Active: hook.Active,
+	})
+	return toError(resp, err)
+}
+
+func (r *gc) EditWebhook(owner string, repo string, hook gitprovider.Webhook) error {
+	resp, err := r.giteaClient.EditRepoHook(owner, repo, hook.ID, gitea.EditHookOption{
+		Config: toHookConfig(hook),
+		Events: hook.Events,
+		Active: &hook.Active,
+	})
+	return toError(resp, err)
+}
+
+func (r *gc) DeleteWebhook(owner string, repo string, id int64) error {
+	resp, err := r.giteaClient.DeleteRepoHook(owner, repo, id)
+	return toError(resp, err)
+}
+
+func (r *gc) ListCollaborators(owner string, repo string) ([]*gitprovider.Collaborator, error) {
+	users, err := listAll(func(opts gitea.ListOptions) ([]*gitea.User, *gitea.Response, error) {
+		return r.giteaClient.ListCollaborators(owner, repo, gitea.ListCollaboratorsOptions{ListOptions: opts})
+	})
+	if err != nil {
+		return nil, err
+	}
+	collaborators := make([]*gitprovider.Collaborator, 0, len(users))
+	for _, u := range users {
+		p, resp, err := r.giteaClient.CollaboratorPermission(owner, repo, u.UserName)
+		if err != nil {
+			return nil, toError(resp, err)
 		}
+		collaborators = append(collaborators, &gitprovider.Collaborator{
+			UserName:   u.UserName,
+			Permission: gitprovider.Permission(p.Permission),
+		})
 	}
+	return collaborators, nil
 }
 
-func getClientAuth(secret *corev1.Secret) gitea.ClientOption {
-	return gitea.SetBasicAuth(string(secret.Data["username"]), string(secret.Data["password"]))
+func (r *gc) SetCollaborator(owner string, repo string, collaborator gitprovider.Collaborator) error {
+	permission := gitea.AccessMode(collaborator.Permission)
+	resp, err := r.giteaClient.AddCollaborator(owner, repo, collaborator.UserName, gitea.AddCollaboratorOption{Permission: &permission})
+	return toError(resp, err)
 }
 
-func (r *gc) IsInitialized() bool {
-	return r.giteaClient != nil
+func (r *gc) DeleteCollaborator(owner string, repo string, userName string) error {
+	resp, err := r.giteaClient.DeleteCollaborator(owner, repo, userName)
+	return toError(resp, err)
 }
 
-func (r *gc) Get() *gitea.Client {
-	return r.giteaClient
+func (r *gc) ListDeployKeys(owner string, repo string) ([]*gitprovider.DeployKey, error) {
+	keys, err := listAll(func(opts gitea.ListOptions) ([]*gitea.DeployKey, *gitea.Response, error) {
+		return r.giteaClient.ListDeployKeys(owner, repo, gitea.ListDeployKeysOptions{ListOptions: opts})
+	})
+	if err != nil {
+		return nil, err
+	}
+	deployKeys := make([]*gitprovider.DeployKey, 0, len(keys))
+	for _, key := range keys {
+		deployKeys = append(deployKeys, &gitprovider.DeployKey{
+			ID:       key.ID,
+			Title:    key.Title,
+			Key:      key.Key,
+			ReadOnly: key.ReadOnly,
+		})
+	}
+	return deployKeys, nil
+}
+
+func (r *gc) CreateDeployKey(owner string, repo string, key gitprovider.DeployKey) error {
+	_, resp, err := r.giteaClient.CreateDeployKey(owner, repo, gitea.CreateKeyOption{
+		Title:    key.Title,
+		Key:      key.Key,
+		ReadOnly: key.ReadOnly,
+	})
+	return toError(resp, err)
+}
+
+func (r *gc) DeleteDeployKey(owner string, repo string, id int64) error {
+	resp, err := r.giteaClient.DeleteDeployKey(owner, repo, id)
+	return toError(resp, err)
 }
 
-func (r *gc) GetMyUserInfo() (*gitea.User, *gitea.Response, error) {
-	return r.giteaClient.GetMyUserInfo()
+func (r *gc) ListAccessTokens() ([]*gitprovider.AccessToken, error) {
+	tokens, err := listAll(func(opts gitea.ListOptions) ([]*gitea.AccessToken, *gitea.Response, error) {
+		return r.giteaClient.ListAccessTokens(gitea.ListAccessTokensOptions{ListOptions: opts})
+	})
+	if err != nil {
+		return nil, err
+	}
+	accessTokens := make([]*gitprovider.AccessToken, 0, len(tokens))
+	for _, token := range tokens {
+		accessTokens = append(accessTokens, toAccessToken(token))
+	}
+	return accessTokens, nil
+}
+
+// CreateAccessToken creates an access token, gitea tokens do not expire so
+// the expiry is ignored
+func (r *gc) CreateAccessToken(opts gitprovider.CreateAccessTokenOptions) (*gitprovider.AccessToken, error) {
+	scopes := make([]gitea.AccessTokenScope, 0, len(opts.Scopes))
+	for _, scope := range opts.Scopes {
+		scopes = append(scopes, gitea.AccessTokenScope(scope))
+	}
+	token, resp, err := r.giteaClient.CreateAccessToken(gitea.CreateAccessTokenOption{
+		Name:   opts.Name,
+		Scopes: scopes,
+	})
+	if err != nil {
+		return nil, toError(resp, err)
+	}
+	return toAccessToken(token), nil
 }
 
-func (r *gc) DeleteRepo(owner string, repo string) (*gitea.Response, error) {
-	return r.giteaClient.DeleteRepo(owner, repo)
+func (r *gc) DeleteAccessToken(name string) error {
+	resp, err := r.giteaClient.DeleteAccessToken(name)
+	return toError(resp, err)
 }
 
-func (r *gc) GetRepo(userName string, repoCRName string) (*gitea.Repository, *gitea.Response, error) {
-	return r.giteaClient.GetRepo(userName, repoCRName)
+// listAll returns the items of all pages of a list call
+func listAll[T any](list func(opts gitea.ListOptions) ([]T, *gitea.Response, error)) ([]T, error) {
+	items := []T{}
+	for page := 1; ; page++ {
+		l, resp, err := list(gitea.ListOptions{Page: page, PageSize: pageSize})
+		if err != nil {
+			return nil, toError(resp, err)
+		}
+		items = append(items, l...)
+		if len(l) < pageSize {
+			return items, nil
+		}
+	}
 }
 
-func (r *gc) CreateRepo(createRepoOption gitea.CreateRepoOption) (*gitea.Repository, *gitea.Response, error) {
-	return r.giteaClient.CreateRepo(createRepoOption)
+// toError classifies the error of a request by the status of the response,
+// the response is nil when the request was not sent
+func toError(resp *gitea.Response, err error) error {
+	if resp == nil || resp.Response == nil {
+		return gitprovider.NewError(0, err)
+	}
+	return gitprovider.NewError(resp.StatusCode, err)
 }
 
-func (r *gc) EditRepo(userName string, repoCRName string, editRepoOption gitea.EditRepoOption) (*gitea.Repository, *gitea.Response, error) {
-	return r.giteaClient.EditRepo(userName, repoCRName, editRepoOption)
+func toRepository(repo *gitea.Repository) *gitprovider.Repository {
+	r := &gitprovider.Repository{
+		ID:             repo.ID,
+		Name:           repo.Name,
+		Description:    repo.Description,
+		Private:        repo.Private,
+		DefaultBranch:  repo.DefaultBranch,
+		CloneURL:       repo.CloneURL,
+		Mirror:         repo.Mirror,
+		MirrorInterval: repo.MirrorInterval,
+	}
+	if repo.Owner != nil {
+		r.Owner = repo.Owner.UserName
+	}
+	return r
 }
 
-func (r *gc) DeleteAccessToken(value interface{}) (*gitea.Response, error) {
-	return r.giteaClient.DeleteAccessToken(value)
+func toHookConfig(hook gitprovider.Webhook) map[string]string {
+	config := map[string]string{
+		"url":          hook.URL,
+		"content_type": hook.ContentType,
+	}
+	if config["content_type"] == "" {
+		config["content_type"] = "json"
+	}
+	if hook.Secret != "" {
+		config["secret"] = hook.Secret
+	}
+	return config
 }
 
-func (r *gc) ListAccessTokens(opts gitea.ListAccessTokensOptions) ([]*gitea.AccessToken, *gitea.Response, error) {
-	return r.giteaClient.ListAccessTokens(opts)
+func toOrganization(org *gitea.Organization) *gitprovider.Organization {
+	return &gitprovider.Organization{
+		ID:          org.ID,
+		Name:        org.UserName,
+		Description: org.Description,
+	}
 }
 
-func (r *gc) CreateAccessToken(opt gitea.CreateAccessTokenOption) (*gitea.AccessToken, *gitea.Response, error) {
-	return r.giteaClient.CreateAccessToken(opt)
+func toAccessToken(token *gitea.AccessToken) *gitprovider.AccessToken {
+	scopes := make([]string, 0, len(token.Scopes))
+	for _, scope := range token.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+	return &gitprovider.AccessToken{
+		ID:     token.ID,
+		Name:   token.Name,
+		Token:  token.Token,
+		Scopes: scopes,
+	}
 }
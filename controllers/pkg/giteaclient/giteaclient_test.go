@@ -1,50 +1,450 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
 package giteaclient
 
 import (
-	"context"
-	"github.com/nephio-project/nephio/controllers/pkg/resource"
-	"reflect"
-	ctrl "sigs.k8s.io/controller-runtime"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/google/go-cmp/cmp"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
 )
 
-func TestClient(t *testing.T) {
-	ctx := ctrl.SetupSignalHandler()
+// giteaServer is a minimal in memory stand-in of the gitea api
+type giteaServer struct {
+	m      sync.Mutex
+	user   string
+	repos  map[string]*gitea.Repository
+	tokens map[string]*gitea.AccessToken
+	orgs   map[string]*gitea.Organization
+	teams  map[string][]*gitea.Team
+	// teamRepos holds the repos of a team by team id
+	teamRepos map[int64][]string
+}
 
-	type args struct {
-		ctx    context.Context
-		client resource.APIPatchingApplicator
+func newGiteaServer(t *testing.T) *httptest.Server {
+	s := &giteaServer{
+		user:      "nephio",
+		repos:     map[string]*gitea.Repository{},
+		tokens:    map[string]*gitea.AccessToken{},
+		orgs:      map[string]*gitea.Organization{},
+		teams:     map[string][]*gitea.Team{},
+		teamRepos: map[int64][]string{},
 	}
-	tests := []struct {
-		name    string
-		args    args
-		want    GiteaClient
-		wantErr bool
-	}{
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func (s *giteaServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if u, _, ok := req.BasicAuth(); !ok || u != s.user {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	path := strings.TrimPrefix(req.URL.Path, "/api/v1")
+	owner := &gitea.User{ID: 1, UserName: s.user}
+	switch {
+	case path == "/version":
+		writeJSON(w, http.StatusOK, map[string]string{"version": "1.19.3"})
+	case path == "/user":
+		writeJSON(w, http.StatusOK, owner)
+	case path == "/user/repos" && req.Method == http.MethodPost:
+		s.createRepo(w, req, owner)
+	case path == "/orgs" && req.Method == http.MethodPost:
+		opt := gitea.CreateOrgOption{}
+		_ = json.NewDecoder(req.Body).Decode(&opt)
+		org := &gitea.Organization{ID: int64(len(s.orgs) + 10), UserName: opt.Name, Description: opt.Description, Visibility: string(opt.Visibility)}
+		s.orgs[opt.Name] = org
+		writeJSON(w, http.StatusCreated, org)
+	case strings.HasPrefix(path, "/org/") && strings.HasSuffix(path, "/repos") && req.Method == http.MethodPost:
+		org, ok := s.orgs[strings.TrimSuffix(strings.TrimPrefix(path, "/org/"), "/repos")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		s.createRepo(w, req, &gitea.User{ID: org.ID, UserName: org.UserName})
+	case strings.HasPrefix(path, "/orgs/"):
+		parts := strings.Split(strings.TrimPrefix(path, "/orgs/"), "/")
+		org, ok := s.orgs[parts[0]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch {
+		case len(parts) == 1:
+			writeJSON(w, http.StatusOK, org)
+		case parts[1] == "teams" && req.Method == http.MethodGet:
+			writeJSON(w, http.StatusOK, s.teams[org.UserName])
+		case parts[1] == "teams" && req.Method == http.MethodPost:
+			opt := gitea.CreateTeamOption{}
+			_ = json.NewDecoder(req.Body).Decode(&opt)
+			team := &gitea.Team{ID: int64(len(s.teams[org.UserName]) + 100), Name: opt.Name, Permission: opt.Permission, Units: opt.Units}
+			s.teams[org.UserName] = append(s.teams[org.UserName], team)
+			writeJSON(w, http.StatusCreated, team)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case strings.HasPrefix(path, "/teams/") && req.Method == http.MethodPut:
+		var id int64
+		var org, repo string
+		_, _ = fmt.Sscanf(strings.ReplaceAll(strings.TrimPrefix(path, "/teams/"), "/", " "), "%d repos %s %s", &id, &org, &repo)
+		s.teamRepos[id] = append(s.teamRepos[id], org+"/"+repo)
+		w.WriteHeader(http.StatusNoContent)
+	case path == "/repos/migrate" && req.Method == http.MethodPost:
+		opt := gitea.MigrateRepoOption{}
+		_ = json.NewDecoder(req.Body).Decode(&opt)
+		if opt.CloneAddr == "" || opt.Service != gitea.GitServicePlain {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		repo := &gitea.Repository{
+			ID:             int64(len(s.repos) + 1),
+			Owner:          &gitea.User{UserName: opt.RepoOwner},
+			Name:           opt.RepoName,
+			Description:    opt.Description,
+			Private:        opt.Private,
+			Mirror:         opt.Mirror,
+			MirrorInterval: opt.MirrorInterval,
+			CloneURL:       "http://gitea/" + opt.RepoOwner + "/" + opt.RepoName + ".git",
+		}
+		s.repos[opt.RepoOwner+"/"+opt.RepoName] = repo
+		writeJSON(w, http.StatusCreated, repo)
+	case strings.HasPrefix(path, "/repos/"):
+		key := strings.TrimPrefix(path, "/repos/")
+		repo, ok := s.repos[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch req.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, repo)
+		case http.MethodPatch:
+			opt := gitea.EditRepoOption{}
+			_ = json.NewDecoder(req.Body).Decode(&opt)
+			if opt.Description != nil {
+				repo.Description = *opt.Description
+			}
+			if opt.Private != nil {
+				repo.Private = *opt.Private
+			}
+			if opt.MirrorInterval != nil {
+				repo.MirrorInterval = *opt.MirrorInterval
+			}
+			writeJSON(w, http.StatusOK, repo)
+		case http.MethodDelete:
+			delete(s.repos, key)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	case path == "/users/"+s.user+"/tokens":
+		switch req.Method {
+		case http.MethodGet:
+			tokens := []*gitea.AccessToken{}
+			for _, token := range s.tokens {
+				tokens = append(tokens, &gitea.AccessToken{ID: token.ID, Name: token.Name, Scopes: token.Scopes})
+			}
+			writeJSON(w, http.StatusOK, tokens)
+		case http.MethodPost:
+			opt := gitea.CreateAccessTokenOption{}
+			_ = json.NewDecoder(req.Body).Decode(&opt)
+			token := &gitea.AccessToken{ID: int64(len(s.tokens) + 1), Name: opt.Name, Token: "secret-" + opt.Name, Scopes: opt.Scopes}
+			s.tokens[opt.Name] = token
+			writeJSON(w, http.StatusCreated, token)
+		}
+	case strings.HasPrefix(path, "/users/"+s.user+"/tokens/") && req.Method == http.MethodDelete:
+		name := strings.TrimPrefix(path, "/users/"+s.user+"/tokens/")
+		if _, ok := s.tokens[name]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(s.tokens, name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *giteaServer) createRepo(w http.ResponseWriter, req *http.Request, owner *gitea.User) {
+	opt := gitea.CreateRepoOption{}
+	_ = json.NewDecoder(req.Body).Decode(&opt)
+	repo := &gitea.Repository{
+		ID:          int64(len(s.repos) + 1),
+		Owner:       owner,
+		Name:        opt.Name,
+		Description: opt.Description,
+		Private:     opt.Private,
+		CloneURL:    "http://gitea/" + owner.UserName + "/" + opt.Name + ".git",
+	}
+	s.repos[owner.UserName+"/"+opt.Name] = repo
+	writeJSON(w, http.StatusCreated, repo)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestRepositories(t *testing.T) {
+	srv := newGiteaServer(t)
+	c, err := New(srv.URL, gitprovider.Credentials{UserName: "nephio", Password: "secret"})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	u, err := c.GetMyUserInfo()
+	if err != nil {
+		t.Fatalf("GetMyUserInfo() unexpected error: %v", err)
+	}
+	if _, err := c.GetRepo(u.UserName, "mgmt"); gitprovider.ClassOf(err) != gitprovider.ErrorClassNotFound {
+		t.Errorf("GetRepo() expected not found error for a repo that does not exist, got %v", err)
+	}
+	created, err := c.CreateRepo(gitprovider.CreateRepoOptions{Name: "mgmt", Description: "a", AutoInit: true})
+	if err != nil {
+		t.Fatalf("CreateRepo() unexpected error: %v", err)
+	}
+	want := &gitprovider.Repository{ID: 1, Owner: "nephio", Name: "mgmt", Description: "a", CloneURL: "http://gitea/nephio/mgmt.git"}
+	if diff := cmp.Diff(want, created); diff != "" {
+		t.Errorf("CreateRepo() -want, +got:\n%s", diff)
+	}
+	description := "b"
+	private := true
+	edited, err := c.EditRepo(u.UserName, "mgmt", gitprovider.EditRepoOptions{Description: &description, Private: &private})
+	if err != nil {
+		t.Fatalf("EditRepo() unexpected error: %v", err)
+	}
+	want.Description = "b"
+	want.Private = true
+	if diff := cmp.Diff(want, edited); diff != "" {
+		t.Errorf("EditRepo() -want, +got:\n%s", diff)
+	}
+	if err := c.DeleteRepo(u.UserName, "mgmt"); err != nil {
+		t.Fatalf("DeleteRepo() unexpected error: %v", err)
+	}
+	if _, err := c.GetRepo(u.UserName, "mgmt"); err == nil {
+		t.Errorf("GetRepo() expected error for a deleted repo")
+	}
+}
+
+func TestMigrateRepo(t *testing.T) {
+	srv := newGiteaServer(t)
+	c, err := New(srv.URL, gitprovider.Credentials{UserName: "nephio", Password: "secret"})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	migrated, err := c.MigrateRepo(gitprovider.MigrateRepoOptions{
+		Owner:          "nephio",
+		Name:           "catalog",
+		CloneURL:       "https://github.com/nephio-project/catalog.git",
+		Mirror:         true,
+		MirrorInterval: "8h0m0s",
+	})
+	if err != nil {
+		t.Fatalf("MigrateRepo() unexpected error: %v", err)
+	}
+	want := &gitprovider.Repository{ID: 1, Owner: "nephio", Name: "catalog", CloneURL: "http://gitea/nephio/catalog.git", Mirror: true, MirrorInterval: "8h0m0s"}
+	if diff := cmp.Diff(want, migrated); diff != "" {
+		t.Errorf("MigrateRepo() -want, +got:\n%s", diff)
+	}
+	interval := "1h0m0s"
+	edited, err := c.EditRepo("nephio", "catalog", gitprovider.EditRepoOptions{MirrorInterval: &interval})
+	if err != nil {
+		t.Fatalf("EditRepo() unexpected error: %v", err)
+	}
+	want.MirrorInterval = interval
+	if diff := cmp.Diff(want, edited); diff != "" {
+		t.Errorf("EditRepo() -want, +got:\n%s", diff)
+	}
+}
+
+func TestAccessTokens(t *testing.T) {
+	srv := newGiteaServer(t)
+	c, err := New(srv.URL, gitprovider.Credentials{UserName: "nephio", Password: "secret"})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	token, err := c.CreateAccessToken(gitprovider.CreateAccessTokenOptions{Name: "mgmt-default", Scopes: []string{gitprovider.ScopeRepo}})
+	if err != nil {
+		t.Fatalf("CreateAccessToken() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff("secret-mgmt-default", token.Token); diff != "" {
+		t.Errorf("CreateAccessToken() -want, +got:\n%s", diff)
+	}
+	tokens, err := c.ListAccessTokens()
+	if err != nil {
+		t.Fatalf("ListAccessTokens() unexpected error: %v", err)
+	}
+	want := []*gitprovider.AccessToken{{ID: 1, Name: "mgmt-default", Scopes: []string{"repo"}}}
+	if diff := cmp.Diff(want, tokens); diff != "" {
+		t.Errorf("ListAccessTokens() -want, +got:\n%s", diff)
+	}
+	if err := c.DeleteAccessToken("mgmt-default"); err != nil {
+		t.Fatalf("DeleteAccessToken() unexpected error: %v", err)
+	}
+	if err := c.DeleteAccessToken("mgmt-default"); err == nil {
+		t.Errorf("DeleteAccessToken() expected error for a deleted token")
+	}
+}
 
-		{
-			name:    "ctx nil check",
-			args:    args{nil, resource.NewAPIPatchingApplicator(nil)},
-			want:    nil,
-			wantErr: true,
-		},
-		{
-			name:    "client nil check",
-			args:    args{ctx, resource.NewAPIPatchingApplicator(nil)},
-			want:    nil,
-			wantErr: true,
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got, err := GetClient(tt.args.ctx, tt.args.client)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("GetClient() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("GetClient() got = %v, want %v", got, tt.want)
+func TestUnauthorized(t *testing.T) {
+	srv := newGiteaServer(t)
+	_, err := New(srv.URL, gitprovider.Credentials{UserName: "other", Password: "secret"})
+	if err == nil {
+		t.Errorf("New() expected error for wrong credentials")
+	}
+}
+
+func TestErrorClass(t *testing.T) {
+	cases := map[string]struct {
+		statusCode int
+		want       gitprovider.ErrorClass
+	}{
+		"Unauthorized": {statusCode: http.StatusUnauthorized, want: gitprovider.ErrorClassUnauthorized},
+		"Forbidden":    {statusCode: http.StatusForbidden, want: gitprovider.ErrorClassUnauthorized},
+		"NotFound":     {statusCode: http.StatusNotFound, want: gitprovider.ErrorClassNotFound},
+		"Conflict":     {statusCode: http.StatusConflict, want: gitprovider.ErrorClassConflict},
+		"RateLimited":  {statusCode: http.StatusTooManyRequests, want: gitprovider.ErrorClassRateLimited},
+		"Unavailable":  {statusCode: http.StatusBadGateway, want: gitprovider.ErrorClassUnavailable},
+		"Unknown":      {statusCode: http.StatusUnprocessableEntity, want: gitprovider.ErrorClassUnknown},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if req.URL.Path == "/api/v1/version" {
+					writeJSON(w, http.StatusOK, map[string]string{"version": "1.19.3"})
+					return
+				}
+				w.WriteHeader(tc.statusCode)
+			}))
+			defer srv.Close()
+			c, err := New(srv.URL, gitprovider.Credentials{UserName: "nephio", Password: "secret"})
+			if err != nil {
+				t.Fatalf("New() unexpected error: %v", err)
+			}
+			_, err = c.CreateRepo(gitprovider.CreateRepoOptions{Name: "mgmt"})
+			if diff := cmp.Diff(tc.want, gitprovider.ClassOf(err)); diff != "" {
+				t.Errorf("ClassOf() -want, +got:\n%s", diff)
 			}
 		})
 	}
+
+	// no response is received from a server that is down
+	srv := newGiteaServer(t)
+	c, err := New(srv.URL, gitprovider.Credentials{UserName: "nephio", Password: "secret"})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	srv.Close()
+	_, err = c.GetMyUserInfo()
+	if diff := cmp.Diff(gitprovider.ErrorClassUnavailable, gitprovider.ClassOf(err)); diff != "" {
+		t.Errorf("ClassOf() -want, +got:\n%s", diff)
+	}
+}
+
+func TestOrganizations(t *testing.T) {
+	srv := newGiteaServer(t)
+	c, err := New(srv.URL, gitprovider.Credentials{UserName: "nephio", Password: "secret"})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	if _, err := c.GetOrg("edge"); err == nil {
+		t.Errorf("GetOrg() expected error for an org that does not exist")
+	}
+	org, err := c.CreateOrg(gitprovider.CreateOrgOptions{Name: "edge", Private: true})
+	if err != nil {
+		t.Fatalf("CreateOrg() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(&gitprovider.Organization{ID: 10, Name: "edge"}, org); diff != "" {
+		t.Errorf("CreateOrg() -want, +got:\n%s", diff)
+	}
+	repo, err := c.CreateRepo(gitprovider.CreateRepoOptions{Owner: "edge", Name: "edge01"})
+	if err != nil {
+		t.Fatalf("CreateRepo() unexpected error: %v", err)
+	}
+	want := &gitprovider.Repository{ID: 1, Owner: "edge", Name: "edge01", CloneURL: "http://gitea/edge/edge01.git"}
+	if diff := cmp.Diff(want, repo); diff != "" {
+		t.Errorf("CreateRepo() -want, +got:\n%s", diff)
+	}
+	if _, err := c.GetRepo("edge", "edge01"); err != nil {
+		t.Errorf("GetRepo() unexpected error: %v", err)
+	}
+	// the team is created once and reused afterwards
+	for i := 0; i < 2; i++ {
+		if err := c.AddTeamRepo(gitprovider.AddTeamRepoOptions{Org: "edge", Team: "ops", Repo: "edge01", Permission: gitprovider.PermissionWrite}); err != nil {
+			t.Fatalf("AddTeamRepo() unexpected error: %v", err)
+		}
+	}
+	s := srv.Config.Handler.(*giteaServer)
+	if diff := cmp.Diff(1, len(s.teams["edge"])); diff != "" {
+		t.Errorf("teams -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"edge/edge01", "edge/edge01"}, s.teamRepos[100]); diff != "" {
+		t.Errorf("team repos -want, +got:\n%s", diff)
+	}
+}
+
+func TestListPages(t *testing.T) {
+	keys := []*gitea.DeployKey{}
+	for i := 0; i < 2*pageSize+1; i++ {
+		keys = append(keys, &gitea.DeployKey{ID: int64(i), Title: fmt.Sprintf("key-%d", i)})
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/api/v1/version":
+			writeJSON(w, http.StatusOK, map[string]string{"version": "1.19.3"})
+		case "/api/v1/repos/nephio/mgmt/keys":
+			var page, limit int
+			_, _ = fmt.Sscanf(req.URL.Query().Get("page"), "%d", &page)
+			_, _ = fmt.Sscanf(req.URL.Query().Get("limit"), "%d", &limit)
+			start := (page - 1) * limit
+			end := start + limit
+			if start > len(keys) {
+				start = len(keys)
+			}
+			if end > len(keys) {
+				end = len(keys)
+			}
+			writeJSON(w, http.StatusOK, keys[start:end])
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	c, err := New(srv.URL, gitprovider.Credentials{UserName: "nephio", Password: "secret"})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	got, err := c.ListDeployKeys("nephio", "mgmt")
+	if err != nil {
+		t.Fatalf("ListDeployKeys() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(len(keys), len(got)); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
 }
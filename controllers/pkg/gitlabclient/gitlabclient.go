@@ -0,0 +1,797 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitlabclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
+)
+
+const (
+	apiPath        = "/api/v4"
+	defaultTimeout = 30 * time.Second
+	// pageSize is the number of items requested per page when listing,
+	// gitlab returns 20 items per page by default and at most 100
+	pageSize = 100
+
+	visibilityPrivate = "private"
+	visibilityPublic  = "public"
+)
+
+const (
+	accessLevelNoAccess  = 0
+	accessLevelDeveloper = 30
+)
+
+// accessLevels maps the provider neutral permissions to gitlab access levels
+var accessLevels = map[gitprovider.Permission]int{
+	gitprovider.PermissionRead:  20, // reporter
+	gitprovider.PermissionWrite: 30, // developer
+	gitprovider.PermissionAdmin: 40, // maintainer
+}
+
+// repoScopes are the gitlab scopes that map to the provider neutral repo scope
+var repoScopes = []string{"read_repository", "write_repository"}
+
+// New returns a git provider for a gitlab server. GitLab authenticates api
+// calls with a personal access token of the user, which is taken from the
+// token of the credentials. Creating tokens for the user requires the user to
+// be an administrator.
+// Organizations map to top level groups and teams to subgroups of the
+// organization's group, which the project is shared with.
+func New(baseURL string, creds gitprovider.Credentials) (gitprovider.GitProvider, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if creds.Token == "" {
+		return nil, fmt.Errorf("gitlab requires a personal access token")
+	}
+	return &gl{
+		baseURL:    u.String(),
+		token:      creds.Token,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+	}, nil
+}
+
+type gl struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+type user struct {
+	ID       int64  `json:"id"`
+	UserName string `json:"username"`
+}
+
+type namespace struct {
+	FullPath string `json:"full_path"`
+}
+
+type project struct {
+	ID               int64         `json:"id"`
+	Name             string        `json:"name"`
+	Path             string        `json:"path"`
+	Description      string        `json:"description"`
+	Visibility       string        `json:"visibility"`
+	DefaultBranch    string        `json:"default_branch"`
+	HTTPURLToRepo    string        `json:"http_url_to_repo"`
+	Namespace        *namespace    `json:"namespace,omitempty"`
+	SharedWithGroups []sharedGroup `json:"shared_with_groups,omitempty"`
+}
+
+type sharedGroup struct {
+	GroupID          int64 `json:"group_id"`
+	GroupAccessLevel int   `json:"group_access_level"`
+}
+
+type shareProject struct {
+	GroupID     int64 `json:"group_id"`
+	GroupAccess int   `json:"group_access"`
+}
+
+type group struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	FullPath    string `json:"full_path"`
+	Description string `json:"description"`
+}
+
+type createGroup struct {
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	Description string `json:"description,omitempty"`
+	Visibility  string `json:"visibility"`
+	ParentID    *int64 `json:"parent_id,omitempty"`
+}
+
+type createProject struct {
+	Name                 string `json:"name"`
+	Path                 string `json:"path"`
+	NamespaceID          *int64 `json:"namespace_id,omitempty"`
+	Description          string `json:"description,omitempty"`
+	Visibility           string `json:"visibility"`
+	InitializeWithReadme bool   `json:"initialize_with_readme"`
+	DefaultBranch        string `json:"default_branch,omitempty"`
+	ImportURL            string `json:"import_url,omitempty"`
+}
+
+type editProject struct {
+	Description   *string `json:"description,omitempty"`
+	Visibility    *string `json:"visibility,omitempty"`
+	DefaultBranch *string `json:"default_branch,omitempty"`
+}
+
+type accessLevel struct {
+	AccessLevel int `json:"access_level"`
+}
+
+type protectedBranch struct {
+	Name             string        `json:"name"`
+	PushAccessLevels []accessLevel `json:"push_access_levels"`
+}
+
+type protectBranch struct {
+	Name             string `json:"name"`
+	PushAccessLevel  int    `json:"push_access_level"`
+	MergeAccessLevel int    `json:"merge_access_level"`
+}
+
+type hook struct {
+	ID                  int64  `json:"id"`
+	URL                 string `json:"url"`
+	Token               string `json:"token,omitempty"`
+	PushEvents          bool   `json:"push_events"`
+	MergeRequestsEvents bool   `json:"merge_requests_events"`
+	TagPushEvents       bool   `json:"tag_push_events"`
+	IssuesEvents        bool   `json:"issues_events"`
+	ReleasesEvents      bool   `json:"releases_events"`
+}
+
+type member struct {
+	ID          int64  `json:"id"`
+	UserName    string `json:"username"`
+	AccessLevel int    `json:"access_level"`
+}
+
+type addMember struct {
+	UserID      int64 `json:"user_id"`
+	AccessLevel int   `json:"access_level"`
+}
+
+type deployKey struct {
+	ID      int64  `json:"id"`
+	Title   string `json:"title"`
+	Key     string `json:"key"`
+	CanPush bool   `json:"can_push"`
+}
+
+type personalAccessToken struct {
+	ID     int64    `json:"id"`
+	Name   string   `json:"name"`
+	Token  string   `json:"token,omitempty"`
+	Scopes []string `json:"scopes"`
+	Active bool     `json:"active"`
+}
+
+type createPersonalAccessToken struct {
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	ExpiresAt string   `json:"expires_at,omitempty"`
+}
+
+func (r *gl) IsInitialized() bool {
+	return r.httpClient != nil
+}
+
+func (r *gl) GetMyUserInfo() (*gitprovider.User, error) {
+	u := &user{}
+	if err := r.do(http.MethodGet, "/user", nil, u); err != nil {
+		return nil, err
+	}
+	return &gitprovider.User{ID: u.ID, UserName: u.UserName}, nil
+}
+
+func (r *gl) GetRepo(owner string, name string) (*gitprovider.Repository, error) {
+	p, err := r.getProject(owner, name)
+	if err != nil {
+		return nil, err
+	}
+	return toRepository(p), nil
+}
+
+func (r *gl) CreateRepo(opts gitprovider.CreateRepoOptions) (*gitprovider.Repository, error) {
+	create := &createProject{
+		Name:                 opts.Name,
+		Path:                 opts.Name,
+		Description:          opts.Description,
+		Visibility:           toVisibility(opts.Private),
+		InitializeWithReadme: opts.AutoInit,
+		DefaultBranch:        opts.DefaultBranch,
+	}
+	if opts.Owner != "" {
+		g, err := r.getGroup(opts.Owner)
+		if err != nil {
+			return nil, err
+		}
+		create.NamespaceID = &g.ID
+	}
+	p := &project{}
+	if err := r.do(http.MethodPost, "/projects", create, p); err != nil {
+		return nil, err
+	}
+	return toRepository(p), nil
+}
+
+// MigrateRepo imports the upstream repository once, pull mirrors are not
+// available in gitlab community edition. The credentials are provided to
+// gitlab in the import url.
+func (r *gl) MigrateRepo(opts gitprovider.MigrateRepoOptions) (*gitprovider.Repository, error) {
+	if opts.Mirror {
+		return nil, fmt.Errorf("mirrors are not supported by gitlab")
+	}
+	importURL, err := url.Parse(opts.CloneURL)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case opts.AuthToken != "":
+		importURL.User = url.UserPassword("oauth2", opts.AuthToken)
+	case opts.AuthUserName != "":
+		importURL.User = url.UserPassword(opts.AuthUserName, opts.AuthPassword)
+	}
+	create := &createProject{
+		Name:        opts.Name,
+		Path:        opts.Name,
+		Description: opts.Description,
+		Visibility:  toVisibility(opts.Private),
+		ImportURL:   importURL.String(),
+	}
+	u, err := r.GetMyUserInfo()
+	if err != nil {
+		return nil, err
+	}
+	if opts.Owner != "" && opts.Owner != u.UserName {
+		g, err := r.getGroup(opts.Owner)
+		if err != nil {
+			return nil, err
+		}
+		create.NamespaceID = &g.ID
+	}
+	p := &project{}
+	if err := r.do(http.MethodPost, "/projects", create, p); err != nil {
+		return nil, err
+	}
+	return toRepository(p), nil
+}
+
+func (r *gl) EditRepo(owner string, name string, opts gitprovider.EditRepoOptions) (*gitprovider.Repository, error) {
+	if opts.MirrorInterval != nil {
+		return nil, fmt.Errorf("mirrors are not supported by gitlab")
+	}
+	edit := &editProject{Description: opts.Description, DefaultBranch: opts.DefaultBranch}
+	if opts.Private != nil {
+		visibility := toVisibility(*opts.Private)
+		edit.Visibility = &visibility
+	}
+	p := &project{}
+	if err := r.do(http.MethodPut, projectPath(owner, name), edit, p); err != nil {
+		return nil, err
+	}
+	return toRepository(p), nil
+}
+
+func (r *gl) DeleteRepo(owner string, name string) error {
+	return r.do(http.MethodDelete, projectPath(owner, name), nil, nil)
+}
+
+func (r *gl) GetOrg(name string) (*gitprovider.Organization, error) {
+	g, err := r.getGroup(name)
+	if err != nil {
+		return nil, err
+	}
+	return toOrganization(g), nil
+}
+
+func (r *gl) CreateOrg(opts gitprovider.CreateOrgOptions) (*gitprovider.Organization, error) {
+	g := &group{}
+	if err := r.do(http.MethodPost, "/groups", &createGroup{
+		Name:        opts.Name,
+		Path:        opts.Name,
+		Description: opts.Description,
+		Visibility:  toVisibility(opts.Private),
+	}, g); err != nil {
+		return nil, err
+	}
+	return toOrganization(g), nil
+}
+
+// AddTeamRepo shares the project with the subgroup of the team, the subgroup
+// is created when it does not exist. GitLab does not allow to change the
+// access level of a share, so a share with another access level is replaced.
+func (r *gl) AddTeamRepo(opts gitprovider.AddTeamRepoOptions) error {
+	accessLevel, ok := accessLevels[opts.Permission]
+	if !ok {
+		return fmt.Errorf("unsupported permission %s", opts.Permission)
+	}
+	team, err := r.getGroup(opts.Org + "/" + opts.Team)
+	if err != nil {
+		if !isNotFound(err) {
+			return err
+		}
+		org, err := r.getGroup(opts.Org)
+		if err != nil {
+			return err
+		}
+		team = &group{}
+		if err := r.do(http.MethodPost, "/groups", &createGroup{
+			Name:       opts.Team,
+			Path:       opts.Team,
+			Visibility: visibilityPrivate,
+			ParentID:   &org.ID,
+		}, team); err != nil {
+			return err
+		}
+	}
+	p, err := r.getProject(opts.Org, opts.Repo)
+	if err != nil {
+		return err
+	}
+	for _, shared := range p.SharedWithGroups {
+		if shared.GroupID != team.ID {
+			continue
+		}
+		if shared.GroupAccessLevel == accessLevel {
+			return nil
+		}
+		if err := r.do(http.MethodDelete, fmt.Sprintf("%s/share/%d", projectPath(opts.Org, opts.Repo), team.ID), nil, nil); err != nil {
+			return err
+		}
+	}
+	return r.do(http.MethodPost, projectPath(opts.Org, opts.Repo)+"/share", &shareProject{
+		GroupID:     team.ID,
+		GroupAccess: accessLevel,
+	}, nil)
+}
+
+// ListBranchProtections returns the protected branches of the project, push
+// is enabled when developers are allowed to push
+func (r *gl) ListBranchProtections(owner string, repo string) ([]*gitprovider.BranchProtection, error) {
+	branches, err := listAll[protectedBranch](r, projectPath(owner, repo)+"/protected_branches")
+	if err != nil {
+		return nil, err
+	}
+	bps := make([]*gitprovider.BranchProtection, 0, len(branches))
+	for _, branch := range branches {
+		bp := &gitprovider.BranchProtection{Branch: branch.Name}
+		for _, level := range branch.PushAccessLevels {
+			if level.AccessLevel != accessLevelNoAccess && level.AccessLevel <= accessLevelDeveloper {
+				bp.EnablePush = true
+			}
+		}
+		bps = append(bps, bp)
+	}
+	return bps, nil
+}
+
+// CreateBranchProtection protects the branch, push allowlists and required
+// approvals are not available in gitlab community edition
+func (r *gl) CreateBranchProtection(owner string, repo string, bp gitprovider.BranchProtection) error {
+	if len(bp.PushAllowlistUsers) > 0 || len(bp.PushAllowlistTeams) > 0 || bp.RequiredApprovals > 0 {
+		return fmt.Errorf("branch protection of %s: push allowlists and required approvals are not supported by gitlab", bp.Branch)
+	}
+	pushAccessLevel := accessLevelNoAccess
+	if bp.EnablePush {
+		pushAccessLevel = accessLevelDeveloper
+	}
+	return r.do(http.MethodPost, projectPath(owner, repo)+"/protected_branches", &protectBranch{
+		Name:             bp.Branch,
+		PushAccessLevel:  pushAccessLevel,
+		MergeAccessLevel: accessLevelDeveloper,
+	}, nil)
+}
+
+// EditBranchProtection replaces the protection of the branch, since gitlab
+// community edition cannot update a protected branch
+func (r *gl) EditBranchProtection(owner string, repo string, bp gitprovider.BranchProtection) error {
+	if err := r.DeleteBranchProtection(owner, repo, bp.Branch); err != nil {
+		return err
+	}
+	return r.CreateBranchProtection(owner, repo, bp)
+}
+
+func (r *gl) DeleteBranchProtection(owner string, repo string, branch string) error {
+	return r.do(http.MethodDelete, projectPath(owner, repo)+"/protected_branches/"+url.PathEscape(branch), nil, nil)
+}
+
+func (r *gl) ListWebhooks(owner string, repo string) ([]*gitprovider.Webhook, error) {
+	hooks, err := listAll[hook](r, projectPath(owner, repo)+"/hooks")
+	if err != nil {
+		return nil, err
+	}
+	webhooks := make([]*gitprovider.Webhook, 0, len(hooks))
+	for _, h := range hooks {
+		webhooks = append(webhooks, toWebhook(h))
+	}
+	return webhooks, nil
+}
+
+func (r *gl) CreateWebhook(owner string, repo string, webhook gitprovider.Webhook) error {
+	h, err := toHook(webhook)
+	if err != nil {
+		return err
+	}
+	return r.do(http.MethodPost, projectPath(owner, repo)+"/hooks", h, nil)
+}
+
+func (r *gl) EditWebhook(owner string, repo string, webhook gitprovider.Webhook) error {
+	h, err := toHook(webhook)
+	if err != nil {
+		return err
+	}
+	return r.do(http.MethodPut, fmt.Sprintf("%s/hooks/%d", projectPath(owner, repo), webhook.ID), h, nil)
+}
+
+func (r *gl) DeleteWebhook(owner string, repo string, id int64) error {
+	return r.do(http.MethodDelete, fmt.Sprintf("%s/hooks/%d", projectPath(owner, repo), id), nil, nil)
+}
+
+// ListCollaborators returns the direct members of the project
+func (r *gl) ListCollaborators(owner string, repo string) ([]*gitprovider.Collaborator, error) {
+	members, err := listAll[member](r, projectPath(owner, repo)+"/members")
+	if err != nil {
+		return nil, err
+	}
+	collaborators := make([]*gitprovider.Collaborator, 0, len(members))
+	for _, m := range members {
+		collaborators = append(collaborators, &gitprovider.Collaborator{
+			UserName:   m.UserName,
+			Permission: toPermission(m.AccessLevel),
+		})
+	}
+	return collaborators, nil
+}
+
+func (r *gl) SetCollaborator(owner string, repo string, collaborator gitprovider.Collaborator) error {
+	level, ok := accessLevels[collaborator.Permission]
+	if !ok {
+		return fmt.Errorf("unsupported permission %s", collaborator.Permission)
+	}
+	u, err := r.getUser(collaborator.UserName)
+	if err != nil {
+		return err
+	}
+	m := &addMember{UserID: u.ID, AccessLevel: level}
+	err = r.do(http.MethodPut, fmt.Sprintf("%s/members/%d", projectPath(owner, repo), u.ID), m, nil)
+	if isNotFound(err) {
+		return r.do(http.MethodPost, projectPath(owner, repo)+"/members", m, nil)
+	}
+	return err
+}
+
+func (r *gl) DeleteCollaborator(owner string, repo string, userName string) error {
+	u, err := r.getUser(userName)
+	if err != nil {
+		return err
+	}
+	return r.do(http.MethodDelete, fmt.Sprintf("%s/members/%d", projectPath(owner, repo), u.ID), nil, nil)
+}
+
+func (r *gl) ListDeployKeys(owner string, repo string) ([]*gitprovider.DeployKey, error) {
+	keys, err := listAll[deployKey](r, projectPath(owner, repo)+"/deploy_keys")
+	if err != nil {
+		return nil, err
+	}
+	deployKeys := make([]*gitprovider.DeployKey, 0, len(keys))
+	for _, key := range keys {
+		deployKeys = append(deployKeys, &gitprovider.DeployKey{
+			ID:       key.ID,
+			Title:    key.Title,
+			Key:      key.Key,
+			ReadOnly: !key.CanPush,
+		})
+	}
+	return deployKeys, nil
+}
+
+func (r *gl) CreateDeployKey(owner string, repo string, key gitprovider.DeployKey) error {
+	return r.do(http.MethodPost, projectPath(owner, repo)+"/deploy_keys", &deployKey{
+		Title:   key.Title,
+		Key:     key.Key,
+		CanPush: !key.ReadOnly,
+	}, nil)
+}
+
+func (r *gl) DeleteDeployKey(owner string, repo string, id int64) error {
+	return r.do(http.MethodDelete, fmt.Sprintf("%s/deploy_keys/%d", projectPath(owner, repo), id), nil, nil)
+}
+
+func (r *gl) ListAccessTokens() ([]*gitprovider.AccessToken, error) {
+	u, err := r.GetMyUserInfo()
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := listAll[personalAccessToken](r, fmt.Sprintf("/personal_access_tokens?state=active&user_id=%d", u.ID))
+	if err != nil {
+		return nil, err
+	}
+	accessTokens := make([]*gitprovider.AccessToken, 0, len(tokens))
+	for _, token := range tokens {
+		accessTokens = append(accessTokens, toAccessToken(token))
+	}
+	return accessTokens, nil
+}
+
+func (r *gl) CreateAccessToken(opts gitprovider.CreateAccessTokenOptions) (*gitprovider.AccessToken, error) {
+	u, err := r.GetMyUserInfo()
+	if err != nil {
+		return nil, err
+	}
+	create := &createPersonalAccessToken{
+		Name:   opts.Name,
+		Scopes: toScopes(opts.Scopes),
+	}
+	if opts.ExpiresAt != nil {
+		// gitlab expires tokens at the start of the day, so the token is kept
+		// valid until the end of the day it expires
+		create.ExpiresAt = opts.ExpiresAt.UTC().AddDate(0, 0, 1).Format(time.DateOnly)
+	}
+	token := &personalAccessToken{}
+	if err := r.do(http.MethodPost, fmt.Sprintf("/users/%d/personal_access_tokens", u.ID), create, token); err != nil {
+		return nil, err
+	}
+	return toAccessToken(token), nil
+}
+
+// DeleteAccessToken revokes all active tokens with the name
+func (r *gl) DeleteAccessToken(name string) error {
+	tokens, err := r.ListAccessTokens()
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		if token.Name == name {
+			if err := r.do(http.MethodDelete, fmt.Sprintf("/personal_access_tokens/%d", token.ID), nil, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *gl) getProject(owner string, name string) (*project, error) {
+	p := &project{}
+	if err := r.do(http.MethodGet, projectPath(owner, name), nil, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *gl) getGroup(fullPath string) (*group, error) {
+	g := &group{}
+	if err := r.do(http.MethodGet, "/groups/"+url.PathEscape(fullPath), nil, g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (r *gl) getUser(userName string) (*user, error) {
+	users := []*user{}
+	if err := r.do(http.MethodGet, "/users?username="+url.QueryEscape(userName), nil, &users); err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("user %s not found", userName)
+	}
+	return users[0], nil
+}
+
+// listAll returns the items of all pages of the list at the api path
+func listAll[T any](r *gl, path string) ([]*T, error) {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	items := []*T{}
+	for page := 1; ; page++ {
+		l := []*T{}
+		if err := r.do(http.MethodGet, fmt.Sprintf("%s%spage=%d&per_page=%d", path, sep, page, pageSize), nil, &l); err != nil {
+			return nil, err
+		}
+		items = append(items, l...)
+		if len(l) < pageSize {
+			return items, nil
+		}
+	}
+}
+
+// apiError is returned when the gitlab api responds with an error status
+type apiError struct {
+	method     string
+	path       string
+	statusCode int
+	message    string
+}
+
+func (r *apiError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", r.method, r.path, r.statusCode, r.message)
+}
+
+func isNotFound(err error) bool {
+	var e *apiError
+	return errors.As(err, &e) && e.statusCode == http.StatusNotFound
+}
+
+// do sends the request to the gitlab api and decodes the response into out
+// if out is not nil. Errors of the request are classified by the status of the
+// response.
+func (r *gl) do(method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, r.baseURL+apiPath+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("PRIVATE-TOKEN", r.token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return gitprovider.NewError(0, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return gitprovider.NewError(resp.StatusCode, &apiError{method: method, path: path, statusCode: resp.StatusCode, message: strings.TrimSpace(string(msg))})
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// projectPath returns the api path of the project, gitlab identifies projects
+// by their url encoded full path
+func projectPath(owner string, name string) string {
+	return "/projects/" + url.PathEscape(owner+"/"+name)
+}
+
+func toVisibility(private bool) string {
+	if private {
+		return visibilityPrivate
+	}
+	return visibilityPublic
+}
+
+func toScopes(scopes []string) []string {
+	glScopes := []string{}
+	for _, scope := range scopes {
+		if scope == gitprovider.ScopeRepo {
+			glScopes = append(glScopes, repoScopes...)
+			continue
+		}
+		glScopes = append(glScopes, scope)
+	}
+	return glScopes
+}
+
+func toRepository(p *project) *gitprovider.Repository {
+	r := &gitprovider.Repository{
+		ID:            p.ID,
+		Name:          p.Path,
+		Description:   p.Description,
+		Private:       p.Visibility != visibilityPublic,
+		DefaultBranch: p.DefaultBranch,
+		CloneURL:      p.HTTPURLToRepo,
+	}
+	if p.Namespace != nil {
+		r.Owner = p.Namespace.FullPath
+	}
+	return r
+}
+
+// toHook maps the provider neutral events to the gitlab hook events, gitlab
+// only supports active hooks with json payloads
+func toHook(webhook gitprovider.Webhook) (*hook, error) {
+	if !webhook.Active {
+		return nil, fmt.Errorf("inactive webhooks are not supported by gitlab")
+	}
+	h := &hook{URL: webhook.URL, Token: webhook.Secret}
+	for _, event := range webhook.Events {
+		switch event {
+		case "push":
+			h.PushEvents = true
+		case "pull_request":
+			h.MergeRequestsEvents = true
+		case "create":
+			h.TagPushEvents = true
+		case "issues":
+			h.IssuesEvents = true
+		case "release":
+			h.ReleasesEvents = true
+		default:
+			return nil, fmt.Errorf("webhook event %s is not supported by gitlab", event)
+		}
+	}
+	return h, nil
+}
+
+// toWebhook returns the webhook of the gitlab hook, gitlab hooks cannot be
+// deactivated
+func toWebhook(h *hook) *gitprovider.Webhook {
+	webhook := &gitprovider.Webhook{ID: h.ID, URL: h.URL, ContentType: "json", Active: true}
+	for event, enabled := range map[string]bool{
+		"push":         h.PushEvents,
+		"pull_request": h.MergeRequestsEvents,
+		"create":       h.TagPushEvents,
+		"issues":       h.IssuesEvents,
+		"release":      h.ReleasesEvents,
+	} {
+		if enabled {
+			webhook.Events = append(webhook.Events, event)
+		}
+	}
+	sort.Strings(webhook.Events)
+	return webhook
+}
+
+func toPermission(level int) gitprovider.Permission {
+	switch {
+	case level >= accessLevels[gitprovider.PermissionAdmin]:
+		return gitprovider.PermissionAdmin
+	case level >= accessLevels[gitprovider.PermissionWrite]:
+		return gitprovider.PermissionWrite
+	default:
+		return gitprovider.PermissionRead
+	}
+}
+
+func toOrganization(g *group) *gitprovider.Organization {
+	return &gitprovider.Organization{
+		ID:          g.ID,
+		Name:        g.FullPath,
+		Description: g.Description,
+	}
+}
+
+func toAccessToken(token *personalAccessToken) *gitprovider.AccessToken {
+	return &gitprovider.AccessToken{
+		ID:     token.ID,
+		Name:   token.Name,
+		Token:  token.Token,
+		Scopes: token.Scopes,
+	}
+}
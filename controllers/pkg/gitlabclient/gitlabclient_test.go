@@ -0,0 +1,451 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitlabclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
+)
+
+// gitlabServer is a minimal in memory stand-in of the gitlab api
+type gitlabServer struct {
+	m        sync.Mutex
+	token    string
+	projects map[string]*project
+	tokens   map[int64]*personalAccessToken
+	groups   map[string]*group
+}
+
+func newGitLabServer(t *testing.T) *httptest.Server {
+	s := &gitlabServer{
+		token:    "admin-token",
+		projects: map[string]*project{},
+		tokens:   map[int64]*personalAccessToken{},
+		groups:   map[string]*group{},
+	}
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func (s *gitlabServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if req.Header.Get("PRIVATE-TOKEN") != s.token {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	// projects are identified by their url encoded path, so we use the
+	// escaped path to keep the encoded slash
+	path := strings.TrimPrefix(req.URL.EscapedPath(), apiPath)
+	switch {
+	case path == "/user":
+		writeJSON(w, http.StatusOK, &user{ID: 1, UserName: "root"})
+	case path == "/projects" && req.Method == http.MethodPost:
+		in := &createProject{}
+		_ = json.NewDecoder(req.Body).Decode(in)
+		ns := "root"
+		if in.NamespaceID != nil {
+			for _, g := range s.groups {
+				if g.ID == *in.NamespaceID {
+					ns = g.FullPath
+				}
+			}
+		}
+		p := &project{
+			ID:            int64(len(s.projects) + 1),
+			Name:          in.Name,
+			Path:          in.Path,
+			Description:   in.Description,
+			Visibility:    in.Visibility,
+			DefaultBranch: "main",
+			HTTPURLToRepo: "http://gitlab/" + ns + "/" + in.Path + ".git",
+			Namespace:     &namespace{FullPath: ns},
+		}
+		s.projects[url.PathEscape(ns+"/"+in.Path)] = p
+		writeJSON(w, http.StatusCreated, p)
+	case path == "/groups" && req.Method == http.MethodPost:
+		in := &createGroup{}
+		_ = json.NewDecoder(req.Body).Decode(in)
+		g := &group{ID: int64(len(s.groups) + 10), Name: in.Name, Path: in.Path, FullPath: in.Path, Description: in.Description}
+		if in.ParentID != nil {
+			for _, parent := range s.groups {
+				if parent.ID == *in.ParentID {
+					g.FullPath = parent.FullPath + "/" + in.Path
+				}
+			}
+		}
+		s.groups[url.PathEscape(g.FullPath)] = g
+		writeJSON(w, http.StatusCreated, g)
+	case strings.HasPrefix(path, "/groups/") && req.Method == http.MethodGet:
+		g, ok := s.groups[strings.TrimPrefix(path, "/groups/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, g)
+	case strings.HasPrefix(path, "/projects/") && strings.Contains(path, "/share"):
+		key, share, _ := strings.Cut(strings.TrimPrefix(path, "/projects/"), "/share")
+		p, ok := s.projects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch req.Method {
+		case http.MethodPost:
+			in := &shareProject{}
+			_ = json.NewDecoder(req.Body).Decode(in)
+			for _, shared := range p.SharedWithGroups {
+				if shared.GroupID == in.GroupID {
+					w.WriteHeader(http.StatusConflict)
+					return
+				}
+			}
+			p.SharedWithGroups = append(p.SharedWithGroups, sharedGroup{GroupID: in.GroupID, GroupAccessLevel: in.GroupAccess})
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodDelete:
+			var id int64
+			_, _ = fmt.Sscanf(strings.TrimPrefix(share, "/"), "%d", &id)
+			shares := []sharedGroup{}
+			for _, shared := range p.SharedWithGroups {
+				if shared.GroupID != id {
+					shares = append(shares, shared)
+				}
+			}
+			p.SharedWithGroups = shares
+			w.WriteHeader(http.StatusNoContent)
+		}
+	case strings.HasPrefix(path, "/projects/"):
+		key := strings.TrimPrefix(path, "/projects/")
+		p, ok := s.projects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch req.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, p)
+		case http.MethodPut:
+			in := &editProject{}
+			_ = json.NewDecoder(req.Body).Decode(in)
+			if in.Description != nil {
+				p.Description = *in.Description
+			}
+			if in.Visibility != nil {
+				p.Visibility = *in.Visibility
+			}
+			writeJSON(w, http.StatusOK, p)
+		case http.MethodDelete:
+			delete(s.projects, key)
+			w.WriteHeader(http.StatusAccepted)
+		}
+	case path == "/personal_access_tokens" && req.Method == http.MethodGet:
+		tokens := []*personalAccessToken{}
+		for _, t := range s.tokens {
+			if t.Active {
+				tokens = append(tokens, &personalAccessToken{ID: t.ID, Name: t.Name, Scopes: t.Scopes, Active: true})
+			}
+		}
+		sort.Slice(tokens, func(i, j int) bool { return tokens[i].ID < tokens[j].ID })
+		writeJSON(w, http.StatusOK, getPage(req, tokens))
+	case path == "/users/1/personal_access_tokens" && req.Method == http.MethodPost:
+		in := &createPersonalAccessToken{}
+		_ = json.NewDecoder(req.Body).Decode(in)
+		t := &personalAccessToken{ID: int64(len(s.tokens) + 1), Name: in.Name, Scopes: in.Scopes, Token: "glpat-" + in.Name, Active: true}
+		s.tokens[t.ID] = t
+		writeJSON(w, http.StatusCreated, t)
+	case strings.HasPrefix(path, "/personal_access_tokens/") && req.Method == http.MethodDelete:
+		var id int64
+		_, _ = fmt.Sscanf(strings.TrimPrefix(path, "/personal_access_tokens/"), "%d", &id)
+		t, ok := s.tokens[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		t.Active = false
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// getPage returns the page of the items requested like gitlab does, which
+// returns 20 items per page by default
+func getPage[T any](req *http.Request, items []T) []T {
+	page, perPage := 1, 20
+	if v, err := strconv.Atoi(req.URL.Query().Get("page")); err == nil {
+		page = v
+	}
+	if v, err := strconv.Atoi(req.URL.Query().Get("per_page")); err == nil {
+		perPage = v
+	}
+	start := (page - 1) * perPage
+	if start >= len(items) {
+		return []T{}
+	}
+	return items[start:min(start+perPage, len(items))]
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestRepositories(t *testing.T) {
+	srv := newGitLabServer(t)
+	c, err := New(srv.URL, gitprovider.Credentials{UserName: "root", Token: "admin-token"})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	u, err := c.GetMyUserInfo()
+	if err != nil {
+		t.Fatalf("GetMyUserInfo() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(&gitprovider.User{ID: 1, UserName: "root"}, u); diff != "" {
+		t.Errorf("GetMyUserInfo() -want, +got:\n%s", diff)
+	}
+	if _, err := c.GetRepo(u.UserName, "mgmt"); err == nil {
+		t.Errorf("GetRepo() expected error for a repo that does not exist")
+	}
+	created, err := c.CreateRepo(gitprovider.CreateRepoOptions{Name: "mgmt", Description: "a", Private: true, AutoInit: true})
+	if err != nil {
+		t.Fatalf("CreateRepo() unexpected error: %v", err)
+	}
+	want := &gitprovider.Repository{ID: 1, Owner: "root", Name: "mgmt", Description: "a", Private: true, DefaultBranch: "main", CloneURL: "http://gitlab/root/mgmt.git"}
+	if diff := cmp.Diff(want, created); diff != "" {
+		t.Errorf("CreateRepo() -want, +got:\n%s", diff)
+	}
+	got, err := c.GetRepo(u.UserName, "mgmt")
+	if err != nil {
+		t.Fatalf("GetRepo() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetRepo() -want, +got:\n%s", diff)
+	}
+	private := false
+	edited, err := c.EditRepo(u.UserName, "mgmt", gitprovider.EditRepoOptions{Private: &private})
+	if err != nil {
+		t.Fatalf("EditRepo() unexpected error: %v", err)
+	}
+	want.Private = false
+	if diff := cmp.Diff(want, edited); diff != "" {
+		t.Errorf("EditRepo() -want, +got:\n%s", diff)
+	}
+	if err := c.DeleteRepo(u.UserName, "mgmt"); err != nil {
+		t.Fatalf("DeleteRepo() unexpected error: %v", err)
+	}
+	if _, err := c.GetRepo(u.UserName, "mgmt"); err == nil {
+		t.Errorf("GetRepo() expected error for a deleted repo")
+	}
+}
+
+func TestMigrateRepo(t *testing.T) {
+	srv := newGitLabServer(t)
+	c, err := New(srv.URL, gitprovider.Credentials{UserName: "root", Token: "admin-token"})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	if _, err := c.MigrateRepo(gitprovider.MigrateRepoOptions{Name: "catalog", CloneURL: "https://github.com/nephio-project/catalog.git", Mirror: true}); err == nil {
+		t.Errorf("MigrateRepo() expected error for a mirror")
+	}
+	imported, err := c.MigrateRepo(gitprovider.MigrateRepoOptions{
+		Owner:     "root",
+		Name:      "catalog",
+		CloneURL:  "https://github.com/nephio-project/catalog.git",
+		AuthToken: "s3cr3t",
+	})
+	if err != nil {
+		t.Fatalf("MigrateRepo() unexpected error: %v", err)
+	}
+	want := &gitprovider.Repository{ID: 1, Owner: "root", Name: "catalog", DefaultBranch: "main", CloneURL: "http://gitlab/root/catalog.git"}
+	if diff := cmp.Diff(want, imported); diff != "" {
+		t.Errorf("MigrateRepo() -want, +got:\n%s", diff)
+	}
+	interval := "1h0m0s"
+	if _, err := c.EditRepo("root", "catalog", gitprovider.EditRepoOptions{MirrorInterval: &interval}); err == nil {
+		t.Errorf("EditRepo() expected error for a mirror interval")
+	}
+}
+
+func TestAccessTokens(t *testing.T) {
+	srv := newGitLabServer(t)
+	c, err := New(srv.URL, gitprovider.Credentials{UserName: "root", Token: "admin-token"})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	token, err := c.CreateAccessToken(gitprovider.CreateAccessTokenOptions{Name: "mgmt-default", Scopes: []string{gitprovider.ScopeRepo}})
+	if err != nil {
+		t.Fatalf("CreateAccessToken() unexpected error: %v", err)
+	}
+	want := &gitprovider.AccessToken{ID: 1, Name: "mgmt-default", Token: "glpat-mgmt-default", Scopes: []string{"read_repository", "write_repository"}}
+	if diff := cmp.Diff(want, token); diff != "" {
+		t.Errorf("CreateAccessToken() -want, +got:\n%s", diff)
+	}
+	tokens, err := c.ListAccessTokens()
+	if err != nil {
+		t.Fatalf("ListAccessTokens() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(1, len(tokens)); diff != "" {
+		t.Errorf("ListAccessTokens() -want, +got:\n%s", diff)
+	}
+	if err := c.DeleteAccessToken("mgmt-default"); err != nil {
+		t.Fatalf("DeleteAccessToken() unexpected error: %v", err)
+	}
+	tokens, err = c.ListAccessTokens()
+	if err != nil {
+		t.Fatalf("ListAccessTokens() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(0, len(tokens)); diff != "" {
+		t.Errorf("ListAccessTokens() -want, +got:\n%s", diff)
+	}
+}
+
+func TestListPages(t *testing.T) {
+	srv := newGitLabServer(t)
+	c, err := New(srv.URL, gitprovider.Credentials{UserName: "root", Token: "admin-token"})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	// more tokens than fit on a page
+	for i := 0; i < pageSize+10; i++ {
+		if _, err := c.CreateAccessToken(gitprovider.CreateAccessTokenOptions{Name: fmt.Sprintf("token-%d", i)}); err != nil {
+			t.Fatalf("CreateAccessToken() unexpected error: %v", err)
+		}
+	}
+	tokens, err := c.ListAccessTokens()
+	if err != nil {
+		t.Fatalf("ListAccessTokens() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(pageSize+10, len(tokens)); diff != "" {
+		t.Errorf("ListAccessTokens() -want, +got:\n%s", diff)
+	}
+}
+
+func TestUnauthorized(t *testing.T) {
+	srv := newGitLabServer(t)
+	c, err := New(srv.URL, gitprovider.Credentials{UserName: "root", Token: "wrong"})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	_, err = c.GetMyUserInfo()
+	if diff := cmp.Diff(gitprovider.ErrorClassUnauthorized, gitprovider.ClassOf(err)); diff != "" {
+		t.Errorf("GetMyUserInfo() error class -want, +got:\n%s", diff)
+	}
+	if !strings.Contains(err.Error(), "401") {
+		t.Errorf("GetMyUserInfo() expected the status in the error, got %v", err)
+	}
+}
+
+func TestGroups(t *testing.T) {
+	srv := newGitLabServer(t)
+	c, err := New(srv.URL, gitprovider.Credentials{UserName: "root", Token: "admin-token"})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	if _, err := c.GetOrg("edge"); err == nil {
+		t.Errorf("GetOrg() expected error for a group that does not exist")
+	}
+	org, err := c.CreateOrg(gitprovider.CreateOrgOptions{Name: "edge"})
+	if err != nil {
+		t.Fatalf("CreateOrg() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(&gitprovider.Organization{ID: 10, Name: "edge"}, org); diff != "" {
+		t.Errorf("CreateOrg() -want, +got:\n%s", diff)
+	}
+	repo, err := c.CreateRepo(gitprovider.CreateRepoOptions{Owner: "edge", Name: "edge01"})
+	if err != nil {
+		t.Fatalf("CreateRepo() unexpected error: %v", err)
+	}
+	want := &gitprovider.Repository{ID: 1, Owner: "edge", Name: "edge01", DefaultBranch: "main", CloneURL: "http://gitlab/edge/edge01.git"}
+	if diff := cmp.Diff(want, repo); diff != "" {
+		t.Errorf("CreateRepo() -want, +got:\n%s", diff)
+	}
+
+	// the subgroup is created once, a changed permission replaces the share
+	for _, permission := range []gitprovider.Permission{gitprovider.PermissionWrite, gitprovider.PermissionWrite, gitprovider.PermissionAdmin} {
+		if err := c.AddTeamRepo(gitprovider.AddTeamRepoOptions{Org: "edge", Team: "ops", Repo: "edge01", Permission: permission}); err != nil {
+			t.Fatalf("AddTeamRepo() unexpected error: %v", err)
+		}
+	}
+	s := srv.Config.Handler.(*gitlabServer)
+	if diff := cmp.Diff(2, len(s.groups)); diff != "" {
+		t.Errorf("groups -want, +got:\n%s", diff)
+	}
+	wantShares := []sharedGroup{{GroupID: 11, GroupAccessLevel: 40}}
+	if diff := cmp.Diff(wantShares, s.projects["edge%2Fedge01"].SharedWithGroups); diff != "" {
+		t.Errorf("shares -want, +got:\n%s", diff)
+	}
+	if err := c.AddTeamRepo(gitprovider.AddTeamRepoOptions{Org: "edge", Team: "ops", Repo: "edge01", Permission: "owner"}); err == nil {
+		t.Errorf("AddTeamRepo() expected error for an unsupported permission")
+	}
+}
+
+func TestToHook(t *testing.T) {
+	cases := map[string]struct {
+		webhook gitprovider.Webhook
+		want    *hook
+		wantErr bool
+	}{
+		"Events": {
+			webhook: gitprovider.Webhook{URL: "http://porch", Secret: "s", Events: []string{"push", "pull_request", "create"}, Active: true},
+			want:    &hook{URL: "http://porch", Token: "s", PushEvents: true, MergeRequestsEvents: true, TagPushEvents: true},
+		},
+		"UnsupportedEvent": {
+			webhook: gitprovider.Webhook{URL: "http://porch", Events: []string{"wiki"}, Active: true},
+			wantErr: true,
+		},
+		"Inactive": {
+			webhook: gitprovider.Webhook{URL: "http://porch", Events: []string{"push"}},
+			wantErr: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := toHook(tc.webhook)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("toHook() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("toHook() -want, +got:\n%s", diff)
+			}
+			if tc.want == nil {
+				return
+			}
+			// the webhook read back from gitlab has the same events
+			if diff := cmp.Diff([]string{"create", "pull_request", "push"}, toWebhook(got).Events); diff != "" {
+				t.Errorf("toWebhook() -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
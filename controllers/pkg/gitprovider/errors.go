@@ -0,0 +1,109 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitprovider
+
+import (
+	"errors"
+	"net"
+	"net/http"
+)
+
+// ErrorClass classifies the errors returned by the git server, so callers can
+// report and retry them according to their cause
+type ErrorClass string
+
+const (
+	// ErrorClassUnauthorized is returned when the credentials are invalid or
+	// miss the permissions for the request
+	ErrorClassUnauthorized ErrorClass = "Unauthorized"
+	ErrorClassNotFound     ErrorClass = "NotFound"
+	ErrorClassConflict     ErrorClass = "Conflict"
+	ErrorClassRateLimited  ErrorClass = "RateLimited"
+	// ErrorClassUnavailable is returned when the git server cannot be reached
+	// or fails to handle the request
+	ErrorClassUnavailable ErrorClass = "Unavailable"
+	ErrorClassUnknown     ErrorClass = "Unknown"
+)
+
+// Error is an error returned by the git server with its class
+type Error struct {
+	Class ErrorClass
+	// StatusCode is the http status of the response, 0 when no response was
+	// received
+	StatusCode int
+	Err        error
+}
+
+func (r *Error) Error() string {
+	return r.Err.Error()
+}
+
+func (r *Error) Unwrap() error {
+	return r.Err
+}
+
+// NewError classifies the error of a request to the git server by the http
+// status of its response, 0 when no response was received. Errors that are
+// already classified are returned as is.
+func NewError(statusCode int, err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+	return &Error{Class: classify(statusCode, err), StatusCode: statusCode, Err: err}
+}
+
+// ClassOf returns the class of the error, empty when err is nil
+func ClassOf(err error) ErrorClass {
+	if err == nil {
+		return ""
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Class
+	}
+	return classify(0, err)
+}
+
+// IsNotFound returns true when the git server reported the resource as not
+// found
+func IsNotFound(err error) bool {
+	return ClassOf(err) == ErrorClassNotFound
+}
+
+func classify(statusCode int, err error) ErrorClass {
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return ErrorClassUnauthorized
+	case statusCode == http.StatusNotFound:
+		return ErrorClassNotFound
+	case statusCode == http.StatusConflict:
+		return ErrorClassConflict
+	case statusCode == http.StatusTooManyRequests:
+		return ErrorClassRateLimited
+	case statusCode >= http.StatusInternalServerError:
+		return ErrorClassUnavailable
+	}
+	var netErr net.Error
+	if statusCode == 0 && errors.As(err, &netErr) {
+		return ErrorClassUnavailable
+	}
+	return ErrorClassUnknown
+}
@@ -0,0 +1,311 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitprovider
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Kind identifies the implementation of a git provider
+type Kind string
+
+const (
+	KindGitea  Kind = "gitea"
+	KindGitLab Kind = "gitlab"
+)
+
+const (
+	// ScopeRepo is the provider neutral scope of an access token that allows
+	// reading and writing repositories; each provider maps it to its own scopes
+	ScopeRepo = "repo"
+
+	defaultSecretName = "git-user-secret"
+)
+
+// GitProvider is the provider neutral interface to a git server used by the
+// Repository and Token reconcilers
+type GitProvider interface {
+	IsInitialized() bool
+	GetMyUserInfo() (*User, error)
+	GetRepo(owner string, name string) (*Repository, error)
+	CreateRepo(opts CreateRepoOptions) (*Repository, error)
+	// MigrateRepo creates a repository as a copy or a mirror of an upstream
+	// git repository
+	MigrateRepo(opts MigrateRepoOptions) (*Repository, error)
+	EditRepo(owner string, name string, opts EditRepoOptions) (*Repository, error)
+	DeleteRepo(owner string, name string) error
+	GetOrg(name string) (*Organization, error)
+	CreateOrg(opts CreateOrgOptions) (*Organization, error)
+	// AddTeamRepo grants a team of the organization access to the repository,
+	// the team is created when it does not exist
+	AddTeamRepo(opts AddTeamRepoOptions) error
+	ListBranchProtections(owner string, repo string) ([]*BranchProtection, error)
+	CreateBranchProtection(owner string, repo string, bp BranchProtection) error
+	EditBranchProtection(owner string, repo string, bp BranchProtection) error
+	DeleteBranchProtection(owner string, repo string, branch string) error
+	ListWebhooks(owner string, repo string) ([]*Webhook, error)
+	CreateWebhook(owner string, repo string, hook Webhook) error
+	EditWebhook(owner string, repo string, hook Webhook) error
+	DeleteWebhook(owner string, repo string, id int64) error
+	ListCollaborators(owner string, repo string) ([]*Collaborator, error)
+	// SetCollaborator adds the collaborator to the repository or updates the
+	// permission of an existing collaborator
+	SetCollaborator(owner string, repo string, collaborator Collaborator) error
+	DeleteCollaborator(owner string, repo string, userName string) error
+	ListDeployKeys(owner string, repo string) ([]*DeployKey, error)
+	CreateDeployKey(owner string, repo string, key DeployKey) error
+	DeleteDeployKey(owner string, repo string, id int64) error
+	ListAccessTokens() ([]*AccessToken, error)
+	CreateAccessToken(opts CreateAccessTokenOptions) (*AccessToken, error)
+	DeleteAccessToken(name string) error
+}
+
+// Permission is the access level of a team to a repository
+type Permission string
+
+const (
+	PermissionRead  Permission = "read"
+	PermissionWrite Permission = "write"
+	PermissionAdmin Permission = "admin"
+)
+
+// User is a user of the git server
+type User struct {
+	ID       int64
+	UserName string
+}
+
+// Repository is a repository on the git server
+type Repository struct {
+	ID            int64
+	Owner         string
+	Name          string
+	Description   string
+	Private       bool
+	DefaultBranch string
+	CloneURL      string
+	// Mirror is true if the repository is a mirror of an upstream repository
+	Mirror bool
+	// MirrorInterval is the interval at which a mirror is synced
+	MirrorInterval string
+}
+
+// CreateRepoOptions are the options to create a repository; options that are
+// not supported by a provider are ignored
+type CreateRepoOptions struct {
+	// Owner is the organization owning the repository, if empty the
+	// repository is created for the authenticated user
+	Owner         string
+	Name          string
+	Description   string
+	Private       bool
+	IssueLabels   string
+	Gitignores    string
+	License       string
+	Readme        string
+	DefaultBranch string
+	TrustModel    string
+	AutoInit      bool
+}
+
+// MigrateRepoOptions are the options to create a repository from an upstream
+// git repository
+type MigrateRepoOptions struct {
+	// Owner is the user or the organization owning the repository
+	Owner       string
+	Name        string
+	Description string
+	Private     bool
+	// CloneURL is the url of the upstream repository
+	CloneURL string
+	// Credentials to authenticate to the upstream repository, optional
+	AuthUserName string
+	AuthPassword string
+	AuthToken    string
+	// Mirror keeps the repository in sync with the upstream repository,
+	// otherwise the upstream repository is imported once
+	Mirror bool
+	// MirrorInterval is the interval at which a mirror is synced, e.g. 8h0m0s
+	MirrorInterval string
+}
+
+// EditRepoOptions are the options to update a repository, nil fields are not
+// changed
+type EditRepoOptions struct {
+	Description    *string
+	Private        *bool
+	DefaultBranch  *string
+	MirrorInterval *string
+}
+
+// Organization is an organization on the git server, which maps to a group
+// on providers that have no organizations
+type Organization struct {
+	ID          int64
+	Name        string
+	Description string
+}
+
+// CreateOrgOptions are the options to create an organization
+type CreateOrgOptions struct {
+	Name        string
+	Description string
+	Private     bool
+}
+
+// AddTeamRepoOptions are the options to grant a team access to a repository
+// of the organization
+type AddTeamRepoOptions struct {
+	Org        string
+	Team       string
+	Repo       string
+	Permission Permission
+}
+
+// BranchProtection is a protection rule of a branch of a repository
+type BranchProtection struct {
+	Branch string
+	// EnablePush allows pushing to the branch, when PushAllowlistUsers or
+	// PushAllowlistTeams are set only they can push
+	EnablePush         bool
+	PushAllowlistUsers []string
+	PushAllowlistTeams []string
+	// RequiredApprovals is the number of approvals required to merge a pull
+	// request to the branch
+	RequiredApprovals int64
+}
+
+// Webhook is a webhook of a repository
+type Webhook struct {
+	ID  int64
+	URL string
+	// ContentType is json or form
+	ContentType string
+	// Secret is used to sign the payload, it is never returned by the server
+	Secret string
+	// Events are the events triggering the webhook, e.g. push, pull_request,
+	// create, delete or release
+	Events []string
+	Active bool
+}
+
+// Collaborator is a user with access to a repository
+type Collaborator struct {
+	UserName   string
+	Permission Permission
+}
+
+// DeployKey is an ssh key with access to a repository
+type DeployKey struct {
+	ID       int64
+	Title    string
+	Key      string
+	ReadOnly bool
+}
+
+// AccessToken is an access token of the authenticated user, the Token field
+// is only set when the token is created
+type AccessToken struct {
+	ID     int64
+	Name   string
+	Token  string
+	Scopes []string
+}
+
+// CreateAccessTokenOptions are the options to create an access token
+type CreateAccessTokenOptions struct {
+	Name   string
+	Scopes []string
+	// ExpiresAt is the time the token expires, the token does not expire when
+	// not set. Providers without token expiry ignore it.
+	ExpiresAt *time.Time
+}
+
+// Config is the configuration of the git server, which is provided through
+// environment variables
+type Config struct {
+	Kind Kind
+	// URL of the git server, the url key of the credentials secret overrides
+	// it so the endpoint can be changed without restarting the controller
+	URL string
+	// Secret is the key of the secret holding the credentials of the git server
+	Secret types.NamespacedName
+}
+
+// GetConfig returns the git server configuration from the environment
+// GIT_PROVIDER selects the provider, default gitea
+// GIT_URL provides the url of the git server
+// GIT_NAMESPACE and GIT_SECRET_NAME provide the credentials secret
+func GetConfig() Config {
+	cfg := Config{
+		Kind: KindGitea,
+		URL:  os.Getenv("GIT_URL"),
+		Secret: types.NamespacedName{
+			Namespace: os.Getenv("POD_NAMESPACE"),
+			Name:      defaultSecretName,
+		},
+	}
+	if kind, ok := os.LookupEnv("GIT_PROVIDER"); ok && kind != "" {
+		cfg.Kind = Kind(kind)
+	}
+	if gitNamespace, ok := os.LookupEnv("GIT_NAMESPACE"); ok {
+		cfg.Secret.Namespace = gitNamespace
+	}
+	if gitSecretName, ok := os.LookupEnv("GIT_SECRET_NAME"); ok {
+		cfg.Secret.Name = gitSecretName
+	}
+	return cfg
+}
+
+// GetURL returns the url of the git server, the url in the secret takes
+// precedence over the configured url
+func (r Config) GetURL(secret *corev1.Secret) (string, error) {
+	if url := string(secret.Data["url"]); url != "" {
+		return url, nil
+	}
+	if r.URL == "" {
+		return "", fmt.Errorf("git url not defined")
+	}
+	return r.URL, nil
+}
+
+// Credentials are the credentials to authenticate to the git server
+type Credentials struct {
+	UserName string
+	Password string
+	// Token is used by providers that authenticate with a token instead of a
+	// password, if empty the password is used as token
+	Token string
+}
+
+// GetCredentials returns the credentials stored in the secret
+func GetCredentials(secret *corev1.Secret) Credentials {
+	creds := Credentials{
+		UserName: string(secret.Data["username"]),
+		Password: string(secret.Data["password"]),
+		Token:    string(secret.Data["token"]),
+	}
+	if creds.Token == "" {
+		creds.Token = creds.Password
+	}
+	return creds
+}
@@ -0,0 +1,1455 @@
+// Code generated by mockery v2.41.0. DO NOT EDIT.
+
+package gitprovider
+
+import mock "github.com/stretchr/testify/mock"
+
+// MockGitProvider is an autogenerated mock type for the GitProvider type
+type MockGitProvider struct {
+	mock.Mock
+}
+
+type MockGitProvider_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockGitProvider) EXPECT() *MockGitProvider_Expecter {
+	return &MockGitProvider_Expecter{mock: &_m.Mock}
+}
+
+// AddTeamRepo provides a mock function with given fields: opts
+func (_m *MockGitProvider) AddTeamRepo(opts AddTeamRepoOptions) error {
+	ret := _m.Called(opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddTeamRepo")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(AddTeamRepoOptions) error); ok {
+		r0 = rf(opts)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockGitProvider_AddTeamRepo_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddTeamRepo'
+type MockGitProvider_AddTeamRepo_Call struct {
+	*mock.Call
+}
+
+// AddTeamRepo is a helper method to define mock.On call
+//   - opts AddTeamRepoOptions
+func (_e *MockGitProvider_Expecter) AddTeamRepo(opts interface{}) *MockGitProvider_AddTeamRepo_Call {
+	return &MockGitProvider_AddTeamRepo_Call{Call: _e.mock.On("AddTeamRepo", opts)}
+}
+
+func (_c *MockGitProvider_AddTeamRepo_Call) Run(run func(opts AddTeamRepoOptions)) *MockGitProvider_AddTeamRepo_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(AddTeamRepoOptions))
+	})
+	return _c
+}
+
+func (_c *MockGitProvider_AddTeamRepo_Call) Return(_a0 error) *MockGitProvider_AddTeamRepo_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockGitProvider_AddTeamRepo_Call) RunAndReturn(run func(AddTeamRepoOptions) error) *MockGitProvider_AddTeamRepo_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateAccessToken provides a mock function with given fields: opts
+func (_m *MockGitProvider) CreateAccessToken(opts CreateAccessTokenOptions) (*AccessToken, error) {
+	ret := _m.Called(opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAccessToken")
+	}
+
+	var r0 *AccessToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(CreateAccessTokenOptions) (*AccessToken, error)); ok {
+		return rf(opts)
+	}
+	if rf, ok := ret.Get(0).(func(CreateAccessTokenOptions) *AccessToken); ok {
+		r0 = rf(opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*AccessToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(CreateAccessTokenOptions) error); ok {
+		r1 = rf(opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockGitProvider_CreateAccessToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateAccessToken'
+type MockGitProvider_CreateAccessToken_Call struct {
+	*mock.Call
+}
+
+// CreateAccessToken is a helper method to define mock.On call
+//   - opts CreateAccessTokenOptions
+func (_e *MockGitProvider_Expecter) CreateAccessToken(opts interface{}) *MockGitProvider_CreateAccessToken_Call {
+	return &MockGitProvider_CreateAccessToken_Call{Call: _e.mock.On("CreateAccessToken", opts)}
+}
+
+func (_c *MockGitProvider_CreateAccessToken_Call) Run(run func(opts CreateAccessTokenOptions)) *MockGitProvider_CreateAccessToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(CreateAccessTokenOptions))
+	})
+	return _c
+}
+
+func (_c *MockGitProvider_CreateAccessToken_Call) Return(_a0 *AccessToken, _a1 error) *MockGitProvider_CreateAccessToken_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockGitProvider_CreateAccessToken_Call) RunAndReturn(run func(CreateAccessTokenOptions) (*AccessToken, error)) *MockGitProvider_CreateAccessToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateBranchProtection provides a mock function with given fields: owner, repo, bp
+func (_m *MockGitProvider) CreateBranchProtection(owner string, repo string, bp BranchProtection) error {
+	ret := _m.Called(owner, repo, bp)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateBranchProtection")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, BranchProtection) error); ok {
+		r0 = rf(owner, repo, bp)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockGitProvider_CreateBranchProtection_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateBranchProtection'
+type MockGitProvider_CreateBranchProtection_Call struct {
+	*mock.Call
+}
+
+// CreateBranchProtection is a helper method to define mock.On call
+//   - owner string
+//   - repo string
+//   - bp BranchProtection
+func (_e *MockGitProvider_Expecter) CreateBranchProtection(owner interface{}, repo interface{}, bp interface{}) *MockGitProvider_CreateBranchProtection_Call {
+	return &MockGitProvider_CreateBranchProtection_Call{Call: _e.mock.On("CreateBranchProtection", owner, repo, bp)}
+}
+
+func (_c *MockGitProvider_CreateBranchProtection_Call) Run(run func(owner string, repo string, bp BranchProtection)) *MockGitProvider_CreateBranchProtection_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(BranchProtection))
+	})
+	return _c
+}
+
+func (_c *MockGitProvider_CreateBranchProtection_Call) Return(_a0 error) *MockGitProvider_CreateBranchProtection_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockGitProvider_CreateBranchProtection_Call) RunAndReturn(run func(string, string, BranchProtection) error) *MockGitProvider_CreateBranchProtection_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateDeployKey provides a mock function with given fields: owner, repo, key
+func (_m *MockGitProvider) CreateDeployKey(owner string, repo string, key DeployKey) error {
+	ret := _m.Called(owner, repo, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateDeployKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, DeployKey) error); ok {
+		r0 = rf(owner, repo, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockGitProvider_CreateDeployKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateDeployKey'
+type MockGitProvider_CreateDeployKey_Call struct {
+	*mock.Call
+}
+
+// CreateDeployKey is a helper method to define mock.On call
+//   - owner string
+//   - repo string
+//   - key DeployKey
+func (_e *MockGitProvider_Expecter) CreateDeployKey(owner interface{}, repo interface{}, key interface{}) *MockGitProvider_CreateDeployKey_Call {
+	return &MockGitProvider_CreateDeployKey_Call{Call: _e.mock.On("CreateDeployKey", owner, repo, key)}
+}
+
+func (_c *MockGitProvider_CreateDeployKey_Call) Run(run func(owner string, repo string, key DeployKey)) *MockGitProvider_CreateDeployKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(DeployKey))
+	})
+	return _c
+}
+
+func (_c *MockGitProvider_CreateDeployKey_Call) Return(_a0 error) *MockGitProvider_CreateDeployKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockGitProvider_CreateDeployKey_Call) RunAndReturn(run func(string, string, DeployKey) error) *MockGitProvider_CreateDeployKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateOrg provides a mock function with given fields: opts
+func (_m *MockGitProvider) CreateOrg(opts CreateOrgOptions) (*Organization, error) {
+	ret := _m.Called(opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateOrg")
+	}
+
+	var r0 *Organization
+	var r1 error
+	if rf, ok := ret.Get(0).(func(CreateOrgOptions) (*Organization, error)); ok {
+		return rf(opts)
+	}
+	if rf, ok := ret.Get(0).(func(CreateOrgOptions) *Organization); ok {
+		r0 = rf(opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Organization)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(CreateOrgOptions) error); ok {
+		r1 = rf(opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockGitProvider_CreateOrg_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateOrg'
+type MockGitProvider_CreateOrg_Call struct {
+	*mock.Call
+}
+
+// CreateOrg is a helper method to define mock.On call
+//   - opts CreateOrgOptions
+func (_e *MockGitProvider_Expecter) CreateOrg(opts interface{}) *MockGitProvider_CreateOrg_Call {
+	return &MockGitProvider_CreateOrg_Call{Call: _e.mock.On("CreateOrg", opts)}
+}
+
+func (_c *MockGitProvider_CreateOrg_Call) Run(run func(opts CreateOrgOptions)) *MockGitProvider_CreateOrg_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(CreateOrgOptions))
+	})
+	return _c
+}
+
+func (_c *MockGitProvider_CreateOrg_Call) Return(_a0 *Organization, _a1 error) *MockGitProvider_CreateOrg_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockGitProvider_CreateOrg_Call) RunAndReturn(run func(CreateOrgOptions) (*Organization, error)) *MockGitProvider_CreateOrg_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateRepo provides a mock function with given fields: opts
+func (_m *MockGitProvider) CreateRepo(opts CreateRepoOptions) (*Repository, error) {
+	ret := _m.Called(opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateRepo")
+	}
+
+	var r0 *Repository
+	var r1 error
+	if rf, ok := ret.Get(0).(func(CreateRepoOptions) (*Repository, error)); ok {
+		return rf(opts)
+	}
+	if rf, ok := ret.Get(0).(func(CreateRepoOptions) *Repository); ok {
+		r0 = rf(opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Repository)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(CreateRepoOptions) error); ok {
+		r1 = rf(opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockGitProvider_CreateRepo_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateRepo'
+type MockGitProvider_CreateRepo_Call struct {
+	*mock.Call
+}
+
+// CreateRepo is a helper method to define mock.On call
+//   - opts CreateRepoOptions
+func (_e *MockGitProvider_Expecter) CreateRepo(opts interface{}) *MockGitProvider_CreateRepo_Call {
+	return &MockGitProvider_CreateRepo_Call{Call: _e.mock.On("CreateRepo", opts)}
+}
+
+func (_c *MockGitProvider_CreateRepo_Call) Run(run func(opts CreateRepoOptions)) *MockGitProvider_CreateRepo_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(CreateRepoOptions))
+	})
+	return _c
+}
+
+func (_c *MockGitProvider_CreateRepo_Call) Return(_a0 *Repository, _a1 error) *MockGitProvider_CreateRepo_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockGitProvider_CreateRepo_Call) RunAndReturn(run func(CreateRepoOptions) (*Repository, error)) *MockGitProvider_CreateRepo_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateWebhook provides a mock function with given fields: owner, repo, hook
+func (_m *MockGitProvider) CreateWebhook(owner string, repo string, hook Webhook) error {
+	ret := _m.Called(owner, repo, hook)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateWebhook")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, Webhook) error); ok {
+		r0 = rf(owner, repo, hook)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockGitProvider_CreateWebhook_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateWebhook'
+type MockGitProvider_CreateWebhook_Call struct {
+	*mock.Call
+}
+
+// CreateWebhook is a helper method to define mock.On call
+//   - owner string
+//   - repo string
+//   - hook Webhook
+func (_e *MockGitProvider_Expecter) CreateWebhook(owner interface{}, repo interface{}, hook interface{}) *MockGitProvider_CreateWebhook_Call {
+	return &MockGitProvider_CreateWebhook_Call{Call: _e.mock.On("CreateWebhook", owner, repo, hook)}
+}
+
+func (_c *MockGitProvider_CreateWebhook_Call) Run(run func(owner string, repo string, hook Webhook)) *MockGitProvider_CreateWebhook_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(Webhook))
+	})
+	return _c
+}
+
+func (_c *MockGitProvider_CreateWebhook_Call) Return(_a0 error) *MockGitProvider_CreateWebhook_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockGitProvider_CreateWebhook_Call) RunAndReturn(run func(string, string, Webhook) error) *MockGitProvider_CreateWebhook_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteAccessToken provides a mock function with given fields: name
+func (_m *MockGitProvider) DeleteAccessToken(name string) error {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteAccessToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockGitProvider_DeleteAccessToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteAccessToken'
+type MockGitProvider_DeleteAccessToken_Call struct {
+	*mock.Call
+}
+
+// DeleteAccessToken is a helper method to define mock.On call
+//   - name string
+func (_e *MockGitProvider_Expecter) DeleteAccessToken(name interface{}) *MockGitProvider_DeleteAccessToken_Call {
+	return &MockGitProvider_DeleteAccessToken_Call{Call: _e.mock.On("DeleteAccessToken", name)}
+}
+
+func (_c *MockGitProvider_DeleteAccessToken_Call) Run(run func(name string)) *MockGitProvider_DeleteAccessToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockGitProvider_DeleteAccessToken_Call) Return(_a0 error) *MockGitProvider_DeleteAccessToken_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockGitProvider_DeleteAccessToken_Call) RunAndReturn(run func(string) error) *MockGitProvider_DeleteAccessToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteBranchProtection provides a mock function with given fields: owner, repo, branch
+func (_m *MockGitProvider) DeleteBranchProtection(owner string, repo string, branch string) error {
+	ret := _m.Called(owner, repo, branch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteBranchProtection")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(owner, repo, branch)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockGitProvider_DeleteBranchProtection_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteBranchProtection'
+type MockGitProvider_DeleteBranchProtection_Call struct {
+	*mock.Call
+}
+
+// DeleteBranchProtection is a helper method to define mock.On call
+//   - owner string
+//   - repo string
+//   - branch string
+func (_e *MockGitProvider_Expecter) DeleteBranchProtection(owner interface{}, repo interface{}, branch interface{}) *MockGitProvider_DeleteBranchProtection_Call {
+	return &MockGitProvider_DeleteBranchProtection_Call{Call: _e.mock.On("DeleteBranchProtection", owner, repo, branch)}
+}
+
+func (_c *MockGitProvider_DeleteBranchProtection_Call) Run(run func(owner string, repo string, branch string)) *MockGitProvider_DeleteBranchProtection_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockGitProvider_DeleteBranchProtection_Call) Return(_a0 error) *MockGitProvider_DeleteBranchProtection_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockGitProvider_DeleteBranchProtection_Call) RunAndReturn(run func(string, string, string) error) *MockGitProvider_DeleteBranchProtection_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteCollaborator provides a mock function with given fields: owner, repo, userName
+func (_m *MockGitProvider) DeleteCollaborator(owner string, repo string, userName string) error {
+	ret := _m.Called(owner, repo, userName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteCollaborator")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(owner, repo, userName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockGitProvider_DeleteCollaborator_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteCollaborator'
+type MockGitProvider_DeleteCollaborator_Call struct {
+	*mock.Call
+}
+
+// DeleteCollaborator is a helper method to define mock.On call
+//   - owner string
+//   - repo string
+//   - userName string
+func (_e *MockGitProvider_Expecter) DeleteCollaborator(owner interface{}, repo interface{}, userName interface{}) *MockGitProvider_DeleteCollaborator_Call {
+	return &MockGitProvider_DeleteCollaborator_Call{Call: _e.mock.On("DeleteCollaborator", owner, repo, userName)}
+}
+
+func (_c *MockGitProvider_DeleteCollaborator_Call) Run(run func(owner string, repo string, userName string)) *MockGitProvider_DeleteCollaborator_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockGitProvider_DeleteCollaborator_Call) Return(_a0 error) *MockGitProvider_DeleteCollaborator_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockGitProvider_DeleteCollaborator_Call) RunAndReturn(run func(string, string, string) error) *MockGitProvider_DeleteCollaborator_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteDeployKey provides a mock function with given fields: owner, repo, id
+func (_m *MockGitProvider) DeleteDeployKey(owner string, repo string, id int64) error {
+	ret := _m.Called(owner, repo, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteDeployKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, int64) error); ok {
+		r0 = rf(owner, repo, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockGitProvider_DeleteDeployKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteDeployKey'
+type MockGitProvider_DeleteDeployKey_Call struct {
+	*mock.Call
+}
+
+// DeleteDeployKey is a helper method to define mock.On call
+//   - owner string
+//   - repo string
+//   - id int64
+func (_e *MockGitProvider_Expecter) DeleteDeployKey(owner interface{}, repo interface{}, id interface{}) *MockGitProvider_DeleteDeployKey_Call {
+	return &MockGitProvider_DeleteDeployKey_Call{Call: _e.mock.On("DeleteDeployKey", owner, repo, id)}
+}
+
+func (_c *MockGitProvider_DeleteDeployKey_Call) Run(run func(owner string, repo string, id int64)) *MockGitProvider_DeleteDeployKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockGitProvider_DeleteDeployKey_Call) Return(_a0 error) *MockGitProvider_DeleteDeployKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockGitProvider_DeleteDeployKey_Call) RunAndReturn(run func(string, string, int64) error) *MockGitProvider_DeleteDeployKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteRepo provides a mock function with given fields: owner, name
+func (_m *MockGitProvider) DeleteRepo(owner string, name string) error {
+	ret := _m.Called(owner, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteRepo")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(owner, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockGitProvider_DeleteRepo_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteRepo'
+type MockGitProvider_DeleteRepo_Call struct {
+	*mock.Call
+}
+
+// DeleteRepo is a helper method to define mock.On call
+//   - owner string
+//   - name string
+func (_e *MockGitProvider_Expecter) DeleteRepo(owner interface{}, name interface{}) *MockGitProvider_DeleteRepo_Call {
+	return &MockGitProvider_DeleteRepo_Call{Call: _e.mock.On("DeleteRepo", owner, name)}
+}
+
+func (_c *MockGitProvider_DeleteRepo_Call) Run(run func(owner string, name string)) *MockGitProvider_DeleteRepo_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockGitProvider_DeleteRepo_Call) Return(_a0 error) *MockGitProvider_DeleteRepo_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockGitProvider_DeleteRepo_Call) RunAndReturn(run func(string, string) error) *MockGitProvider_DeleteRepo_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteWebhook provides a mock function with given fields: owner, repo, id
+func (_m *MockGitProvider) DeleteWebhook(owner string, repo string, id int64) error {
+	ret := _m.Called(owner, repo, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteWebhook")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, int64) error); ok {
+		r0 = rf(owner, repo, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockGitProvider_DeleteWebhook_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteWebhook'
+type MockGitProvider_DeleteWebhook_Call struct {
+	*mock.Call
+}
+
+// DeleteWebhook is a helper method to define mock.On call
+//   - owner string
+//   - repo string
+//   - id int64
+func (_e *MockGitProvider_Expecter) DeleteWebhook(owner interface{}, repo interface{}, id interface{}) *MockGitProvider_DeleteWebhook_Call {
+	return &MockGitProvider_DeleteWebhook_Call{Call: _e.mock.On("DeleteWebhook", owner, repo, id)}
+}
+
+func (_c *MockGitProvider_DeleteWebhook_Call) Run(run func(owner string, repo string, id int64)) *MockGitProvider_DeleteWebhook_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockGitProvider_DeleteWebhook_Call) Return(_a0 error) *MockGitProvider_DeleteWebhook_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockGitProvider_DeleteWebhook_Call) RunAndReturn(run func(string, string, int64) error) *MockGitProvider_DeleteWebhook_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// EditBranchProtection provides a mock function with given fields: owner, repo, bp
+func (_m *MockGitProvider) EditBranchProtection(owner string, repo string, bp BranchProtection) error {
+	ret := _m.Called(owner, repo, bp)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EditBranchProtection")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, BranchProtection) error); ok {
+		r0 = rf(owner, repo, bp)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockGitProvider_EditBranchProtection_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EditBranchProtection'
+type MockGitProvider_EditBranchProtection_Call struct {
+	*mock.Call
+}
+
+// EditBranchProtection is a helper method to define mock.On call
+//   - owner string
+//   - repo string
+//   - bp BranchProtection
+func (_e *MockGitProvider_Expecter) EditBranchProtection(owner interface{}, repo interface{}, bp interface{}) *MockGitProvider_EditBranchProtection_Call {
+	return &MockGitProvider_EditBranchProtection_Call{Call: _e.mock.On("EditBranchProtection", owner, repo, bp)}
+}
+
+func (_c *MockGitProvider_EditBranchProtection_Call) Run(run func(owner string, repo string, bp BranchProtection)) *MockGitProvider_EditBranchProtection_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(BranchProtection))
+	})
+	return _c
+}
+
+func (_c *MockGitProvider_EditBranchProtection_Call) Return(_a0 error) *MockGitProvider_EditBranchProtection_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockGitProvider_EditBranchProtection_Call) RunAndReturn(run func(string, string, BranchProtection) error) *MockGitProvider_EditBranchProtection_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// EditRepo provides a mock function with given fields: owner, name, opts
+func (_m *MockGitProvider) EditRepo(owner string, name string, opts EditRepoOptions) (*Repository, error) {
+	ret := _m.Called(owner, name, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EditRepo")
+	}
+
+	var r0 *Repository
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, EditRepoOptions) (*Repository, error)); ok {
+		return rf(owner, name, opts)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, EditRepoOptions) *Repository); ok {
+		r0 = rf(owner, name, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Repository)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, EditRepoOptions) error); ok {
+		r1 = rf(owner, name, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockGitProvider_EditRepo_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EditRepo'
+type MockGitProvider_EditRepo_Call struct {
+	*mock.Call
+}
+
+// EditRepo is a helper method to define mock.On call
+//   - owner string
+//   - name string
+//   - opts EditRepoOptions
+func (_e *MockGitProvider_Expecter) EditRepo(owner interface{}, name interface{}, opts interface{}) *MockGitProvider_EditRepo_Call {
+	return &MockGitProvider_EditRepo_Call{Call: _e.mock.On("EditRepo", owner, name, opts)}
+}
+
+func (_c *MockGitProvider_EditRepo_Call) Run(run func(owner string, name string, opts EditRepoOptions)) *MockGitProvider_EditRepo_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(EditRepoOptions))
+	})
+	return _c
+}
+
+func (_c *MockGitProvider_EditRepo_Call) Return(_a0 *Repository, _a1 error) *MockGitProvider_EditRepo_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockGitProvider_EditRepo_Call) RunAndReturn(run func(string, string, EditRepoOptions) (*Repository, error)) *MockGitProvider_EditRepo_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// EditWebhook provides a mock function with given fields: owner, repo, hook
+func (_m *MockGitProvider) EditWebhook(owner string, repo string, hook Webhook) error {
+	ret := _m.Called(owner, repo, hook)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EditWebhook")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, Webhook) error); ok {
+		r0 = rf(owner, repo, hook)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockGitProvider_EditWebhook_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EditWebhook'
+type MockGitProvider_EditWebhook_Call struct {
+	*mock.Call
+}
+
+// EditWebhook is a helper method to define mock.On call
+//   - owner string
+//   - repo string
+//   - hook Webhook
+func (_e *MockGitProvider_Expecter) EditWebhook(owner interface{}, repo interface{}, hook interface{}) *MockGitProvider_EditWebhook_Call {
+	return &MockGitProvider_EditWebhook_Call{Call: _e.mock.On("EditWebhook", owner, repo, hook)}
+}
+
+func (_c *MockGitProvider_EditWebhook_Call) Run(run func(owner string, repo string, hook Webhook)) *MockGitProvider_EditWebhook_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(Webhook))
+	})
+	return _c
+}
+
+func (_c *MockGitProvider_EditWebhook_Call) Return(_a0 error) *MockGitProvider_EditWebhook_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockGitProvider_EditWebhook_Call) RunAndReturn(run func(string, string, Webhook) error) *MockGitProvider_EditWebhook_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetMyUserInfo provides a mock function with given fields:
+func (_m *MockGitProvider) GetMyUserInfo() (*User, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMyUserInfo")
+	}
+
+	var r0 *User
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (*User, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() *User); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockGitProvider_GetMyUserInfo_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetMyUserInfo'
+type MockGitProvider_GetMyUserInfo_Call struct {
+	*mock.Call
+}
+
+// GetMyUserInfo is a helper method to define mock.On call
+func (_e *MockGitProvider_Expecter) GetMyUserInfo() *MockGitProvider_GetMyUserInfo_Call {
+	return &MockGitProvider_GetMyUserInfo_Call{Call: _e.mock.On("GetMyUserInfo")}
+}
+
+func (_c *MockGitProvider_GetMyUserInfo_Call) Run(run func()) *MockGitProvider_GetMyUserInfo_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockGitProvider_GetMyUserInfo_Call) Return(_a0 *User, _a1 error) *MockGitProvider_GetMyUserInfo_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockGitProvider_GetMyUserInfo_Call) RunAndReturn(run func() (*User, error)) *MockGitProvider_GetMyUserInfo_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetOrg provides a mock function with given fields: name
+func (_m *MockGitProvider) GetOrg(name string) (*Organization, error) {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrg")
+	}
+
+	var r0 *Organization
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*Organization, error)); ok {
+		return rf(name)
+	}
+	if rf, ok := ret.Get(0).(func(string) *Organization); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Organization)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockGitProvider_GetOrg_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOrg'
+type MockGitProvider_GetOrg_Call struct {
+	*mock.Call
+}
+
+// GetOrg is a helper method to define mock.On call
+//   - name string
+func (_e *MockGitProvider_Expecter) GetOrg(name interface{}) *MockGitProvider_GetOrg_Call {
+	return &MockGitProvider_GetOrg_Call{Call: _e.mock.On("GetOrg", name)}
+}
+
+func (_c *MockGitProvider_GetOrg_Call) Run(run func(name string)) *MockGitProvider_GetOrg_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockGitProvider_GetOrg_Call) Return(_a0 *Organization, _a1 error) *MockGitProvider_GetOrg_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockGitProvider_GetOrg_Call) RunAndReturn(run func(string) (*Organization, error)) *MockGitProvider_GetOrg_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetRepo provides a mock function with given fields: owner, name
+func (_m *MockGitProvider) GetRepo(owner string, name string) (*Repository, error) {
+	ret := _m.Called(owner, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRepo")
+	}
+
+	var r0 *Repository
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (*Repository, error)); ok {
+		return rf(owner, name)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) *Repository); ok {
+		r0 = rf(owner, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Repository)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(owner, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockGitProvider_GetRepo_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRepo'
+type MockGitProvider_GetRepo_Call struct {
+	*mock.Call
+}
+
+// GetRepo is a helper method to define mock.On call
+//   - owner string
+//   - name string
+func (_e *MockGitProvider_Expecter) GetRepo(owner interface{}, name interface{}) *MockGitProvider_GetRepo_Call {
+	return &MockGitProvider_GetRepo_Call{Call: _e.mock.On("GetRepo", owner, name)}
+}
+
+func (_c *MockGitProvider_GetRepo_Call) Run(run func(owner string, name string)) *MockGitProvider_GetRepo_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockGitProvider_GetRepo_Call) Return(_a0 *Repository, _a1 error) *MockGitProvider_GetRepo_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockGitProvider_GetRepo_Call) RunAndReturn(run func(string, string) (*Repository, error)) *MockGitProvider_GetRepo_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IsInitialized provides a mock function with given fields:
+func (_m *MockGitProvider) IsInitialized() bool {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsInitialized")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// MockGitProvider_IsInitialized_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IsInitialized'
+type MockGitProvider_IsInitialized_Call struct {
+	*mock.Call
+}
+
+// IsInitialized is a helper method to define mock.On call
+func (_e *MockGitProvider_Expecter) IsInitialized() *MockGitProvider_IsInitialized_Call {
+	return &MockGitProvider_IsInitialized_Call{Call: _e.mock.On("IsInitialized")}
+}
+
+func (_c *MockGitProvider_IsInitialized_Call) Run(run func()) *MockGitProvider_IsInitialized_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockGitProvider_IsInitialized_Call) Return(_a0 bool) *MockGitProvider_IsInitialized_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockGitProvider_IsInitialized_Call) RunAndReturn(run func() bool) *MockGitProvider_IsInitialized_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListAccessTokens provides a mock function with given fields:
+func (_m *MockGitProvider) ListAccessTokens() ([]*AccessToken, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAccessTokens")
+	}
+
+	var r0 []*AccessToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]*AccessToken, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []*AccessToken); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*AccessToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockGitProvider_ListAccessTokens_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListAccessTokens'
+type MockGitProvider_ListAccessTokens_Call struct {
+	*mock.Call
+}
+
+// ListAccessTokens is a helper method to define mock.On call
+func (_e *MockGitProvider_Expecter) ListAccessTokens() *MockGitProvider_ListAccessTokens_Call {
+	return &MockGitProvider_ListAccessTokens_Call{Call: _e.mock.On("ListAccessTokens")}
+}
+
+func (_c *MockGitProvider_ListAccessTokens_Call) Run(run func()) *MockGitProvider_ListAccessTokens_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockGitProvider_ListAccessTokens_Call) Return(_a0 []*AccessToken, _a1 error) *MockGitProvider_ListAccessTokens_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockGitProvider_ListAccessTokens_Call) RunAndReturn(run func() ([]*AccessToken, error)) *MockGitProvider_ListAccessTokens_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListBranchProtections provides a mock function with given fields: owner, repo
+func (_m *MockGitProvider) ListBranchProtections(owner string, repo string) ([]*BranchProtection, error) {
+	ret := _m.Called(owner, repo)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListBranchProtections")
+	}
+
+	var r0 []*BranchProtection
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) ([]*BranchProtection, error)); ok {
+		return rf(owner, repo)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) []*BranchProtection); ok {
+		r0 = rf(owner, repo)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*BranchProtection)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(owner, repo)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockGitProvider_ListBranchProtections_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListBranchProtections'
+type MockGitProvider_ListBranchProtections_Call struct {
+	*mock.Call
+}
+
+// ListBranchProtections is a helper method to define mock.On call
+//   - owner string
+//   - repo string
+func (_e *MockGitProvider_Expecter) ListBranchProtections(owner interface{}, repo interface{}) *MockGitProvider_ListBranchProtections_Call {
+	return &MockGitProvider_ListBranchProtections_Call{Call: _e.mock.On("ListBranchProtections", owner, repo)}
+}
+
+func (_c *MockGitProvider_ListBranchProtections_Call) Run(run func(owner string, repo string)) *MockGitProvider_ListBranchProtections_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockGitProvider_ListBranchProtections_Call) Return(_a0 []*BranchProtection, _a1 error) *MockGitProvider_ListBranchProtections_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockGitProvider_ListBranchProtections_Call) RunAndReturn(run func(string, string) ([]*BranchProtection, error)) *MockGitProvider_ListBranchProtections_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListCollaborators provides a mock function with given fields: owner, repo
+func (_m *MockGitProvider) ListCollaborators(owner string, repo string) ([]*Collaborator, error) {
+	ret := _m.Called(owner, repo)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListCollaborators")
+	}
+
+	var r0 []*Collaborator
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) ([]*Collaborator, error)); ok {
+		return rf(owner, repo)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) []*Collaborator); ok {
+		r0 = rf(owner, repo)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*Collaborator)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(owner, repo)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockGitProvider_ListCollaborators_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListCollaborators'
+type MockGitProvider_ListCollaborators_Call struct {
+	*mock.Call
+}
+
+// ListCollaborators is a helper method to define mock.On call
+//   - owner string
+//   - repo string
+func (_e *MockGitProvider_Expecter) ListCollaborators(owner interface{}, repo interface{}) *MockGitProvider_ListCollaborators_Call {
+	return &MockGitProvider_ListCollaborators_Call{Call: _e.mock.On("ListCollaborators", owner, repo)}
+}
+
+func (_c *MockGitProvider_ListCollaborators_Call) Run(run func(owner string, repo string)) *MockGitProvider_ListCollaborators_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockGitProvider_ListCollaborators_Call) Return(_a0 []*Collaborator, _a1 error) *MockGitProvider_ListCollaborators_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockGitProvider_ListCollaborators_Call) RunAndReturn(run func(string, string) ([]*Collaborator, error)) *MockGitProvider_ListCollaborators_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListDeployKeys provides a mock function with given fields: owner, repo
+func (_m *MockGitProvider) ListDeployKeys(owner string, repo string) ([]*DeployKey, error) {
+	ret := _m.Called(owner, repo)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListDeployKeys")
+	}
+
+	var r0 []*DeployKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) ([]*DeployKey, error)); ok {
+		return rf(owner, repo)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) []*DeployKey); ok {
+		r0 = rf(owner, repo)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*DeployKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(owner, repo)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockGitProvider_ListDeployKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListDeployKeys'
+type MockGitProvider_ListDeployKeys_Call struct {
+	*mock.Call
+}
+
+// ListDeployKeys is a helper method to define mock.On call
+//   - owner string
+//   - repo string
+func (_e *MockGitProvider_Expecter) ListDeployKeys(owner interface{}, repo interface{}) *MockGitProvider_ListDeployKeys_Call {
+	return &MockGitProvider_ListDeployKeys_Call{Call: _e.mock.On("ListDeployKeys", owner, repo)}
+}
+
+func (_c *MockGitProvider_ListDeployKeys_Call) Run(run func(owner string, repo string)) *MockGitProvider_ListDeployKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockGitProvider_ListDeployKeys_Call) Return(_a0 []*DeployKey, _a1 error) *MockGitProvider_ListDeployKeys_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockGitProvider_ListDeployKeys_Call) RunAndReturn(run func(string, string) ([]*DeployKey, error)) *MockGitProvider_ListDeployKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListWebhooks provides a mock function with given fields: owner, repo
+func (_m *MockGitProvider) ListWebhooks(owner string, repo string) ([]*Webhook, error) {
+	ret := _m.Called(owner, repo)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListWebhooks")
+	}
+
+	var r0 []*Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) ([]*Webhook, error)); ok {
+		return rf(owner, repo)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) []*Webhook); ok {
+		r0 = rf(owner, repo)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*Webhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(owner, repo)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockGitProvider_ListWebhooks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListWebhooks'
+type MockGitProvider_ListWebhooks_Call struct {
+	*mock.Call
+}
+
+// ListWebhooks is a helper method to define mock.On call
+//   - owner string
+//   - repo string
+func (_e *MockGitProvider_Expecter) ListWebhooks(owner interface{}, repo interface{}) *MockGitProvider_ListWebhooks_Call {
+	return &MockGitProvider_ListWebhooks_Call{Call: _e.mock.On("ListWebhooks", owner, repo)}
+}
+
+func (_c *MockGitProvider_ListWebhooks_Call) Run(run func(owner string, repo string)) *MockGitProvider_ListWebhooks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockGitProvider_ListWebhooks_Call) Return(_a0 []*Webhook, _a1 error) *MockGitProvider_ListWebhooks_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockGitProvider_ListWebhooks_Call) RunAndReturn(run func(string, string) ([]*Webhook, error)) *MockGitProvider_ListWebhooks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MigrateRepo provides a mock function with given fields: opts
+func (_m *MockGitProvider) MigrateRepo(opts MigrateRepoOptions) (*Repository, error) {
+	ret := _m.Called(opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MigrateRepo")
+	}
+
+	var r0 *Repository
+	var r1 error
+	if rf, ok := ret.Get(0).(func(MigrateRepoOptions) (*Repository, error)); ok {
+		return rf(opts)
+	}
+	if rf, ok := ret.Get(0).(func(MigrateRepoOptions) *Repository); ok {
+		r0 = rf(opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Repository)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(MigrateRepoOptions) error); ok {
+		r1 = rf(opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockGitProvider_MigrateRepo_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MigrateRepo'
+type MockGitProvider_MigrateRepo_Call struct {
+	*mock.Call
+}
+
+// MigrateRepo is a helper method to define mock.On call
+//   - opts MigrateRepoOptions
+func (_e *MockGitProvider_Expecter) MigrateRepo(opts interface{}) *MockGitProvider_MigrateRepo_Call {
+	return &MockGitProvider_MigrateRepo_Call{Call: _e.mock.On("MigrateRepo", opts)}
+}
+
+func (_c *MockGitProvider_MigrateRepo_Call) Run(run func(opts MigrateRepoOptions)) *MockGitProvider_MigrateRepo_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(MigrateRepoOptions))
+	})
+	return _c
+}
+
+func (_c *MockGitProvider_MigrateRepo_Call) Return(_a0 *Repository, _a1 error) *MockGitProvider_MigrateRepo_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockGitProvider_MigrateRepo_Call) RunAndReturn(run func(MigrateRepoOptions) (*Repository, error)) *MockGitProvider_MigrateRepo_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetCollaborator provides a mock function with given fields: owner, repo, collaborator
+func (_m *MockGitProvider) SetCollaborator(owner string, repo string, collaborator Collaborator) error {
+	ret := _m.Called(owner, repo, collaborator)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetCollaborator")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, Collaborator) error); ok {
+		r0 = rf(owner, repo, collaborator)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockGitProvider_SetCollaborator_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetCollaborator'
+type MockGitProvider_SetCollaborator_Call struct {
+	*mock.Call
+}
+
+// SetCollaborator is a helper method to define mock.On call
+//   - owner string
+//   - repo string
+//   - collaborator Collaborator
+func (_e *MockGitProvider_Expecter) SetCollaborator(owner interface{}, repo interface{}, collaborator interface{}) *MockGitProvider_SetCollaborator_Call {
+	return &MockGitProvider_SetCollaborator_Call{Call: _e.mock.On("SetCollaborator", owner, repo, collaborator)}
+}
+
+func (_c *MockGitProvider_SetCollaborator_Call) Run(run func(owner string, repo string, collaborator Collaborator)) *MockGitProvider_SetCollaborator_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(Collaborator))
+	})
+	return _c
+}
+
+func (_c *MockGitProvider_SetCollaborator_Call) Return(_a0 error) *MockGitProvider_SetCollaborator_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockGitProvider_SetCollaborator_Call) RunAndReturn(run func(string, string, Collaborator) error) *MockGitProvider_SetCollaborator_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockGitProvider creates a new instance of MockGitProvider. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockGitProvider(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockGitProvider {
+	mock := &MockGitProvider{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
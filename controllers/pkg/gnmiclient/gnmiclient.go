@@ -0,0 +1,165 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gnmiclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Mode determines how the config is pushed to the device
+type Mode string
+
+const (
+	// ModeReplace replaces the config of the device with the pushed config
+	ModeReplace Mode = "replace"
+	// ModeUpdate merges the pushed config into the config of the device
+	ModeUpdate Mode = "update"
+)
+
+// Encoding is the encoding of the pushed config
+type Encoding string
+
+const (
+	EncodingJSON     Encoding = "JSON"
+	EncodingJSONIETF Encoding = "JSON_IETF"
+)
+
+// Target is the device the config is pushed to
+type Target struct {
+	// Address is the host:port of the gNMI server of the device
+	Address  string
+	Username string
+	Password string
+	// Insecure disables TLS
+	Insecure bool
+	// SkipVerify disables the verification of the certificate of the device
+	SkipVerify bool
+	// CA is the PEM encoded certificate authority used to verify the
+	// certificate of the device, the system pool is used when empty
+	CA       []byte
+	Encoding Encoding
+}
+
+// Client pushes config to devices over gNMI
+type Client interface {
+	// Set pushes the config, RFC7951 json rooted at the top of the schema, to
+	// the target in a single gNMI Set request
+	Set(ctx context.Context, t *Target, mode Mode, config []byte) error
+}
+
+func New(timeout time.Duration) Client {
+	return &gnmiClient{timeout: timeout}
+}
+
+type gnmiClient struct {
+	timeout time.Duration
+}
+
+func (r *gnmiClient) Set(ctx context.Context, t *Target, mode Mode, config []byte) error {
+	req, err := getSetRequest(t.Encoding, mode, config)
+	if err != nil {
+		return err
+	}
+	creds, err := getTransportCredentials(t)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, t.Address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return errors.Wrapf(err, "cannot dial %s", t.Address)
+	}
+	defer conn.Close()
+
+	if t.Username != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "username", t.Username, "password", t.Password)
+	}
+	if _, err := gnmi.NewGNMIClient(conn).Set(ctx, req); err != nil {
+		return errors.Wrapf(err, "cannot set config on %s", t.Address)
+	}
+	return nil
+}
+
+// getSetRequest returns the Set request replacing or updating the root of the
+// schema with the config
+func getSetRequest(encoding Encoding, mode Mode, config []byte) (*gnmi.SetRequest, error) {
+	val := &gnmi.TypedValue{}
+	switch encoding {
+	case EncodingJSONIETF, "":
+		val.Value = &gnmi.TypedValue_JsonIetfVal{JsonIetfVal: config}
+	case EncodingJSON:
+		val.Value = &gnmi.TypedValue_JsonVal{JsonVal: config}
+	default:
+		return nil, fmt.Errorf("unsupported encoding %s", encoding)
+	}
+	update := []*gnmi.Update{{Path: &gnmi.Path{}, Val: val}}
+
+	switch mode {
+	case ModeReplace:
+		return &gnmi.SetRequest{Replace: update}, nil
+	case ModeUpdate, "":
+		return &gnmi.SetRequest{Update: update}, nil
+	default:
+		return nil, fmt.Errorf("unsupported mode %s", mode)
+	}
+}
+
+func getTransportCredentials(t *Target) (credentials.TransportCredentials, error) {
+	if t.Insecure {
+		return insecure.NewCredentials(), nil
+	}
+	//nolint:gosec // skipping the verification is an explicit choice of the target
+	cfg := &tls.Config{InsecureSkipVerify: t.SkipVerify}
+	if len(t.CA) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(t.CA) {
+			return nil, fmt.Errorf("cannot parse the certificate authority of %s", t.Address)
+		}
+		cfg.RootCAs = pool
+	}
+	return credentials.NewTLS(cfg), nil
+}
+
+// Code returns the gRPC status code of the error returned by Set, errors that
+// were not returned by the device are reported as Unknown
+func Code(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+	for err != nil {
+		if s, ok := status.FromError(err); ok {
+			return s.Code()
+		}
+		err = errors.Unwrap(err)
+	}
+	return codes.Unknown
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gnmiclient
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// testServer stands in for the gNMI server of a device, it records the Set
+// requests and rejects the requests with invalid credentials
+type testServer struct {
+	gnmi.UnimplementedGNMIServer
+	m        sync.Mutex
+	requests []*gnmi.SetRequest
+}
+
+func (r *testServer) Set(ctx context.Context, req *gnmi.SetRequest) (*gnmi.SetResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if fmt.Sprint(md.Get("username"), md.Get("password")) != "[admin] [secret]" {
+		return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+	}
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.requests = append(r.requests, req)
+	return &gnmi.SetResponse{}, nil
+}
+
+func startTestServer(t *testing.T) (*testServer, string) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("cannot listen: %v", err)
+	}
+	s := &testServer{}
+	srv := grpc.NewServer()
+	gnmi.RegisterGNMIServer(srv, s)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+	return s, lis.Addr().String()
+}
+
+func TestSet(t *testing.T) {
+	config := []byte(`{"srl_nokia-interfaces:interface":[{"name":"ethernet-1/1"}]}`)
+	jsonIETF := &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonIetfVal{JsonIetfVal: config}}
+	jsonVal := &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonVal{JsonVal: config}}
+
+	cases := map[string]struct {
+		mode     Mode
+		encoding Encoding
+		password string
+		want     *gnmi.SetRequest
+		wantCode codes.Code
+	}{
+		"Update": {
+			mode:     ModeUpdate,
+			password: "secret",
+			want:     &gnmi.SetRequest{Update: []*gnmi.Update{{Path: &gnmi.Path{}, Val: jsonIETF}}},
+			wantCode: codes.OK,
+		},
+		"Replace": {
+			mode:     ModeReplace,
+			password: "secret",
+			want:     &gnmi.SetRequest{Replace: []*gnmi.Update{{Path: &gnmi.Path{}, Val: jsonIETF}}},
+			wantCode: codes.OK,
+		},
+		"JSON": {
+			mode:     ModeUpdate,
+			encoding: EncodingJSON,
+			password: "secret",
+			want:     &gnmi.SetRequest{Update: []*gnmi.Update{{Path: &gnmi.Path{}, Val: jsonVal}}},
+			wantCode: codes.OK,
+		},
+		"Unauthenticated": {
+			mode:     ModeUpdate,
+			password: "wrong",
+			wantCode: codes.Unauthenticated,
+		},
+		"UnsupportedEncoding": {
+			mode:     ModeUpdate,
+			encoding: "protobuf",
+			password: "secret",
+			wantCode: codes.Unknown,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s, address := startTestServer(t)
+			c := New(5 * time.Second)
+			err := c.Set(context.Background(), &Target{
+				Address:  address,
+				Username: "admin",
+				Password: tc.password,
+				Insecure: true,
+				Encoding: tc.encoding,
+			}, tc.mode, config)
+			if diff := cmp.Diff(tc.wantCode, Code(err)); diff != "" {
+				t.Errorf("Set() code -want, +got:\n%s\nerror: %v", diff, err)
+			}
+			if tc.want == nil {
+				if len(s.requests) != 0 {
+					t.Errorf("Set() want no request on the server, got: %v", s.requests)
+				}
+				return
+			}
+			if len(s.requests) != 1 {
+				t.Fatalf("Set() want 1 request on the server, got: %d", len(s.requests))
+			}
+			if diff := cmp.Diff(tc.want.String(), s.requests[0].String()); diff != "" {
+				t.Errorf("Set() request -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSetUnreachable(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("cannot listen: %v", err)
+	}
+	address := lis.Addr().String()
+	lis.Close()
+
+	err = New(time.Second).Set(context.Background(), &Target{Address: address, Insecure: true}, ModeUpdate, []byte(`{}`))
+	if diff := cmp.Diff(codes.Unavailable, Code(err)); diff != "" {
+		t.Errorf("Set() code -want, +got:\n%s\nerror: %v", diff, err)
+	}
+}
@@ -27,6 +27,7 @@ import (
 	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
 	porchconfigv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
 	"github.com/nephio-project/nephio/controllers/pkg/cluster"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/readiness"
 	ctrlconfig "github.com/nephio-project/nephio/controllers/pkg/reconcilers/config"
 	reconcilerinterface "github.com/nephio-project/nephio/controllers/pkg/reconcilers/reconciler-interface"
 	"github.com/nephio-project/nephio/controllers/pkg/resource"
@@ -71,6 +72,7 @@ func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c a
 
 	r.Client = mgr.GetClient()
 	r.porchClient = cfg.PorchClient
+	r.clusterReadiness = cfg.ClusterReadiness
 
 	return nil, ctrl.NewControllerManagedBy(mgr).
 		Named("BootstrapPackageController").
@@ -80,7 +82,8 @@ func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c a
 
 type reconciler struct {
 	client.Client
-	porchClient client.Client
+	porchClient      client.Client
+	clusterReadiness readiness.Options
 }
 
 func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -132,14 +135,14 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 				return ctrl.Result{}, errors.Wrap(err, msg)
 			}
 			if ok {
-				clusterClient, ready, err := clusterClient.GetClusterClient(ctx)
+				clusterClient, status, err := clusterClient.GetClusterClient(ctx)
 				if err != nil {
 					msg := "cannot get clusterClient"
 					log.Error(err, msg)
 					return ctrl.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(err, msg)
 				}
-				if !ready {
-					log.Info("cluster not ready")
+				if !status.Ready {
+					log.Info("cluster not ready", "reason", status.Reason, "message", status.Message)
 					return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
 				}
 				// install the resources to the cluster
@@ -170,11 +173,12 @@ func (r *reconciler) GetClusterClient(ctx context.Context, clusterName string) (
 	}
 	clusterSecret := corev1.Secret{}
 	for _, secret := range secrets.Items {
-		if strings.Contains(secret.GetName(), clusterName) {
+		if cluster.IsClusterSecret(&secret, clusterName) {
 			clusterSecret = secret
 
 			clusterClient, ok := cluster.Cluster{
-				Client: r.Client,
+				Client:    r.Client,
+				Readiness: r.clusterReadiness,
 			}.GetClusterClient(&clusterSecret)
 
 			if ok {
@@ -23,6 +23,9 @@ import (
 	"time"
 
 	"github.com/nephio-project/nephio/controllers/pkg/cluster"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/cache"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/readiness"
+	ctrlconfig "github.com/nephio-project/nephio/controllers/pkg/reconcilers/config"
 	reconcilerinterface "github.com/nephio-project/nephio/controllers/pkg/reconcilers/reconciler-interface"
 	"github.com/nephio-project/nephio/controllers/pkg/resource"
 	"github.com/pkg/errors"
@@ -56,6 +59,10 @@ const (
 // SetupWithManager sets up the controller with the Manager.
 func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c any) (map[schema.GroupVersionKind]chan event.GenericEvent, error) {
 	r.Client = mgr.GetClient()
+	// the controller config is optional for this reconciler
+	if cfg, ok := c.(*ctrlconfig.ControllerConfig); ok {
+		r.clusterReadiness = cfg.ClusterReadiness
+	}
 
 	return nil, ctrl.NewControllerManagedBy(mgr).
 		Named("BootstrapSecretController").
@@ -65,6 +72,7 @@ func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c a
 
 type reconciler struct {
 	client.Client
+	clusterReadiness readiness.Options
 }
 
 func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -78,11 +86,14 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			log.Error(err, msg)
 			return ctrl.Result{}, errors.Wrap(resource.IgnoreNotFound(err), msg)
 		}
+		// the secret might hold cluster credentials, so we drop the cached cluster client
+		cache.Default().Invalidate(req.NamespacedName)
 		return reconcile.Result{}, nil
 	}
 
 	// if the secret is being deleted don't do anything for now
 	if resource.WasDeleted(cr) {
+		cache.Default().Invalidate(req.NamespacedName)
 		return reconcile.Result{}, nil
 	}
 
@@ -114,19 +125,19 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			}
 			found := false
 			for _, secret := range secrets.Items {
-				if strings.Contains(secret.GetName(), clusterName) {
+				if cluster.IsClusterSecret(&secret, clusterName) {
 					secret := secret // required to prevent gosec warning: G601 (CWE-118): Implicit memory aliasing in for loop
-					clusterClient, ok := cluster.Cluster{Client: r.Client}.GetClusterClient(&secret)
+					clusterClient, ok := cluster.Cluster{Client: r.Client, Readiness: r.clusterReadiness}.GetClusterClient(&secret)
 					if ok {
 						found = true
-						clusterClient, ready, err := clusterClient.GetClusterClient(ctx)
+						clusterClient, status, err := clusterClient.GetClusterClient(ctx)
 						if err != nil {
 							msg := "cannot get clusterClient"
 							log.Error(err, msg)
 							return ctrl.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(err, msg)
 						}
-						if !ready {
-							log.Info("cluster not ready")
+						if !status.Ready {
+							log.Info("cluster not ready", "reason", status.Reason, "message", status.Message)
 							return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
 						}
 
@@ -19,6 +19,7 @@ package ctrlrconfig
 import (
 	"time"
 
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/readiness"
 	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
 	vlanv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/vlan/v1alpha1"
 	"github.com/nokia/k8s-ipam/pkg/proxy/clientproxy"
@@ -35,4 +36,11 @@ type ControllerConfig struct {
 	Address         string // backend server address
 	IpamClientProxy clientproxy.Proxy[*ipamv1alpha1.NetworkInstance, *ipamv1alpha1.IPClaim]
 	VlanClientProxy clientproxy.Proxy[*vlanv1alpha1.VLANIndex, *vlanv1alpha1.VLANClaim]
+	// ClusterReadiness defines the readiness checks performed on remote clusters
+	ClusterReadiness readiness.Options
+	// EnableWebhooks registers the admission webhooks of the reconcilers
+	EnableWebhooks bool
+	// EnabledReconcilers are the names of the reconcilers set up with the
+	// manager
+	EnabledReconcilers []string
 }
@@ -0,0 +1,188 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package genericspecializer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/nephio-project/nephio/krm-functions/lib/kptrl"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// finalizer is set on the packages with claims, so the claims are released
+	// before the package is deleted
+	finalizer = "specializer.nephio.org/finalizer"
+)
+
+// getFinalizer returns the finalizer of the controller running the named
+// specializer, every dedicated controller releases the claims of its own
+// specializer so it sets a finalizer of its own
+func getFinalizer(specializer string) string {
+	if specializer == "" {
+		return finalizer
+	}
+	return strings.ToLower(specializer) + "." + finalizer
+}
+
+// Releaser is implemented by specializers claiming resources from a backend,
+// the For resources are the claims to release when a package is deleted
+type Releaser interface {
+	Release(ctx context.Context, o *fn.KubeObject) error
+}
+
+// isReleaser returns true when the specializer releases its claims
+func (r *specializer) isReleaser() bool {
+	_, ok := r.Specializer.(Releaser)
+	return ok
+}
+
+// hasReleaser returns true when one of the specializers releases its claims
+func hasReleaser(ss []*specializer) bool {
+	for _, s := range ss {
+		if s.isReleaser() {
+			return true
+		}
+	}
+	return false
+}
+
+// getClaimKey identifies a claim across the revisions of a package
+func getClaimKey(o *fn.KubeObject) string {
+	return fmt.Sprintf("%s/%s/%s/%s", o.GetAPIVersion(), o.GetKind(), o.GetNamespace(), o.GetName())
+}
+
+// getClaims returns the claims of the package revision per claim key and the
+// specializer releasing them
+func (r *reconciler) getClaims(ctx context.Context, pr *porchv1alpha1.PackageRevision, ss []*specializer) (map[string]*fn.KubeObject, map[string]*specializer, error) {
+	prr := &porchv1alpha1.PackageRevisionResources{}
+	if err := r.porchClient.Get(ctx, client.ObjectKeyFromObject(pr), prr); err != nil {
+		return nil, nil, errors.Wrap(err, "cannot get package revision resources")
+	}
+	rl, err := kptrl.GetResourceList(prr.Spec.Resources)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "cannot get resourceList")
+	}
+	claims := map[string]*fn.KubeObject{}
+	releasers := map[string]*specializer{}
+	for _, o := range rl.Items {
+		for _, s := range ss {
+			if s.isReleaser() && s.isFor(o) {
+				claims[getClaimKey(o)] = o
+				releasers[getClaimKey(o)] = s
+			}
+		}
+	}
+	return claims, releasers, nil
+}
+
+// getRemainingClaims returns the keys of the claims held by the other
+// revisions of the package which are not being deleted, these claims are
+// shared with the deleted revision and cannot be released
+func (r *reconciler) getRemainingClaims(ctx context.Context, pr *porchv1alpha1.PackageRevision, ss []*specializer) (map[string]struct{}, error) {
+	prl := &porchv1alpha1.PackageRevisionList{}
+	if err := r.porchClient.List(ctx, prl, client.InNamespace(pr.GetNamespace())); err != nil {
+		return nil, errors.Wrap(err, "cannot list package revisions")
+	}
+	remaining := map[string]struct{}{}
+	for i := range prl.Items {
+		other := &prl.Items[i]
+		if other.GetName() == pr.GetName() ||
+			other.Spec.RepositoryName != pr.Spec.RepositoryName ||
+			other.Spec.PackageName != pr.Spec.PackageName ||
+			resource.WasDeleted(other) {
+			continue
+		}
+		claims, _, err := r.getClaims(ctx, other, ss)
+		if err != nil {
+			return nil, err
+		}
+		for key := range claims {
+			remaining[key] = struct{}{}
+		}
+	}
+	return remaining, nil
+}
+
+// releaseFinalizers releases the claims of the deleted package revision and
+// removes the finalizers of the controller, a dedicated controller also takes
+// over the finalizer set by the generic specializer and then releases the
+// claims of all specializers
+func (r *reconciler) releaseFinalizers(ctx context.Context, pr *porchv1alpha1.PackageRevision) error {
+	specializer := r.specializer
+	finalizers := []string{}
+	if resource.FinalizerExists(pr, getFinalizer(r.specializer)) {
+		finalizers = append(finalizers, getFinalizer(r.specializer))
+	}
+	if r.specializer != "" && resource.FinalizerExists(pr, finalizer) {
+		specializer = ""
+		finalizers = append(finalizers, finalizer)
+	}
+	if len(finalizers) == 0 {
+		return nil
+	}
+	if err := r.releaseClaims(ctx, pr, specializer); err != nil {
+		return errors.Wrap(err, "cannot release claims")
+	}
+	for _, f := range finalizers {
+		if err := resource.NewAPIFinalizer(r.porchClient, f).RemoveFinalizer(ctx, pr); err != nil {
+			return errors.Wrap(err, "cannot remove finalizer")
+		}
+	}
+	log.FromContext(ctx).Info("claims released")
+	return nil
+}
+
+// releaseClaims releases the claims of the deleted package revision in their
+// backend, unless another revision of the package still holds them
+func (r *reconciler) releaseClaims(ctx context.Context, pr *porchv1alpha1.PackageRevision, specializer string) error {
+	log := log.FromContext(ctx)
+	ss := getSpecializers(r.cfg, pr, specializer)
+	if !hasReleaser(ss) {
+		return nil
+	}
+	claims, releasers, err := r.getClaims(ctx, pr, ss)
+	if err != nil {
+		if resource.IgnoreNotFound(err) == nil {
+			// without resources the package holds no claims
+			return nil
+		}
+		return err
+	}
+	remaining, err := r.getRemainingClaims(ctx, pr, ss)
+	if err != nil {
+		return err
+	}
+	for key, o := range claims {
+		if _, ok := remaining[key]; ok {
+			log.Info("claim held by another revision, not released", "claim", key)
+			continue
+		}
+		if err := releasers[key].Specializer.(Releaser).Release(ctx, o); err != nil {
+			return errors.Wrapf(err, "cannot release claim %s", key)
+		}
+		log.Info("claim released", "claim", key)
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package genericspecializer
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	ctrlconfig "github.com/nephio-project/nephio/controllers/pkg/reconcilers/config"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type testReleaser struct {
+	testSpecializer
+	released *[]string
+}
+
+func (r *testReleaser) Release(ctx context.Context, o *fn.KubeObject) error {
+	*r.released = append(*r.released, o.GetName())
+	return nil
+}
+
+func TestReleaseClaims(t *testing.T) {
+	released := []string{}
+	RegisterSpecializer("test-c", func(cfg *ctrlconfig.ControllerConfig) Specializer {
+		return &testReleaser{testSpecializer: *newTestSpecializer("C")(cfg).(*testSpecializer), released: &released}
+	})
+	unregisterTestSpecializers(t, "test-c")
+
+	claimA := "apiVersion: test.nephio.org/v1alpha1\nkind: C\nmetadata:\n  name: a\n"
+	claimB := "apiVersion: test.nephio.org/v1alpha1\nkind: C\nmetadata:\n  name: b\n"
+	now := metav1.Now()
+
+	cases := map[string]struct {
+		// others are the other package revisions and their resources
+		others []client.Object
+		want   []string
+	}{
+		"LastRevision": {
+			want: []string{"a", "b"},
+		},
+		"SharedWithRevision": {
+			others: []client.Object{
+				&porchv1alpha1.PackageRevision{
+					ObjectMeta: metav1.ObjectMeta{Name: "pr-v2", Namespace: "default"},
+					Spec:       porchv1alpha1.PackageRevisionSpec{RepositoryName: "repo", PackageName: "pkg"},
+					Status: porchv1alpha1.PackageRevisionStatus{
+						Conditions: []porchv1alpha1.Condition{{Type: "test.nephio.org/v1alpha1.C.c1"}},
+					},
+				},
+				&porchv1alpha1.PackageRevisionResources{
+					ObjectMeta: metav1.ObjectMeta{Name: "pr-v2", Namespace: "default"},
+					Spec: porchv1alpha1.PackageRevisionResourcesSpec{
+						Resources: map[string]string{"Kptfile": testKptfile, "a.yaml": claimA},
+					},
+				},
+			},
+			want: []string{"b"},
+		},
+		"SharedWithDeletedRevision": {
+			others: []client.Object{
+				&porchv1alpha1.PackageRevision{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "pr-v2",
+						Namespace:         "default",
+						DeletionTimestamp: &now,
+						Finalizers:        []string{finalizer},
+					},
+					Spec: porchv1alpha1.PackageRevisionSpec{RepositoryName: "repo", PackageName: "pkg"},
+					Status: porchv1alpha1.PackageRevisionStatus{
+						Conditions: []porchv1alpha1.Condition{{Type: "test.nephio.org/v1alpha1.C.c1"}},
+					},
+				},
+				&porchv1alpha1.PackageRevisionResources{
+					ObjectMeta: metav1.ObjectMeta{Name: "pr-v2", Namespace: "default"},
+					Spec: porchv1alpha1.PackageRevisionResourcesSpec{
+						Resources: map[string]string{"Kptfile": testKptfile, "a.yaml": claimA},
+					},
+				},
+			},
+			want: []string{"a", "b"},
+		},
+		"OtherPackage": {
+			others: []client.Object{
+				&porchv1alpha1.PackageRevision{
+					ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"},
+					Spec:       porchv1alpha1.PackageRevisionSpec{RepositoryName: "repo", PackageName: "other"},
+					Status: porchv1alpha1.PackageRevisionStatus{
+						Conditions: []porchv1alpha1.Condition{{Type: "test.nephio.org/v1alpha1.C.c1"}},
+					},
+				},
+				&porchv1alpha1.PackageRevisionResources{
+					ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"},
+					Spec: porchv1alpha1.PackageRevisionResourcesSpec{
+						Resources: map[string]string{"Kptfile": testKptfile, "a.yaml": claimA, "b.yaml": claimB},
+					},
+				},
+			},
+			want: []string{"a", "b"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			released = []string{}
+			scheme := runtime.NewScheme()
+			if err := porchv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+			pr := &porchv1alpha1.PackageRevision{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "pr-v1",
+					Namespace:         "default",
+					DeletionTimestamp: &now,
+					Finalizers:        []string{finalizer},
+				},
+				Spec: porchv1alpha1.PackageRevisionSpec{RepositoryName: "repo", PackageName: "pkg"},
+				Status: porchv1alpha1.PackageRevisionStatus{
+					Conditions: []porchv1alpha1.Condition{{Type: "test.nephio.org/v1alpha1.C.c1"}},
+				},
+			}
+			prr := &porchv1alpha1.PackageRevisionResources{
+				ObjectMeta: metav1.ObjectMeta{Name: "pr-v1", Namespace: "default"},
+				Spec: porchv1alpha1.PackageRevisionResourcesSpec{
+					Resources: map[string]string{"Kptfile": testKptfile, "a.yaml": claimA, "b.yaml": claimB},
+				},
+			}
+			objs := []client.Object{pr, prr}
+			// the fake client sets the resource version of the objects, the
+			// objects of the cases are shared by the runs of the test
+			for _, o := range tc.others {
+				objs = append(objs, o.DeepCopyObject().(client.Object))
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+			r := &reconciler{Client: c, cfg: &ctrlconfig.ControllerConfig{}, porchClient: c}
+
+			if err := r.releaseClaims(context.Background(), pr, ""); err != nil {
+				t.Fatal(err)
+			}
+			sort.Strings(released)
+			if diff := cmp.Diff(tc.want, released); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReleaseFinalizers(t *testing.T) {
+	released := []string{}
+	for name, kind := range map[string]string{"test-c": "C", "test-d": "D"} {
+		kind := kind
+		RegisterSpecializer(name, func(cfg *ctrlconfig.ControllerConfig) Specializer {
+			return &testReleaser{testSpecializer: *newTestSpecializer(kind)(cfg).(*testSpecializer), released: &released}
+		})
+	}
+	unregisterTestSpecializers(t, "test-c", "test-d")
+
+	cases := map[string]struct {
+		specializer    string
+		finalizers     []string
+		wantReleased   []string
+		wantFinalizers []string
+	}{
+		"Generic": {
+			finalizers:   []string{finalizer},
+			wantReleased: []string{"c", "d"},
+		},
+		"Dedicated": {
+			specializer:  "test-c",
+			finalizers:   []string{getFinalizer("test-c")},
+			wantReleased: []string{"c"},
+		},
+		"DedicatedTakesOverGeneric": {
+			specializer:  "test-c",
+			finalizers:   []string{getFinalizer("test-c"), finalizer},
+			wantReleased: []string{"c", "d"},
+		},
+		"OtherDedicated": {
+			specializer:    "test-c",
+			finalizers:     []string{getFinalizer("test-c"), getFinalizer("test-d")},
+			wantReleased:   []string{"c"},
+			wantFinalizers: []string{getFinalizer("test-d")},
+		},
+		"NoFinalizer": {
+			specializer:    "test-c",
+			finalizers:     []string{getFinalizer("test-d")},
+			wantReleased:   []string{},
+			wantFinalizers: []string{getFinalizer("test-d")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			released = []string{}
+			scheme := runtime.NewScheme()
+			if err := porchv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+			now := metav1.Now()
+			pr := &porchv1alpha1.PackageRevision{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "pr",
+					Namespace:         "default",
+					DeletionTimestamp: &now,
+					Finalizers:        tc.finalizers,
+				},
+				Status: porchv1alpha1.PackageRevisionStatus{
+					Conditions: []porchv1alpha1.Condition{
+						{Type: "test.nephio.org/v1alpha1.C.c"},
+						{Type: "test.nephio.org/v1alpha1.D.d"},
+					},
+				},
+			}
+			prr := &porchv1alpha1.PackageRevisionResources{
+				ObjectMeta: metav1.ObjectMeta{Name: "pr", Namespace: "default"},
+				Spec: porchv1alpha1.PackageRevisionResourcesSpec{
+					Resources: map[string]string{
+						"Kptfile": testKptfile,
+						"c.yaml":  "apiVersion: test.nephio.org/v1alpha1\nkind: C\nmetadata:\n  name: c\n",
+						"d.yaml":  "apiVersion: test.nephio.org/v1alpha1\nkind: D\nmetadata:\n  name: d\n",
+					},
+				},
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pr, prr).Build()
+			r := &reconciler{Client: c, cfg: &ctrlconfig.ControllerConfig{}, porchClient: c, specializer: tc.specializer}
+
+			if err := r.releaseFinalizers(context.Background(), pr); err != nil {
+				t.Fatal(err)
+			}
+			sort.Strings(released)
+			if diff := cmp.Diff(tc.wantReleased, released); diff != "" {
+				t.Errorf("released -want, +got:\n%s", diff)
+			}
+			got := &porchv1alpha1.PackageRevision{}
+			if err := c.Get(context.Background(), client.ObjectKeyFromObject(pr), got); resource.IgnoreNotFound(err) != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.wantFinalizers, got.GetFinalizers()); diff != "" {
+				t.Errorf("finalizers -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetFinalizer(t *testing.T) {
+	if got := getFinalizer(""); got != finalizer {
+		t.Errorf("generic specializer: want %s, got %s", finalizer, got)
+	}
+	if got, want := getFinalizer("configInject"), "configinject.specializer.nephio.org/finalizer"; got != want {
+		t.Errorf("dedicated controller: want %s, got %s", want, got)
+	}
+}
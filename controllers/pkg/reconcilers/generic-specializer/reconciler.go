@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"slices"
 	"strings"
 	"time"
 
@@ -27,20 +28,13 @@ import (
 	kptv1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
 	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
 	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
-	porchcondition "github.com/nephio-project/nephio/controllers/pkg/porch/condition"
 	porchutil "github.com/nephio-project/nephio/controllers/pkg/porch/util"
 	ctrlconfig "github.com/nephio-project/nephio/controllers/pkg/reconcilers/config"
 	reconcilerinterface "github.com/nephio-project/nephio/controllers/pkg/reconcilers/reconciler-interface"
 	"github.com/nephio-project/nephio/controllers/pkg/resource"
-	configinjectfn "github.com/nephio-project/nephio/krm-functions/configinject-fn/fn"
-	ipamfn "github.com/nephio-project/nephio/krm-functions/ipam-fn/fn"
-	kptfilelibv1 "github.com/nephio-project/nephio/krm-functions/lib/kptfile/v1"
+	specializerreconciler "github.com/nephio-project/nephio/controllers/pkg/specializer-reconciler"
 	"github.com/nephio-project/nephio/krm-functions/lib/kptrl"
 	"github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
-	vlanfn "github.com/nephio-project/nephio/krm-functions/vlan-fn/fn"
-	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
-	vlanv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/vlan/v1alpha1"
-	"github.com/nokia/k8s-ipam/pkg/proxy/clientproxy"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -54,10 +48,14 @@ import (
 
 const (
 	RequeueDuration = 10 * time.Second
+
+	// reconcilerName is the name of the generic specializer, which runs all
+	// registered specializers
+	reconcilerName = "genericspecializer"
 )
 
 func init() {
-	reconcilerinterface.Register("genericspecializer", &reconciler{})
+	reconcilerinterface.Register(reconcilerName, &reconciler{})
 }
 
 // +kubebuilder:rbac:groups=porch.kpt.dev,resources=packagerevisions,verbs=get;list;watch;create;update;patch;delete
@@ -74,10 +72,15 @@ func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c i
 	}
 
 	r.Client = mgr.GetClient()
+	r.cfg = cfg
 	r.porchClient = cfg.PorchClient
+	r.finalizer = resource.NewAPIFinalizer(cfg.PorchClient, getFinalizer(r.specializer))
+
+	if r.specializer != "" {
+		return nil, r.setupSpecializer(ctx, mgr, cfg)
+	}
+
 	r.recorder = mgr.GetEventRecorderFor("generic-specializer")
-	r.ipamClientProxy = cfg.IpamClientProxy
-	r.vlanClientProxy = cfg.VlanClientProxy
 
 	// TBD how does the proxy cache work with the injector for updates
 	return nil, ctrl.NewControllerManagedBy(mgr).
@@ -86,13 +89,38 @@ func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c i
 		Complete(r)
 }
 
-// reconciler reconciles a NetworkInstance object
+// setupSpecializer sets up the dedicated controller of the specializer, the
+// controller is not set up when the generic specializer is enabled as both
+// would specialize the same packages
+func (r *reconciler) setupSpecializer(ctx context.Context, mgr ctrl.Manager, cfg *ctrlconfig.ControllerConfig) error {
+	if slices.Contains(cfg.EnabledReconcilers, reconcilerName) {
+		log.FromContext(ctx).Info("generic specializer enabled, dedicated controller not set up", "specializer", r.specializer)
+		return nil
+	}
+	f, ok := getFactory(r.specializer)
+	if !ok {
+		return fmt.Errorf("specializer %s is not registered", r.specializer)
+	}
+	name := strings.ToLower(r.specializer) + "-specializer"
+	r.recorder = mgr.GetEventRecorderFor(name)
+	return specializerreconciler.Setup(mgr, specializerreconciler.Config{
+		Name: name,
+		For:  f(cfg).GetConfig().For,
+	}, r)
+}
+
+// reconciler runs the registered specializers on the package revisions, or
+// only its specializer when it is the dedicated controller of a specializer
 type reconciler struct {
 	client.Client
-	ipamClientProxy clientproxy.Proxy[*ipamv1alpha1.NetworkInstance, *ipamv1alpha1.IPClaim]
-	vlanClientProxy clientproxy.Proxy[*vlanv1alpha1.VLANIndex, *vlanv1alpha1.VLANClaim]
-	porchClient     client.Client
-	recorder        record.EventRecorder
+	// cfg creates the registered specializers
+	cfg         *ctrlconfig.ControllerConfig
+	porchClient client.Client
+	finalizer   *resource.APIFinalizer
+	recorder    record.EventRecorder
+	// specializer is the name of the specializer the dedicated controller
+	// runs, empty for the generic specializer
+	specializer string
 }
 
 func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -110,6 +138,18 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{}, nil
 	}
 
+	if resource.WasDeleted(pr) {
+		// package revision being deleted
+		// release the claims of the package in their backend, when successful
+		// remove the finalizer
+		if err := r.releaseFinalizers(ctx, pr); err != nil {
+			r.recorder.Event(pr, corev1.EventTypeWarning, "ReconcileError", err.Error())
+			log.Error(err, "cannot release claims")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
 	// check if the PackageVariant has done its work
 	pvReady, err := porchutil.PackageVariantReady(ctx, pr, r.porchClient)
 	if err != nil {
@@ -126,24 +166,17 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{RequeueAfter: RequeueDuration}, nil
 	}
 
-	ipamf := ipamfn.New(r.ipamClientProxy)
-	ipamkrmfn := fn.ResourceListProcessorFunc(ipamf.Run)
-	ipamFor := ipamf.GetConfig().For
-
-	vlanf := vlanfn.New(r.vlanClientProxy)
-	vlankrmfn := fn.ResourceListProcessorFunc(vlanf.Run)
-	vlanFor := vlanf.GetConfig().For
-
-	configInjectf := configinjectfn.New(r.porchClient)
-	configInjectkrmfn := fn.ResourceListProcessorFunc(configInjectf.Run)
-	configInjectFor := configInjectf.GetConfig().For
-
+	ss := getSpecializers(r.cfg, pr, r.specializer)
 	// we just check for forResource conditions and we don't care if it is satisfied already
 	// this allows us to refresh the allocation.
-	if porchcondition.HasSpecificTypeConditions(pr.Status.Conditions, kptfilelibv1.GetConditionType(&ipamFor)) ||
-		porchcondition.HasSpecificTypeConditions(pr.Status.Conditions, kptfilelibv1.GetConditionType(&vlanFor)) ||
-		porchcondition.HasSpecificTypeConditions(pr.Status.Conditions, kptfilelibv1.GetConditionType(&configInjectFor)) {
-
+	if len(ss) > 0 {
+		// add finalizer to release the claims of the package when it is deleted
+		if hasReleaser(ss) {
+			if err := r.finalizer.AddFinalizer(ctx, pr); err != nil {
+				log.Error(err, "cannot add finalizer")
+				return ctrl.Result{}, err
+			}
+		}
 		// get package revision resourceList
 		prr := &porchv1alpha1.PackageRevisionResources{}
 		if err := r.porchClient.Get(ctx, req.NamespacedName, prr); err != nil {
@@ -158,114 +191,84 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			log.Error(err, "cannot get resourceList")
 			return ctrl.Result{}, errors.Wrap(err, "cannot get resourceList")
 		}
+		// the hash of the resources the specializers act upon before they run
+		hash := getResourcesHash(getResources(rl.Items, ss))
 
-		if porchcondition.HasSpecificTypeConditions(pr.Status.Conditions, kptfilelibv1.GetConditionType(&ipamFor)) {
+		status := &specializationStatus{}
+		for _, s := range ss {
+			before := getConditions(rl)
 			// run the function SDK
-			_, err = ipamkrmfn.Process(rl)
-			if err != nil {
-				r.recorder.Event(pr, corev1.EventTypeWarning, "ReconcileError", fmt.Sprintf("ipam function: %s", err.Error()))
-				log.Error(err, "ipam function run failed")
-				return ctrl.Result{}, nil
+			_, err := fn.ResourceListProcessorFunc(s.Run).Process(rl)
+			status.Specializers = append(status.Specializers, s.getStatus(rl, err))
+			for _, c := range s.getChangedConditions(before, getConditions(rl)) {
+				r.recorder.Event(pr, corev1.EventTypeNormal, "ConditionChanged", fmt.Sprintf("%s function: condition %s %s: %s", s.name, c.Type, c.Status, c.Message))
 			}
-			log.Info("ipam specializer fn run successful")
-		}
-		if porchcondition.HasSpecificTypeConditions(pr.Status.Conditions, kptfilelibv1.GetConditionType(&vlanFor)) {
-			// run the function SDK
-			_, err = vlankrmfn.Process(rl)
 			if err != nil {
-				r.recorder.Event(pr, corev1.EventTypeWarning, "ReconcileError", fmt.Sprintf("vlan function: %s", err.Error()))
-				log.Error(err, "vlan function run failed")
+				r.recorder.Event(pr, corev1.EventTypeWarning, "ReconcileError", fmt.Sprintf("%s function: %s", s.name, err.Error()))
+				log.Error(err, "specializer fn run failed", "specializer", s.name)
+				if err := r.recordStatus(ctx, pr, status); err != nil {
+					log.Error(err, "cannot record specialization status")
+				}
 				return ctrl.Result{}, nil
 			}
-			log.Info("vlan specializer fn run successful")
+			log.Info("specializer fn run successful", "specializer", s.name)
 		}
-		if porchcondition.HasSpecificTypeConditions(pr.Status.Conditions, kptfilelibv1.GetConditionType(&configInjectFor)) {
-			// run the function SDK
-			_, err = configInjectkrmfn.Process(rl)
-			if err != nil {
-				r.recorder.Event(pr, corev1.EventTypeWarning, "ReconcileError", fmt.Sprintf("configInject function: %s", err.Error()))
-				log.Error(err, "configInject function run failed")
-				return ctrl.Result{}, nil
-			}
-			log.Info("configInject specializer fn run successful")
+		if err := r.recordStatus(ctx, pr, status); err != nil {
+			r.recorder.Event(pr, corev1.EventTypeWarning, "ReconcileError", err.Error())
+			log.Error(err, "cannot record specialization status")
+			return ctrl.Result{}, err
 		}
 		workloadClusterObjs := rl.Items.Where(fn.IsGroupVersionKind(infrav1alpha1.WorkloadClusterGroupVersionKind))
 		clusterName := r.getClusterName(ctx, workloadClusterObjs)
 
+		// writing unchanged resources creates a new revision of the package
+		// and triggers another reconcile, so the update is skipped
+		resources := getResources(rl.Items, ss)
+		if getResourcesHash(resources) == hash {
+			log.Info("package revision resources unchanged, no update needed")
+			return ctrl.Result{}, nil
+		}
+
 		// We want to process the functions to refresh the claims
 		// but if the package is in publish state the updates cannot be done
-		// so we stop here
+		// so we stop here, unless the package refreshes its claims through a
+		// new draft revision
 		if porchv1alpha1.LifecycleIsPublished(pr.Spec.Lifecycle) {
-			r.recorder.Event(pr, corev1.EventTypeNormal, "CannotRefreshClaims", "package is published, no update possible")
-			log.Info("package is published, no updates possible",
-				"repo", pr.Spec.RepositoryName,
-				"package", pr.Spec.PackageName,
-				"rev", pr.Spec.Revision,
-				"clusterName", clusterName,
-			)
+			if !isRefreshPublished(pr) {
+				r.recorder.Event(pr, corev1.EventTypeNormal, "CannotRefreshClaims", "package is published, no update possible")
+				log.Info("package is published, no updates possible",
+					"repo", pr.Spec.RepositoryName,
+					"package", pr.Spec.PackageName,
+					"rev", pr.Spec.Revision,
+					"clusterName", clusterName,
+				)
+				return ctrl.Result{}, nil
+			}
+			draftName, err := r.refreshPublished(ctx, pr, ss, hash, resources)
+			if err != nil {
+				r.recorder.Event(pr, corev1.EventTypeWarning, "ReconcileError", fmt.Sprintf("cannot refresh claims: %s", err.Error()))
+				log.Error(err, "cannot refresh claims of published package")
+				return ctrl.Result{}, err
+			}
+			if draftName != "" {
+				r.recorder.Event(pr, corev1.EventTypeNormal, "RefreshingClaims", fmt.Sprintf("created draft revision %s with the refreshed claims", draftName))
+				log.Info("created draft revision with the refreshed claims", "draft", draftName, "clusterName", clusterName)
+			}
 			return ctrl.Result{}, nil
 		}
 
 		for _, o := range rl.Items {
 			// TBD what if we create new resources
 			// update only the resource we act upon
-			if o.GetAPIVersion() == ipamFor.APIVersion && o.GetKind() == ipamFor.Kind {
-				prr.Spec.Resources[o.GetAnnotation(kioutil.PathAnnotation)] = o.String()
-				// Debug
-				alloc, err := kubeobject.NewFromKubeObject[ipamv1alpha1.IPClaim](o)
-				if err != nil {
-					log.Error(err, "cannot get extended kubeobject")
-					continue
-				}
-				ipAlloc, err := alloc.GetGoStruct()
-				if err != nil {
-					log.Error(err, "cannot get gostruct from kubeobject")
-					continue
-				}
-				log.Info("generic specializer ip allocation", "clusterName", clusterName, "status", ipAlloc.Status)
-			}
-			if o.GetAPIVersion() == vlanFor.APIVersion && o.GetKind() == vlanFor.Kind {
-				prr.Spec.Resources[o.GetAnnotation(kioutil.PathAnnotation)] = o.String()
-				// Debug
-				alloc, err := kubeobject.NewFromKubeObject[vlanv1alpha1.VLANClaim](o)
-				if err != nil {
-					log.Error(err, "cannot get extended kubeobject")
-					continue
-				}
-				vlanAlloc, err := alloc.GetGoStruct()
-				if err != nil {
-					log.Error(err, "cannot get gostruct from kubeobject")
-					continue
-				}
-				log.Info("generic specializer vlan allocation", "clusterName", clusterName, "status", vlanAlloc.Status)
-			}
-			if o.GetAPIVersion() == configInjectFor.APIVersion && o.GetKind() == configInjectFor.Kind {
-				prr.Spec.Resources[o.GetAnnotation(kioutil.PathAnnotation)] = o.String()
-				log.Info("generic specializer config injector", "clusterName", clusterName, "resourceName", fmt.Sprintf("%s/%s", configInjectFor.Kind, o.GetName()))
-			}
-			for own := range configInjectf.GetConfig().Owns {
-				if o.GetAPIVersion() == own.APIVersion && o.GetKind() == own.Kind {
-					if o.GetAnnotation(kioutil.PathAnnotation) == "" {
-						// this is a new resource
-						filename := fmt.Sprintf("%s_%s.yaml", strings.ToLower(own.Kind), o.GetName())
-						if o.GetNamespace() != "" {
-							filename = fmt.Sprintf("%s/%s.yaml", o.GetNamespace(), filename)
-						}
-						prr.Spec.Resources[filename] = o.String()
-					} else {
-						prr.Spec.Resources[o.GetAnnotation(kioutil.PathAnnotation)] = o.String()
-					}
-					log.Info("generic specializer", "kind", own.Kind, "pathAnnotation", o.GetAnnotation(kioutil.PathAnnotation))
-					log.Info("generic specializer config injector", "clusterName", clusterName, "resourceName", fmt.Sprintf("%s/%s", own.Kind, o.GetName()))
-					log.Info("generic specializer config injector", "object", o.String())
+			for _, s := range ss {
+				if s.isFor(o) || s.isOwned(o) {
+					log.Info("generic specializer", "specializer", s.name, "clusterName", clusterName, "resourceName", fmt.Sprintf("%s/%s", o.GetKind(), o.GetName()), "path", getResourcePath(o))
 				}
 			}
 
 			if o.GetAPIVersion() == "kpt.dev/v1" && o.GetKind() == "Kptfile" {
 				log.Info("generic specializer", "pathAnnotation", o.GetAnnotation(kioutil.PathAnnotation), "kptfile", o.String())
-				prr.Spec.Resources[o.GetAnnotation(kioutil.PathAnnotation)] = o.String()
 				// debug
-
 				log.Info("generic specializer object kptfile", "packageName", pr.Spec.PackageName, "repository", pr.Spec.RepositoryName, "kptfile", o)
 				kptf, err := kubeobject.NewFromKubeObject[kptv1.KptFile](o)
 				if err != nil {
@@ -278,10 +281,10 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 					continue
 				}
 				for _, c := range kptfile.Status.Conditions {
-					if strings.HasPrefix(c.Type, kptfilelibv1.GetConditionType(&vlanFor)+".") ||
-						strings.HasPrefix(c.Type, kptfilelibv1.GetConditionType(&ipamFor)+".") ||
-						strings.HasPrefix(c.Type, kptfilelibv1.GetConditionType(&configInjectFor)+".") {
-						log.Info("generic specializer conditions", "packageName", pr.Spec.PackageName, "repository", pr.Spec.RepositoryName, "status", c.Status, "condition", c.Type, "message", c.Message)
+					for _, s := range ss {
+						if s.hasCondition(c.Type) {
+							log.Info("generic specializer conditions", "packageName", pr.Spec.PackageName, "repository", pr.Spec.RepositoryName, "status", c.Status, "condition", c.Type, "message", c.Message)
+						}
 					}
 				}
 			}
@@ -296,10 +299,9 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 
 		log.Info("generic specializer root kptfile", "packageName", pr.Spec.PackageName, "repository", pr.Spec.RepositoryName, "kptfile", kptfile)
 
-		kptf := kptfilelibv1.KptFile{Kptfile: rl.Items.GetRootKptfile()}
-		pr.Status.Conditions = porchcondition.GetPorchConditions(kptf.GetConditions())
-		// TODO do we need to update the status?
-		if err = r.porchClient.Update(ctx, prr); err != nil {
+		// porch reflects the conditions of the Kptfile in the status of the
+		// PackageRevision
+		if err = r.updateResources(ctx, prr, ss, hash, resources); err != nil {
 			r.recorder.Event(pr, corev1.EventTypeWarning, "ReconcileError", "cannot update packagerevision resources")
 			log.Error(err, "cannot update packagerevision resources")
 			return ctrl.Result{}, err
@@ -0,0 +1,108 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package genericspecializer
+
+import (
+	"context"
+
+	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// RefreshPublishedAnnotationName opts a published package in to refresh
+	// its claims through a new draft revision of the package
+	RefreshPublishedAnnotationName = "specializer.nephio.org/refresh-published"
+)
+
+// isRefreshPublished returns true when the published package refreshes its
+// claims through a new draft revision
+func isRefreshPublished(pr *porchv1alpha1.PackageRevision) bool {
+	return pr.GetAnnotations()[RefreshPublishedAnnotationName] == "true"
+}
+
+// getRefreshWorkspaceName returns the workspace of the draft revision with the
+// refreshed resources, the same refresh always gets the same workspace
+func getRefreshWorkspaceName(resources map[string]string) porchv1alpha1.WorkspaceName {
+	return porchv1alpha1.WorkspaceName("refresh-" + getResourcesHash(resources)[:8])
+}
+
+// hasDraft returns true when the package of the package revision has a
+// revision which is not published yet
+func (r *reconciler) hasDraft(ctx context.Context, pr *porchv1alpha1.PackageRevision) (bool, error) {
+	prl := &porchv1alpha1.PackageRevisionList{}
+	if err := r.porchClient.List(ctx, prl, client.InNamespace(pr.GetNamespace())); err != nil {
+		return false, errors.Wrap(err, "cannot list package revisions")
+	}
+	for _, other := range prl.Items {
+		if other.Spec.RepositoryName == pr.Spec.RepositoryName &&
+			other.Spec.PackageName == pr.Spec.PackageName &&
+			!porchv1alpha1.LifecycleIsPublished(other.Spec.Lifecycle) &&
+			!resource.WasDeleted(&other) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// refreshPublished creates a new draft revision, a copy of the published
+// package revision, with the resources refreshed by the specializers. Only
+// the latest revision of a package is refreshed and only when the package has
+// no draft revision, the draft revision is specialized itself. It returns the
+// name of the created draft revision.
+func (r *reconciler) refreshPublished(ctx context.Context, pr *porchv1alpha1.PackageRevision, ss []*specializer, hash string, resources map[string]string) (string, error) {
+	if pr.GetLabels()[porchv1alpha1.LatestPackageRevisionKey] != porchv1alpha1.LatestPackageRevisionValue {
+		return "", nil
+	}
+	hasDraft, err := r.hasDraft(ctx, pr)
+	if err != nil || hasDraft {
+		return "", err
+	}
+
+	draft := &porchv1alpha1.PackageRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: pr.GetNamespace(),
+		},
+		Spec: porchv1alpha1.PackageRevisionSpec{
+			PackageName:    pr.Spec.PackageName,
+			RepositoryName: pr.Spec.RepositoryName,
+			WorkspaceName:  getRefreshWorkspaceName(resources),
+			Lifecycle:      porchv1alpha1.PackageRevisionLifecycleDraft,
+			Tasks: []porchv1alpha1.Task{{
+				Type: porchv1alpha1.TaskTypeEdit,
+				Edit: &porchv1alpha1.PackageEditTaskSpec{
+					Source: &porchv1alpha1.PackageRevisionRef{Name: pr.GetName()},
+				},
+			}},
+		},
+	}
+	if err := r.porchClient.Create(ctx, draft); err != nil {
+		return "", errors.Wrap(err, "cannot create draft revision")
+	}
+
+	prr := &porchv1alpha1.PackageRevisionResources{}
+	if err := r.porchClient.Get(ctx, client.ObjectKeyFromObject(draft), prr); err != nil {
+		return "", errors.Wrap(err, "cannot get package revision resources")
+	}
+	if err := r.updateResources(ctx, prr, ss, hash, resources); err != nil {
+		return "", errors.Wrap(err, "cannot update package revision resources")
+	}
+	return draft.GetName(), nil
+}
@@ -0,0 +1,166 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package genericspecializer
+
+import (
+	"context"
+	"testing"
+
+	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	"github.com/nephio-project/nephio/krm-functions/lib/kptrl"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestRefreshPublished(t *testing.T) {
+	ss := []*specializer{{
+		name:          "test-a",
+		cfg:           newTestSpecializer("A")(nil).GetConfig(),
+		conditionType: "test.nephio.org/v1alpha1.A",
+	}}
+	refreshed := map[string]string{"a.yaml": "refreshed"}
+
+	cases := map[string]struct {
+		notLatest bool
+		lifecycle porchv1alpha1.PackageRevisionLifecycle
+		wantDraft bool
+	}{
+		"Latest": {
+			wantDraft: true,
+		},
+		"NotLatest": {
+			notLatest: true,
+		},
+		"HasDraft": {
+			lifecycle: porchv1alpha1.PackageRevisionLifecycleProposed,
+		},
+		"HasPublished": {
+			lifecycle: porchv1alpha1.PackageRevisionLifecyclePublished,
+			wantDraft: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			scheme := runtime.NewScheme()
+			if err := porchv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+			pr := &porchv1alpha1.PackageRevision{
+				ObjectMeta: metav1.ObjectMeta{Name: "pr-v2", Namespace: "default"},
+				Spec: porchv1alpha1.PackageRevisionSpec{
+					RepositoryName: "repo",
+					PackageName:    "pkg",
+					Lifecycle:      porchv1alpha1.PackageRevisionLifecyclePublished,
+				},
+			}
+			if !tc.notLatest {
+				pr.SetLabels(map[string]string{porchv1alpha1.LatestPackageRevisionKey: porchv1alpha1.LatestPackageRevisionValue})
+			}
+			prr := &porchv1alpha1.PackageRevisionResources{
+				ObjectMeta: metav1.ObjectMeta{Name: "pr-v2", Namespace: "default"},
+				Spec: porchv1alpha1.PackageRevisionResourcesSpec{
+					Resources: map[string]string{"Kptfile": testKptfile, "a.yaml": testResource},
+				},
+			}
+			objs := []client.Object{pr, prr}
+			if tc.lifecycle != "" {
+				objs = append(objs, &porchv1alpha1.PackageRevision{
+					ObjectMeta: metav1.ObjectMeta{Name: "pr-v1", Namespace: "default"},
+					Spec: porchv1alpha1.PackageRevisionSpec{
+						RepositoryName: "repo",
+						PackageName:    "pkg",
+						Lifecycle:      tc.lifecycle,
+					},
+				})
+			}
+
+			// porch names the draft revision and copies the resources of the
+			// source revision
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).WithInterceptorFuncs(interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					draft := obj.(*porchv1alpha1.PackageRevision)
+					draft.SetName("pr-" + string(draft.Spec.WorkspaceName))
+					if err := c.Create(ctx, draft, opts...); err != nil {
+						return err
+					}
+					draftPRR := prr.DeepCopy()
+					draftPRR.ObjectMeta = metav1.ObjectMeta{Name: draft.GetName(), Namespace: draft.GetNamespace()}
+					return c.Create(ctx, draftPRR)
+				},
+			}).Build()
+			r := &reconciler{Client: c, porchClient: c}
+
+			rl, err := kptrl.GetResourceList(prr.Spec.Resources)
+			if err != nil {
+				t.Fatal(err)
+			}
+			hash := getResourcesHash(getResources(rl.Items, ss))
+
+			draftName, err := r.refreshPublished(ctx, pr, ss, hash, refreshed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (draftName != "") != tc.wantDraft {
+				t.Fatalf("want draft %t, got %q", tc.wantDraft, draftName)
+			}
+			if !tc.wantDraft {
+				return
+			}
+
+			draft := &porchv1alpha1.PackageRevision{}
+			if err := c.Get(ctx, client.ObjectKey{Namespace: "default", Name: draftName}, draft); err != nil {
+				t.Fatal(err)
+			}
+			wantSpec := porchv1alpha1.PackageRevisionSpec{
+				PackageName:    "pkg",
+				RepositoryName: "repo",
+				WorkspaceName:  getRefreshWorkspaceName(refreshed),
+				Lifecycle:      porchv1alpha1.PackageRevisionLifecycleDraft,
+				Tasks: []porchv1alpha1.Task{{
+					Type: porchv1alpha1.TaskTypeEdit,
+					Edit: &porchv1alpha1.PackageEditTaskSpec{
+						Source: &porchv1alpha1.PackageRevisionRef{Name: "pr-v2"},
+					},
+				}},
+			}
+			if diff := cmp.Diff(wantSpec, draft.Spec); diff != "" {
+				t.Errorf("spec -want, +got:\n%s", diff)
+			}
+			draftPRR := &porchv1alpha1.PackageRevisionResources{}
+			if err := c.Get(ctx, client.ObjectKey{Namespace: "default", Name: draftName}, draftPRR); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff("refreshed", draftPRR.Spec.Resources["a.yaml"]); diff != "" {
+				t.Errorf("resources -want, +got:\n%s", diff)
+			}
+			// the published revision is not changed
+			got := &porchv1alpha1.PackageRevisionResources{}
+			if err := c.Get(ctx, client.ObjectKeyFromObject(prr), got); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(prr.Spec.Resources, got.Spec.Resources); diff != "" {
+				t.Errorf("published resources -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
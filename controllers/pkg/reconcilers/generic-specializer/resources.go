@@ -0,0 +1,131 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package genericspecializer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/nephio-project/nephio/krm-functions/lib/kptrl"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// getResources returns the resources of the package the specializers write
+// back per path: the For and Owns resources of the specializers and the
+// Kptfiles
+func getResources(objs fn.KubeObjects, ss []*specializer) map[string]string {
+	resources := map[string]string{}
+	for _, o := range objs {
+		if o.GetAPIVersion() == "kpt.dev/v1" && o.GetKind() == "Kptfile" {
+			resources[getResourcePath(o)] = o.String()
+			continue
+		}
+		for _, s := range ss {
+			if s.isFor(o) || s.isOwned(o) {
+				resources[getResourcePath(o)] = o.String()
+				break
+			}
+		}
+	}
+	return resources
+}
+
+// volatileStatusFields are the fields of the status that a run of the
+// specializers may change without changing the package, e.g. the expiry of
+// a claim that is renewed on every run
+var volatileStatusFields = []string{"expiryTime"}
+
+// getResourcesHash returns a canonical hash of the resources, the resources
+// are hashed as json without their volatile status fields so the hash does
+// not depend on the formatting of the files in the package or on the time
+// the specializers ran
+func getResourcesHash(resources map[string]string) string {
+	paths := make([]string, 0, len(resources))
+	for path := range resources {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	h := sha256.New()
+	for _, path := range paths {
+		h.Write([]byte(path))
+		h.Write([]byte{0})
+		h.Write(getCanonicalResource(resources[path]))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// getCanonicalResource returns the resource as json without the volatile
+// fields of its status and the transition times of its conditions
+func getCanonicalResource(resource string) []byte {
+	o := map[string]any{}
+	if err := yaml.Unmarshal([]byte(resource), &o); err != nil {
+		return []byte(resource)
+	}
+	if status, ok := o["status"].(map[string]any); ok {
+		for _, field := range volatileStatusFields {
+			delete(status, field)
+		}
+		if conditions, ok := status["conditions"].([]any); ok {
+			for _, c := range conditions {
+				if c, ok := c.(map[string]any); ok {
+					delete(c, "lastTransitionTime")
+				}
+			}
+		}
+	}
+	b, err := json.Marshal(o)
+	if err != nil {
+		return []byte(resource)
+	}
+	return b
+}
+
+// updateResources writes the resources the specializers changed in the
+// package. On a conflict the package is read again and the update is retried
+// as long as the resources the specializers ran on were not changed in the
+// meantime, otherwise the specializers have to run again.
+func (r *reconciler) updateResources(ctx context.Context, prr *porchv1alpha1.PackageRevisionResources, ss []*specializer, hash string, resources map[string]string) error {
+	first := true
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if !first {
+			if err := r.porchClient.Get(ctx, client.ObjectKeyFromObject(prr), prr); err != nil {
+				return errors.Wrap(err, "cannot get package revision resources")
+			}
+			rl, err := kptrl.GetResourceList(prr.Spec.Resources)
+			if err != nil {
+				return errors.Wrap(err, "cannot get resourceList")
+			}
+			if getResourcesHash(getResources(rl.Items, ss)) != hash {
+				return errors.New("package revision resources changed while specializing")
+			}
+		}
+		first = false
+		for path, resource := range resources {
+			prr.Spec.Resources[path] = resource
+		}
+		return r.porchClient.Update(ctx, prr)
+	})
+}
@@ -0,0 +1,223 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package genericspecializer
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	"github.com/nephio-project/nephio/krm-functions/lib/kptrl"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testOther = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: other
+`
+
+func TestGetResources(t *testing.T) {
+	ss := []*specializer{{
+		name:          "test-a",
+		cfg:           newTestSpecializer("A")(nil).GetConfig(),
+		conditionType: "test.nephio.org/v1alpha1.A",
+	}}
+	rl, err := kptrl.GetResourceList(map[string]string{
+		"Kptfile":    testKptfile,
+		"a.yaml":     testResource,
+		"other.yaml": testOther,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for path := range getResources(rl.Items, ss) {
+		got = append(got, path)
+	}
+	sort.Strings(got)
+	if diff := cmp.Diff([]string{"Kptfile", "a.yaml"}, got); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+}
+
+func TestGetResourcesHash(t *testing.T) {
+	ss := []*specializer{{
+		name:          "test-a",
+		cfg:           newTestSpecializer("A")(nil).GetConfig(),
+		conditionType: "test.nephio.org/v1alpha1.A",
+	}}
+	// the same resources formatted differently
+	reformatted := `apiVersion:   test.nephio.org/v1alpha1
+kind: A
+metadata: {name: a1}
+status:
+  prefix: "10.0.0.0/24"
+  vlanID: 10
+  expiryTime: "2023-06-01T00:00:00Z"
+  conditions:
+  - {type: Ready, status: "True"}
+`
+	// only the volatile status fields changed
+	renewed := `apiVersion: test.nephio.org/v1alpha1
+kind: A
+metadata:
+  name: a1
+status:
+  prefix: 10.0.0.0/24
+  vlanID: 10
+  expiryTime: "2023-06-02T00:00:00Z"
+  conditions:
+  - type: Ready
+    status: "True"
+    lastTransitionTime: "2023-06-01T00:00:00Z"
+`
+	changed := `apiVersion: test.nephio.org/v1alpha1
+kind: A
+metadata:
+  name: a1
+status:
+  prefix: 10.0.1.0/24
+`
+	hash := func(resources map[string]string) string {
+		rl, err := kptrl.GetResourceList(resources)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return getResourcesHash(getResources(rl.Items, ss))
+	}
+	want := hash(map[string]string{"Kptfile": testKptfile, "a.yaml": testResource})
+
+	cases := map[string]struct {
+		resources map[string]string
+		wantEqual bool
+	}{
+		"Reformatted": {
+			resources: map[string]string{"Kptfile": testKptfile, "a.yaml": reformatted},
+			wantEqual: true,
+		},
+		"Renewed": {
+			resources: map[string]string{"Kptfile": testKptfile, "a.yaml": renewed},
+			wantEqual: true,
+		},
+		"OtherResource": {
+			resources: map[string]string{"Kptfile": testKptfile, "a.yaml": testResource, "other.yaml": testOther},
+			wantEqual: true,
+		},
+		"Changed": {
+			resources: map[string]string{"Kptfile": testKptfile, "a.yaml": changed},
+		},
+		"Moved": {
+			resources: map[string]string{"Kptfile": testKptfile, "b.yaml": testResource},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := hash(tc.resources) == want; got != tc.wantEqual {
+				t.Errorf("want equal hash %t, got %t", tc.wantEqual, got)
+			}
+		})
+	}
+}
+
+func TestUpdateResources(t *testing.T) {
+	ss := []*specializer{{
+		name:          "test-a",
+		cfg:           newTestSpecializer("A")(nil).GetConfig(),
+		conditionType: "test.nephio.org/v1alpha1.A",
+	}}
+	resources := map[string]string{"Kptfile": testKptfile, "a.yaml": testResource}
+	updated := map[string]string{"a.yaml": "updated"}
+
+	cases := map[string]struct {
+		// concurrent is the update of the package by others
+		concurrent map[string]string
+		wantErr    bool
+		want       map[string]string
+	}{
+		"Updated": {
+			want: map[string]string{"Kptfile": testKptfile, "a.yaml": "updated"},
+		},
+		"ConflictOtherResource": {
+			concurrent: map[string]string{"other.yaml": testOther},
+			want:       map[string]string{"Kptfile": testKptfile, "a.yaml": "updated", "other.yaml": testOther},
+		},
+		"ConflictSpecializedResource": {
+			concurrent: map[string]string{"a.yaml": testOther},
+			wantErr:    true,
+			want:       map[string]string{"Kptfile": testKptfile, "a.yaml": testOther},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			scheme := runtime.NewScheme()
+			if err := porchv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+			existing := &porchv1alpha1.PackageRevisionResources{
+				ObjectMeta: metav1.ObjectMeta{Name: "pr", Namespace: "default"},
+				Spec:       porchv1alpha1.PackageRevisionResourcesSpec{Resources: map[string]string{}},
+			}
+			for path, resource := range resources {
+				existing.Spec.Resources[path] = resource
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).Build()
+			r := &reconciler{Client: c, porchClient: c}
+
+			prr := &porchv1alpha1.PackageRevisionResources{}
+			if err := c.Get(ctx, client.ObjectKeyFromObject(existing), prr); err != nil {
+				t.Fatal(err)
+			}
+			rl, err := kptrl.GetResourceList(prr.Spec.Resources)
+			if err != nil {
+				t.Fatal(err)
+			}
+			hash := getResourcesHash(getResources(rl.Items, ss))
+
+			if tc.concurrent != nil {
+				other := prr.DeepCopy()
+				for path, resource := range tc.concurrent {
+					other.Spec.Resources[path] = resource
+				}
+				if err := c.Update(ctx, other); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err = r.updateResources(ctx, prr, ss, hash, updated)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("want error %t, got %v", tc.wantErr, err)
+			}
+
+			got := &porchv1alpha1.PackageRevisionResources{}
+			if err := c.Get(ctx, client.ObjectKeyFromObject(existing), got); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got.Spec.Resources); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
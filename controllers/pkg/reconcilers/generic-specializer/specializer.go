@@ -0,0 +1,162 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package genericspecializer
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	porchcondition "github.com/nephio-project/nephio/controllers/pkg/porch/condition"
+	ctrlconfig "github.com/nephio-project/nephio/controllers/pkg/reconcilers/config"
+	reconcilerinterface "github.com/nephio-project/nephio/controllers/pkg/reconcilers/reconciler-interface"
+	"github.com/nephio-project/nephio/krm-functions/lib/condkptsdk"
+	kptfilelibv1 "github.com/nephio-project/nephio/krm-functions/lib/kptfile/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+)
+
+// Specializer is a condkptsdk based KRM function the generic specializer runs
+// in the controller. The function runs on the packages with a condition of
+// the For resource of its config; the For and Owns resources are written back
+// to the package.
+type Specializer interface {
+	GetConfig() condkptsdk.Config
+	Run(rl *fn.ResourceList) (bool, error)
+}
+
+// SpecializerFactory returns a new specializer, a specializer is created per
+// reconcile as the functions keep state while they run
+type SpecializerFactory func(cfg *ctrlconfig.ControllerConfig) Specializer
+
+type registeredSpecializer struct {
+	name    string
+	factory SpecializerFactory
+}
+
+var (
+	specializersLock sync.RWMutex
+	// specializers in the order they are registered, which is the order they
+	// run in
+	specializers = []registeredSpecializer{}
+)
+
+// RegisterSpecializer registers a specializer with the generic specializer,
+// registering a name again replaces the specializer. The specializer is also
+// registered as a reconciler of its own, named <name>specializer in lower
+// case, which runs the specializer in a dedicated controller.
+func RegisterSpecializer(name string, f SpecializerFactory) {
+	reconcilerinterface.Register(getReconcilerName(name), &reconciler{specializer: name})
+
+	specializersLock.Lock()
+	defer specializersLock.Unlock()
+	for i, s := range specializers {
+		if s.name == name {
+			specializers[i].factory = f
+			return
+		}
+	}
+	specializers = append(specializers, registeredSpecializer{name: name, factory: f})
+}
+
+// getReconcilerName returns the name of the reconciler running the
+// specializer in a dedicated controller
+func getReconcilerName(name string) string {
+	return strings.ToLower(name) + "specializer"
+}
+
+// getFactory returns the factory of the registered specializer
+func getFactory(name string) (SpecializerFactory, bool) {
+	specializersLock.RLock()
+	defer specializersLock.RUnlock()
+	for _, s := range specializers {
+		if s.name == name {
+			return s.factory, true
+		}
+	}
+	return nil, false
+}
+
+// specializer is a registered specializer created for a reconcile
+type specializer struct {
+	name string
+	Specializer
+	cfg           condkptsdk.Config
+	conditionType string
+}
+
+// getSpecializers returns the registered specializers that have a condition
+// on the package revision, or only the named specializer when a name is
+// provided. Specializers run whether their conditions are satisfied or not,
+// which refreshes their claims.
+func getSpecializers(cfg *ctrlconfig.ControllerConfig, pr *porchv1alpha1.PackageRevision, name string) []*specializer {
+	specializersLock.RLock()
+	defer specializersLock.RUnlock()
+	ss := []*specializer{}
+	for _, s := range specializers {
+		if name != "" && s.name != name {
+			continue
+		}
+		f := s.factory(cfg)
+		sdkCfg := f.GetConfig()
+		conditionType := kptfilelibv1.GetConditionType(&sdkCfg.For)
+		if porchcondition.HasSpecificTypeConditions(pr.Status.Conditions, conditionType) {
+			ss = append(ss, &specializer{name: s.name, Specializer: f, cfg: sdkCfg, conditionType: conditionType})
+		}
+	}
+	return ss
+}
+
+func isResource(o *fn.KubeObject, ref corev1.ObjectReference) bool {
+	return o.GetAPIVersion() == ref.APIVersion && o.GetKind() == ref.Kind
+}
+
+// isFor returns true when the object is the For resource of the specializer
+func (r *specializer) isFor(o *fn.KubeObject) bool {
+	return isResource(o, r.cfg.For)
+}
+
+// isOwned returns true when the object is an Owns resource of the specializer
+func (r *specializer) isOwned(o *fn.KubeObject) bool {
+	for own := range r.cfg.Owns {
+		if isResource(o, own) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasCondition returns true when the condition type is a condition of the
+// specializer
+func (r *specializer) hasCondition(conditionType string) bool {
+	return strings.HasPrefix(conditionType, r.conditionType+".")
+}
+
+// getResourcePath returns the path of the object in the package, an object
+// created by a specializer gets a new file
+func getResourcePath(o *fn.KubeObject) string {
+	if path := o.GetAnnotation(kioutil.PathAnnotation); path != "" {
+		return path
+	}
+	filename := fmt.Sprintf("%s_%s.yaml", strings.ToLower(o.GetKind()), o.GetName())
+	if o.GetNamespace() != "" {
+		filename = fmt.Sprintf("%s/%s", o.GetNamespace(), filename)
+	}
+	return filename
+}
@@ -0,0 +1,252 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package genericspecializer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	ctrlconfig "github.com/nephio-project/nephio/controllers/pkg/reconcilers/config"
+	reconcilerinterface "github.com/nephio-project/nephio/controllers/pkg/reconcilers/reconciler-interface"
+	"github.com/nephio-project/nephio/krm-functions/lib/condkptsdk"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+)
+
+type testSpecializer struct {
+	cfg condkptsdk.Config
+}
+
+func (r *testSpecializer) GetConfig() condkptsdk.Config { return r.cfg }
+
+func (r *testSpecializer) Run(rl *fn.ResourceList) (bool, error) { return true, nil }
+
+func newTestSpecializer(kind string) SpecializerFactory {
+	return func(cfg *ctrlconfig.ControllerConfig) Specializer {
+		return &testSpecializer{cfg: condkptsdk.Config{
+			For: corev1.ObjectReference{APIVersion: "test.nephio.org/v1alpha1", Kind: kind},
+			Owns: map[corev1.ObjectReference]condkptsdk.ResourceKind{
+				{APIVersion: "test.nephio.org/v1alpha1", Kind: kind + "Claim"}: condkptsdk.ChildRemote,
+			},
+		}}
+	}
+}
+
+// unregisterTestSpecializers removes the specializers registered by a test
+func unregisterTestSpecializers(t *testing.T, names ...string) {
+	unregister := map[string]struct{}{}
+	for _, name := range names {
+		unregister[name] = struct{}{}
+	}
+	t.Cleanup(func() {
+		specializersLock.Lock()
+		defer specializersLock.Unlock()
+		ss := []registeredSpecializer{}
+		for _, s := range specializers {
+			if _, ok := unregister[s.name]; !ok {
+				ss = append(ss, s)
+			}
+		}
+		specializers = ss
+		for _, name := range names {
+			delete(reconcilerinterface.Reconcilers, strings.ToLower(name)+"specializer")
+		}
+	})
+}
+
+func TestRegisterSpecializer(t *testing.T) {
+	for name, specializer := range map[string]string{
+		"ipamspecializer":         "ipam",
+		"vlanspecializer":         "vlan",
+		"configinjectspecializer": "configInject",
+	} {
+		r, ok := reconcilerinterface.Reconcilers[name].(*reconciler)
+		if !ok {
+			t.Errorf("want reconciler %s registered", name)
+			continue
+		}
+		if r.specializer != specializer {
+			t.Errorf("reconciler %s: want specializer %s, got %s", name, specializer, r.specializer)
+		}
+	}
+}
+
+func TestSetupSpecializer(t *testing.T) {
+	r := &reconciler{specializer: "ipam"}
+	cfg := &ctrlconfig.ControllerConfig{EnabledReconcilers: []string{"ipamspecializer", reconcilerName}}
+	// the generic specializer runs the specializer, the manager is not used
+	if err := r.setupSpecializer(context.Background(), nil, cfg); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if r.recorder != nil {
+		t.Errorf("want the dedicated controller not set up while the generic specializer is enabled")
+	}
+}
+
+func TestGetSpecializers(t *testing.T) {
+	RegisterSpecializer("test-a", newTestSpecializer("A"))
+	RegisterSpecializer("test-b", newTestSpecializer("Dummy"))
+	// registering a name again replaces the specializer, but keeps its order
+	RegisterSpecializer("test-b", newTestSpecializer("B"))
+	unregisterTestSpecializers(t, "test-a", "test-b")
+
+	cases := map[string]struct {
+		conditions  []porchv1alpha1.Condition
+		specializer string
+		want        []string
+	}{
+		"NoConditions": {
+			conditions: nil,
+			want:       []string{},
+		},
+		"Single": {
+			conditions: []porchv1alpha1.Condition{
+				{Type: "test.nephio.org/v1alpha1.B.b1", Status: porchv1alpha1.ConditionFalse},
+			},
+			want: []string{"test-b"},
+		},
+		"RegistrationOrder": {
+			conditions: []porchv1alpha1.Condition{
+				{Type: "test.nephio.org/v1alpha1.B.b1", Status: porchv1alpha1.ConditionTrue},
+				{Type: "test.nephio.org/v1alpha1.A.a1", Status: porchv1alpha1.ConditionFalse},
+			},
+			want: []string{"test-a", "test-b"},
+		},
+		"OtherKind": {
+			conditions: []porchv1alpha1.Condition{
+				{Type: "test.nephio.org/v1alpha1.Dummy.d1", Status: porchv1alpha1.ConditionFalse},
+			},
+			want: []string{},
+		},
+		"Dedicated": {
+			conditions: []porchv1alpha1.Condition{
+				{Type: "test.nephio.org/v1alpha1.B.b1", Status: porchv1alpha1.ConditionTrue},
+				{Type: "test.nephio.org/v1alpha1.A.a1", Status: porchv1alpha1.ConditionFalse},
+			},
+			specializer: "test-b",
+			want:        []string{"test-b"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			pr := &porchv1alpha1.PackageRevision{
+				Status: porchv1alpha1.PackageRevisionStatus{Conditions: tc.conditions},
+			}
+			got := []string{}
+			for _, s := range getSpecializers(&ctrlconfig.ControllerConfig{}, pr, tc.specializer) {
+				if s.name == "test-a" || s.name == "test-b" {
+					got = append(got, s.name)
+				}
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSpecializerResources(t *testing.T) {
+	s := &specializer{
+		cfg:           newTestSpecializer("A")(nil).GetConfig(),
+		conditionType: "test.nephio.org/v1alpha1.A",
+	}
+
+	cases := map[string]struct {
+		kind      string
+		wantFor   bool
+		wantOwned bool
+	}{
+		"For":   {kind: "A", wantFor: true},
+		"Owns":  {kind: "AClaim", wantOwned: true},
+		"Other": {kind: "B"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			o := fn.NewEmptyKubeObject()
+			if err := o.SetAPIVersion("test.nephio.org/v1alpha1"); err != nil {
+				t.Fatal(err)
+			}
+			if err := o.SetKind(tc.kind); err != nil {
+				t.Fatal(err)
+			}
+			if got := s.isFor(o); got != tc.wantFor {
+				t.Errorf("isFor: want %t, got %t", tc.wantFor, got)
+			}
+			if got := s.isOwned(o); got != tc.wantOwned {
+				t.Errorf("isOwned: want %t, got %t", tc.wantOwned, got)
+			}
+		})
+	}
+
+	if !s.hasCondition("test.nephio.org/v1alpha1.A.a1") {
+		t.Errorf("want condition of the specializer")
+	}
+	if s.hasCondition("test.nephio.org/v1alpha1.AClaim.a1") {
+		t.Errorf("want no condition of the specializer")
+	}
+}
+
+func TestGetResourcePath(t *testing.T) {
+	cases := map[string]struct {
+		path      string
+		namespace string
+		want      string
+	}{
+		"Path": {
+			path: "ipclaim.yaml",
+			want: "ipclaim.yaml",
+		},
+		"ClusterScoped": {
+			want: "ipclaim_a.yaml",
+		},
+		"Namespaced": {
+			namespace: "default",
+			want:      "default/ipclaim_a.yaml",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			o := fn.NewEmptyKubeObject()
+			if err := o.SetKind("IPClaim"); err != nil {
+				t.Fatal(err)
+			}
+			if err := o.SetName("a"); err != nil {
+				t.Fatal(err)
+			}
+			if tc.namespace != "" {
+				if err := o.SetNamespace(tc.namespace); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tc.path != "" {
+				if err := o.SetAnnotation(kioutil.PathAnnotation, tc.path); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := getResourcePath(o); got != tc.want {
+				t.Errorf("want %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
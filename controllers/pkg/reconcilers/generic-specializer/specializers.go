@@ -0,0 +1,79 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package genericspecializer
+
+import (
+	"context"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	ctrlconfig "github.com/nephio-project/nephio/controllers/pkg/reconcilers/config"
+	configinjectfn "github.com/nephio-project/nephio/krm-functions/configinject-fn/fn"
+	ipamfn "github.com/nephio-project/nephio/krm-functions/ipam-fn/fn"
+	"github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
+	vlanfn "github.com/nephio-project/nephio/krm-functions/vlan-fn/fn"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
+	vlanv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/vlan/v1alpha1"
+	"github.com/nokia/k8s-ipam/pkg/proxy/clientproxy"
+)
+
+func init() {
+	RegisterSpecializer("ipam", func(cfg *ctrlconfig.ControllerConfig) Specializer {
+		return &ipamSpecializer{FnR: ipamfn.New(cfg.IpamClientProxy), proxy: cfg.IpamClientProxy}
+	})
+	RegisterSpecializer("vlan", func(cfg *ctrlconfig.ControllerConfig) Specializer {
+		return &vlanSpecializer{FnR: vlanfn.New(cfg.VlanClientProxy), proxy: cfg.VlanClientProxy}
+	})
+	RegisterSpecializer("configInject", func(cfg *ctrlconfig.ControllerConfig) Specializer {
+		return configinjectfn.New(cfg.PorchClient)
+	})
+}
+
+// ipamSpecializer releases the ip claims of deleted packages
+type ipamSpecializer struct {
+	*ipamfn.FnR
+	proxy clientproxy.Proxy[*ipamv1alpha1.NetworkInstance, *ipamv1alpha1.IPClaim]
+}
+
+func (r *ipamSpecializer) Release(ctx context.Context, o *fn.KubeObject) error {
+	claimKOE, err := kubeobject.NewFromKubeObject[ipamv1alpha1.IPClaim](o)
+	if err != nil {
+		return err
+	}
+	claim, err := claimKOE.GetGoStruct()
+	if err != nil {
+		return err
+	}
+	return r.proxy.DeleteClaim(ctx, claim, nil)
+}
+
+// vlanSpecializer releases the vlan claims of deleted packages
+type vlanSpecializer struct {
+	*vlanfn.FnR
+	proxy clientproxy.Proxy[*vlanv1alpha1.VLANIndex, *vlanv1alpha1.VLANClaim]
+}
+
+func (r *vlanSpecializer) Release(ctx context.Context, o *fn.KubeObject) error {
+	claimKOE, err := kubeobject.NewFromKubeObject[vlanv1alpha1.VLANClaim](o)
+	if err != nil {
+		return err
+	}
+	claim, err := claimKOE.GetGoStruct()
+	if err != nil {
+		return err
+	}
+	return r.proxy.DeleteClaim(ctx, claim, nil)
+}
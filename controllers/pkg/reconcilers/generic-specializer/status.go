@@ -0,0 +1,268 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package genericspecializer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	kptv1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	kptfilelibv1 "github.com/nephio-project/nephio/krm-functions/lib/kptfile/v1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// StatusAnnotationName records the specializationStatus on the
+	// PackageRevision
+	StatusAnnotationName = "specializer.nephio.org/status"
+)
+
+// ignoredStatusFields are the status fields of a resource which are not
+// allocation results, the expiry time changes on every refresh of a claim
+var ignoredStatusFields = map[string]struct{}{
+	"conditions": {},
+	"expiryTime": {},
+}
+
+// specializationStatus is the result of the last run of the specializers on a
+// package
+type specializationStatus struct {
+	Specializers []specializerStatus `json:"specializers"`
+}
+
+// specializerStatus is the result of a specializer run, the specializer is
+// ready when it ran without error and all its conditions are true
+type specializerStatus struct {
+	Name       string            `json:"name"`
+	Ready      bool              `json:"ready"`
+	Error      string            `json:"error,omitempty"`
+	Conditions []conditionStatus `json:"conditions,omitempty"`
+	Resources  []resourceStatus  `json:"resources,omitempty"`
+}
+
+// conditionStatus is a condition of a specializer in the Kptfile
+type conditionStatus struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// resourceStatus are the allocation results of a For resource
+type resourceStatus struct {
+	Kind   string            `json:"kind"`
+	Name   string            `json:"name"`
+	Status map[string]string `json:"status,omitempty"`
+}
+
+// getConditions returns the conditions of the root Kptfile of the package
+func getConditions(rl *fn.ResourceList) []kptv1.Condition {
+	kptfile := rl.Items.GetRootKptfile()
+	if kptfile == nil {
+		return nil
+	}
+	kptf := kptfilelibv1.KptFile{Kptfile: kptfile}
+	return kptf.GetConditions()
+}
+
+// getStatus returns the result of the run of the specializer on the package
+func (r *specializer) getStatus(rl *fn.ResourceList, err error) specializerStatus {
+	status := specializerStatus{Name: r.name, Ready: err == nil}
+	if err != nil {
+		status.Error = err.Error()
+	}
+	for _, c := range getConditions(rl) {
+		if !r.hasCondition(c.Type) {
+			continue
+		}
+		status.Conditions = append(status.Conditions, conditionStatus{
+			Type:    c.Type,
+			Status:  string(c.Status),
+			Message: c.Message,
+		})
+		if c.Status != kptv1.ConditionTrue {
+			status.Ready = false
+		}
+	}
+	for _, o := range rl.Items {
+		if r.isFor(o) {
+			status.Resources = append(status.Resources, resourceStatus{
+				Kind:   o.GetKind(),
+				Name:   o.GetName(),
+				Status: getAllocationResults(o),
+			})
+		}
+	}
+	return status
+}
+
+// getChangedConditions returns the conditions of the specializer changed by
+// its run
+func (r *specializer) getChangedConditions(before, after []kptv1.Condition) []conditionStatus {
+	existing := map[string]kptv1.Condition{}
+	for _, c := range before {
+		existing[c.Type] = c
+	}
+	changed := []conditionStatus{}
+	for _, c := range after {
+		if !r.hasCondition(c.Type) {
+			continue
+		}
+		if ec, ok := existing[c.Type]; ok && ec.Status == c.Status && ec.Message == c.Message {
+			continue
+		}
+		changed = append(changed, conditionStatus{
+			Type:    c.Type,
+			Status:  string(c.Status),
+			Message: c.Message,
+		})
+	}
+	return changed
+}
+
+// getAllocationResults returns the scalar status fields of the resource
+func getAllocationResults(o *fn.KubeObject) map[string]string {
+	status := o.GetMap("status")
+	if status == nil {
+		return nil
+	}
+	fields := map[string]any{}
+	if err := status.As(&fields); err != nil {
+		return nil
+	}
+	results := map[string]string{}
+	for k, v := range fields {
+		if _, ok := ignoredStatusFields[k]; ok {
+			continue
+		}
+		switch v.(type) {
+		case map[string]any, []any, nil:
+			continue
+		}
+		results[k] = fmt.Sprint(v)
+	}
+	if len(results) == 0 {
+		return nil
+	}
+	return results
+}
+
+// getSummary returns a one line summary of the specialization status
+func (r *specializationStatus) getSummary() string {
+	summaries := []string{}
+	for _, s := range r.Specializers {
+		switch {
+		case s.Error != "":
+			summaries = append(summaries, fmt.Sprintf("%s: failed: %s", s.Name, s.Error))
+		case s.Ready:
+			summaries = append(summaries, fmt.Sprintf("%s: ready", s.Name))
+		default:
+			notReady := []string{}
+			for _, c := range s.Conditions {
+				if c.Status != string(kptv1.ConditionTrue) {
+					notReady = append(notReady, c.Type)
+				}
+			}
+			sort.Strings(notReady)
+			summaries = append(summaries, fmt.Sprintf("%s: not ready: %s", s.Name, strings.Join(notReady, ", ")))
+		}
+	}
+	return strings.Join(summaries, "; ")
+}
+
+// mergeStatus returns the recorded specialization status with the results of
+// the specializers in status replaced or added
+func mergeStatus(recorded string, status *specializationStatus) *specializationStatus {
+	merged := &specializationStatus{}
+	if err := json.Unmarshal([]byte(recorded), merged); err != nil {
+		return status
+	}
+	for _, s := range status.Specializers {
+		found := false
+		for i, m := range merged.Specializers {
+			if m.Name == s.Name {
+				merged.Specializers[i] = s
+				found = true
+			}
+		}
+		if !found {
+			merged.Specializers = append(merged.Specializers, s)
+		}
+	}
+	return merged
+}
+
+// recordStatus persists the specializationStatus on the PackageRevision when
+// it changed and reports the change with an event. On a conflict the package
+// revision is read again and the status is merged into the latest annotation.
+func (r *reconciler) recordStatus(ctx context.Context, pr *porchv1alpha1.PackageRevision, status *specializationStatus) error {
+	first := true
+	changed := false
+	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if !first {
+			if err := r.porchClient.Get(ctx, client.ObjectKeyFromObject(pr), pr); err != nil {
+				return errors.Wrap(err, "cannot get package revision")
+			}
+		}
+		first = false
+		recorded := status
+		if r.specializer != "" {
+			// the dedicated controllers of the other specializers record their
+			// results in the same annotation
+			recorded = mergeStatus(pr.GetAnnotations()[StatusAnnotationName], status)
+		}
+		b, err := json.Marshal(recorded)
+		if err != nil {
+			return err
+		}
+		if pr.GetAnnotations()[StatusAnnotationName] == string(b) {
+			changed = false
+			return nil
+		}
+		patched := pr.DeepCopy()
+		resource.AddAnnotations(patched, map[string]string{StatusAnnotationName: string(b)})
+		if err := r.porchClient.Patch(ctx, patched, client.MergeFromWithOptions(pr, client.MergeFromWithOptimisticLock{})); err != nil {
+			return err
+		}
+		pr.SetAnnotations(patched.GetAnnotations())
+		pr.SetResourceVersion(patched.GetResourceVersion())
+		changed = true
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "cannot record specialization status")
+	}
+	if !changed {
+		return nil
+	}
+
+	eventType := corev1.EventTypeNormal
+	for _, s := range status.Specializers {
+		if s.Error != "" {
+			eventType = corev1.EventTypeWarning
+		}
+	}
+	r.recorder.Event(pr, eventType, "Specialized", status.getSummary())
+	return nil
+}
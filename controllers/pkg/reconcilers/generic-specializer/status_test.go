@@ -0,0 +1,249 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package genericspecializer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	kptv1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	"github.com/nephio-project/nephio/krm-functions/lib/kptrl"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testKptfile = `apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  name: pkg
+status:
+  conditions:
+  - type: test.nephio.org/v1alpha1.A.a1
+    status: "True"
+    message: done
+  - type: test.nephio.org/v1alpha1.A.a2
+    status: "False"
+    message: cannot claim
+  - type: test.nephio.org/v1alpha1.B.b1
+    status: "False"
+`
+
+const testResource = `apiVersion: test.nephio.org/v1alpha1
+kind: A
+metadata:
+  name: a1
+status:
+  prefix: 10.0.0.0/24
+  vlanID: 10
+  expiryTime: "2023-06-01T00:00:00Z"
+  conditions:
+  - type: Ready
+    status: "True"
+`
+
+func TestSpecializerStatus(t *testing.T) {
+	rl, err := kptrl.GetResourceList(map[string]string{
+		"Kptfile": testKptfile,
+		"a.yaml":  testResource,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &specializer{
+		name:          "test-a",
+		cfg:           newTestSpecializer("A")(nil).GetConfig(),
+		conditionType: "test.nephio.org/v1alpha1.A",
+	}
+
+	cases := map[string]struct {
+		err  error
+		want specializerStatus
+	}{
+		"NotReady": {
+			want: specializerStatus{
+				Name: "test-a",
+				Conditions: []conditionStatus{
+					{Type: "test.nephio.org/v1alpha1.A.a1", Status: "True", Message: "done"},
+					{Type: "test.nephio.org/v1alpha1.A.a2", Status: "False", Message: "cannot claim"},
+				},
+				Resources: []resourceStatus{
+					{Kind: "A", Name: "a1", Status: map[string]string{"prefix": "10.0.0.0/24", "vlanID": "10"}},
+				},
+			},
+		},
+		"Error": {
+			err: errors.New("backend unavailable"),
+			want: specializerStatus{
+				Name:  "test-a",
+				Error: "backend unavailable",
+				Conditions: []conditionStatus{
+					{Type: "test.nephio.org/v1alpha1.A.a1", Status: "True", Message: "done"},
+					{Type: "test.nephio.org/v1alpha1.A.a2", Status: "False", Message: "cannot claim"},
+				},
+				Resources: []resourceStatus{
+					{Kind: "A", Name: "a1", Status: map[string]string{"prefix": "10.0.0.0/24", "vlanID": "10"}},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := s.getStatus(rl, tc.err)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetChangedConditions(t *testing.T) {
+	s := &specializer{name: "test-a", conditionType: "test.nephio.org/v1alpha1.A"}
+	before := []kptv1.Condition{
+		{Type: "test.nephio.org/v1alpha1.A.a1", Status: kptv1.ConditionFalse},
+		{Type: "test.nephio.org/v1alpha1.A.a2", Status: kptv1.ConditionTrue},
+		{Type: "test.nephio.org/v1alpha1.B.b1", Status: kptv1.ConditionFalse},
+	}
+	after := []kptv1.Condition{
+		{Type: "test.nephio.org/v1alpha1.A.a1", Status: kptv1.ConditionTrue},
+		{Type: "test.nephio.org/v1alpha1.A.a2", Status: kptv1.ConditionTrue},
+		{Type: "test.nephio.org/v1alpha1.A.a3", Status: kptv1.ConditionFalse, Message: "cannot claim"},
+		{Type: "test.nephio.org/v1alpha1.B.b1", Status: kptv1.ConditionTrue},
+	}
+	want := []conditionStatus{
+		{Type: "test.nephio.org/v1alpha1.A.a1", Status: "True"},
+		{Type: "test.nephio.org/v1alpha1.A.a3", Status: "False", Message: "cannot claim"},
+	}
+	if diff := cmp.Diff(want, s.getChangedConditions(before, after)); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+}
+
+func TestRecordStatus(t *testing.T) {
+	ready := &specializationStatus{Specializers: []specializerStatus{{Name: "ipam", Ready: true}}}
+	failed := &specializationStatus{Specializers: []specializerStatus{
+		{Name: "ipam", Ready: true},
+		{Name: "vlan", Error: "backend unavailable"},
+		{Name: "configInject", Conditions: []conditionStatus{
+			{Type: "b", Status: "False"},
+			{Type: "a", Status: "False"},
+		}},
+	}}
+
+	cases := map[string]struct {
+		annotation string
+		// stored is the annotation of the package revision in the api server
+		// when it was changed since the package revision was read
+		stored      string
+		specializer string
+		status      *specializationStatus
+		// wantAnnotation defaults to the status
+		wantAnnotation string
+		wantEvent      string
+	}{
+		"Ready": {
+			status:    ready,
+			wantEvent: "Normal Specialized ipam: ready",
+		},
+		"Failed": {
+			status:    failed,
+			wantEvent: "Warning Specialized ipam: ready; vlan: failed: backend unavailable; configInject: not ready: a, b",
+		},
+		"Unchanged": {
+			annotation: `{"specializers":[{"name":"ipam","ready":true}]}`,
+			status:     ready,
+		},
+		"Dedicated": {
+			annotation:     `{"specializers":[{"name":"ipam","ready":false},{"name":"vlan","ready":true}]}`,
+			specializer:    "ipam",
+			status:         ready,
+			wantAnnotation: `{"specializers":[{"name":"ipam","ready":true},{"name":"vlan","ready":true}]}`,
+			wantEvent:      "Normal Specialized ipam: ready",
+		},
+		"DedicatedConflict": {
+			annotation:     `{"specializers":[{"name":"ipam","ready":false}]}`,
+			stored:         `{"specializers":[{"name":"ipam","ready":false},{"name":"vlan","ready":true}]}`,
+			specializer:    "ipam",
+			status:         ready,
+			wantAnnotation: `{"specializers":[{"name":"ipam","ready":true},{"name":"vlan","ready":true}]}`,
+			wantEvent:      "Normal Specialized ipam: ready",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			pr := &porchv1alpha1.PackageRevision{
+				ObjectMeta: metav1.ObjectMeta{Name: "pr", Namespace: "default"},
+			}
+			if tc.annotation != "" {
+				pr.SetAnnotations(map[string]string{StatusAnnotationName: tc.annotation})
+			}
+			scheme := runtime.NewScheme()
+			if err := porchv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+			stored := pr.DeepCopy()
+			if tc.stored != "" {
+				stored.SetAnnotations(map[string]string{StatusAnnotationName: tc.stored})
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(stored).Build()
+			// the package revision read by the reconciler, outdated when the
+			// stored annotation changed since
+			pr.SetResourceVersion(stored.GetResourceVersion())
+			if tc.stored != "" {
+				pr.SetResourceVersion("1")
+			}
+			recorder := record.NewFakeRecorder(10)
+			r := &reconciler{Client: c, porchClient: c, recorder: recorder, specializer: tc.specializer}
+
+			if err := r.recordStatus(context.Background(), pr, tc.status); err != nil {
+				t.Fatal(err)
+			}
+
+			got := &porchv1alpha1.PackageRevision{}
+			if err := c.Get(context.Background(), client.ObjectKeyFromObject(pr), got); err != nil {
+				t.Fatal(err)
+			}
+			want := tc.wantAnnotation
+			if want == "" {
+				b, err := json.Marshal(tc.status)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want = string(b)
+			}
+			if diff := cmp.Diff(want, got.GetAnnotations()[StatusAnnotationName]); diff != "" {
+				t.Errorf("annotation -want, +got:\n%s", diff)
+			}
+			gotEvent := ""
+			select {
+			case gotEvent = <-recorder.Events:
+			default:
+			}
+			if gotEvent != tc.wantEvent {
+				t.Errorf("event: want %q, got %q", tc.wantEvent, gotEvent)
+			}
+		})
+	}
+}
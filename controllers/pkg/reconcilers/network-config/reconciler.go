@@ -0,0 +1,160 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkconfig
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	configv1alpha1 "github.com/henderiw-nephio/network/apis/config/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/gnmiclient"
+	reconcilerinterface "github.com/nephio-project/nephio/controllers/pkg/reconcilers/reconciler-interface"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	invv1alpha1 "github.com/nokia/k8s-ipam/apis/inv/v1alpha1"
+	"github.com/nokia/k8s-ipam/pkg/meta"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func init() {
+	reconcilerinterface.Register("network-configs", &reconciler{})
+}
+
+const (
+	// PushModeAnnotationName selects how the config is pushed to the device,
+	// update (default) merges it into the config of the device, replace
+	// replaces the config of the device
+	PushModeAnnotationName = "config.nephio.org/push-mode"
+
+	// ConditionReasonInvalidTarget is the reason of the Ready condition when
+	// the target of the node or its secrets cannot be used
+	ConditionReasonInvalidTarget configv1alpha1.ConditionReason = "InvalidTarget"
+	// ConditionReasonPushFailed is the reason of the Ready condition when the
+	// device rejected the config or could not be reached
+	ConditionReasonPushFailed configv1alpha1.ConditionReason = "PushFailed"
+
+	pushTimeout = 30 * time.Second
+	// errors
+	errGetCr        = "cannot get cr"
+	errUpdateStatus = "cannot update status"
+)
+
+//+kubebuilder:rbac:groups=config.resource.nephio.org,resources=networks,verbs=get;list;watch
+//+kubebuilder:rbac:groups=config.resource.nephio.org,resources=networks/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=inv.nephio.org,resources=targets,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c interface{}) (map[schema.GroupVersionKind]chan event.GenericEvent, error) {
+	if err := configv1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
+		return nil, err
+	}
+	if err := invv1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
+		return nil, err
+	}
+
+	r.APIPatchingApplicator = resource.NewAPIPatchingApplicator(mgr.GetClient())
+	r.gnmiClient = gnmiclient.New(pushTimeout)
+	r.backoff = newBackoff()
+
+	return nil, ctrl.NewControllerManagedBy(mgr).
+		Named("NetworkConfigController").
+		For(&configv1alpha1.Network{}).
+		Watches(&invv1alpha1.Target{}, &targetEventHandler{client: mgr.GetClient()}).
+		Complete(r)
+}
+
+// reconciler pushes the device config of the config.resource.nephio.org
+// Network resources, rendered per node by the network reconciler, to the
+// devices over gNMI
+type reconciler struct {
+	resource.APIPatchingApplicator
+	gnmiClient gnmiclient.Client
+	backoff    workqueue.RateLimiter
+}
+
+func newBackoff() workqueue.RateLimiter {
+	return workqueue.NewItemExponentialFailureRateLimiter(5*time.Second, 5*time.Minute)
+}
+
+func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+	log.Info("reconcile", "req", req)
+
+	cr := &configv1alpha1.Network{}
+	if err := r.Get(ctx, req.NamespacedName, cr); err != nil {
+		// if the resource no longer exists the reconcile loop is done
+		if resource.IgnoreNotFound(err) != nil {
+			log.Error(err, errGetCr)
+			return ctrl.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGetCr)
+		}
+		r.backoff.Forget(req)
+		return ctrl.Result{}, nil
+	}
+
+	// the config is left on the device when the resource is deleted
+	if meta.WasDeleted(cr) {
+		r.backoff.Forget(req)
+		return ctrl.Result{}, nil
+	}
+
+	if isApplied(cr) {
+		log.Info("config already applied")
+		return ctrl.Result{}, nil
+	}
+
+	t, err := r.getTarget(ctx, cr)
+	if err != nil {
+		log.Error(err, "cannot get target")
+		cr.SetConditions(failed(ConditionReasonInvalidTarget, err.Error()))
+		return ctrl.Result{RequeueAfter: r.backoff.When(req)}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	}
+
+	mode := gnmiclient.Mode(cr.GetAnnotations()[PushModeAnnotationName])
+	if err := r.gnmiClient.Set(ctx, t, mode, cr.Spec.Config.Raw); err != nil {
+		log.Error(err, "cannot push config", "address", t.Address, "mode", mode)
+		// the message only holds the code, so it does not change on every attempt
+		cr.SetConditions(failed(ConditionReasonPushFailed, fmt.Sprintf("cannot push config to %s: %s", t.Address, gnmiclient.Code(err))))
+		return ctrl.Result{RequeueAfter: r.backoff.When(req)}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	}
+	log.Info("config pushed", "address", t.Address, "mode", mode)
+
+	r.backoff.Forget(req)
+	cr.Status.LastAppliedConfig = *cr.Spec.Config.DeepCopy()
+	cr.SetConditions(configv1alpha1.Ready())
+	return ctrl.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+}
+
+// isApplied returns true when the config was pushed to the device, a config
+// is pushed once, changes on the device are not reverted
+func isApplied(cr *configv1alpha1.Network) bool {
+	return cr.GetCondition(configv1alpha1.ConditionTypeReady).Status == metav1.ConditionTrue &&
+		bytes.Equal(cr.Spec.Config.Raw, cr.Status.LastAppliedConfig.Raw)
+}
+
+func failed(reason configv1alpha1.ConditionReason, msg string) configv1alpha1.Condition {
+	c := configv1alpha1.Failed(msg)
+	c.Reason = string(reason)
+	return c
+}
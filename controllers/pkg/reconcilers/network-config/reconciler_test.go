@@ -0,0 +1,265 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkconfig
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	configv1alpha1 "github.com/henderiw-nephio/network/apis/config/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/gnmiclient"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	invv1alpha1 "github.com/nokia/k8s-ipam/apis/inv/v1alpha1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type push struct {
+	target gnmiclient.Target
+	mode   gnmiclient.Mode
+	config string
+}
+
+// fakeGnmiClient records the pushed configs instead of sending them to a device
+type fakeGnmiClient struct {
+	err    error
+	pushes []push
+}
+
+func (r *fakeGnmiClient) Set(ctx context.Context, t *gnmiclient.Target, mode gnmiclient.Mode, config []byte) error {
+	r.pushes = append(r.pushes, push{target: *t, mode: mode, config: string(config)})
+	return r.err
+}
+
+const testConfig = `{"srl_nokia-interfaces:interface":[{"name":"ethernet-1/1"}]}`
+
+func newTestReconciler(t *testing.T, gnmiClient gnmiclient.Client, objs ...client.Object) *reconciler {
+	scheme := runtime.NewScheme()
+	if err := configv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("cannot add config to scheme: %v", err)
+	}
+	if err := invv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("cannot add inv to scheme: %v", err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("cannot add core to scheme: %v", err)
+	}
+	// the fixtures are shared by the test cases
+	b := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&configv1alpha1.Network{})
+	for _, o := range objs {
+		b = b.WithObjects(o.DeepCopyObject().(client.Object))
+	}
+	c := b.Build()
+	return &reconciler{
+		APIPatchingApplicator: resource.NewAPIPatchingApplicator(c),
+		gnmiClient:            gnmiClient,
+		backoff:               newBackoff(),
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "vpc-ran-leaf1"}}
+	nc := &configv1alpha1.Network{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "vpc-ran-leaf1",
+			Labels:    map[string]string{invv1alpha1.NephioNodeNameKey: "leaf1"},
+		},
+		Spec: configv1alpha1.NetworkSpec{Config: runtime.RawExtension{Raw: []byte(testConfig)}},
+	}
+	target := &invv1alpha1.Target{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "leaf1"},
+		Spec: invv1alpha1.TargetSpec{
+			Provider:   "srl.nokia.com",
+			Address:    pointer.String("172.18.0.10:57400"),
+			SecretName: "srl-secret",
+			SkipVerify: pointer.Bool(true),
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "srl-secret"},
+		Data: map[string][]byte{
+			corev1.BasicAuthUsernameKey: []byte("admin"),
+			corev1.BasicAuthPasswordKey: []byte("secret"),
+		},
+	}
+	replace := nc.DeepCopy()
+	replace.SetAnnotations(map[string]string{PushModeAnnotationName: "replace"})
+	wantTarget := gnmiclient.Target{
+		Address:    "172.18.0.10:57400",
+		Username:   "admin",
+		Password:   "secret",
+		SkipVerify: true,
+		Encoding:   gnmiclient.EncodingJSONIETF,
+	}
+
+	cases := map[string]struct {
+		objs       []client.Object
+		pushErr    error
+		wantPushes []push
+		wantResult []ctrl.Result
+		wantReason string
+		// wantMessage is a part of the message of the Ready condition
+		wantMessage string
+		wantConfig  string
+	}{
+		"Push": {
+			objs:       []client.Object{nc, target, secret},
+			wantPushes: []push{{target: wantTarget, mode: "", config: testConfig}},
+			wantResult: []ctrl.Result{{}},
+			wantReason: string(configv1alpha1.ConditionReasonReady),
+			wantConfig: testConfig,
+		},
+		"PushOnce": {
+			objs:       []client.Object{nc, target, secret},
+			wantPushes: []push{{target: wantTarget, mode: "", config: testConfig}},
+			wantResult: []ctrl.Result{{}, {}},
+			wantReason: string(configv1alpha1.ConditionReasonReady),
+			wantConfig: testConfig,
+		},
+		"Replace": {
+			objs:       []client.Object{replace, target, secret},
+			wantPushes: []push{{target: wantTarget, mode: gnmiclient.ModeReplace, config: testConfig}},
+			wantResult: []ctrl.Result{{}},
+			wantReason: string(configv1alpha1.ConditionReasonReady),
+			wantConfig: testConfig,
+		},
+		"MissingTarget": {
+			objs:        []client.Object{nc},
+			wantResult:  []ctrl.Result{{RequeueAfter: 5 * time.Second}, {RequeueAfter: 10 * time.Second}},
+			wantReason:  string(ConditionReasonInvalidTarget),
+			wantMessage: `cannot get target leaf1: targets.inv.nephio.org "leaf1" not found`,
+		},
+		"PushFailed": {
+			objs:    []client.Object{nc, target, secret},
+			pushErr: status.Error(codes.Unavailable, "connection refused"),
+			wantPushes: []push{
+				{target: wantTarget, mode: "", config: testConfig},
+				{target: wantTarget, mode: "", config: testConfig},
+			},
+			wantResult: []ctrl.Result{{RequeueAfter: 5 * time.Second}, {RequeueAfter: 10 * time.Second}},
+			wantReason: string(ConditionReasonPushFailed),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			gnmiClient := &fakeGnmiClient{err: tc.pushErr}
+			r := newTestReconciler(t, gnmiClient, tc.objs...)
+
+			got := []ctrl.Result{}
+			for range tc.wantResult {
+				result, err := r.Reconcile(context.Background(), req)
+				if err != nil {
+					t.Fatalf("Reconcile() unexpected error: %v", err)
+				}
+				got = append(got, result)
+			}
+			if diff := cmp.Diff(tc.wantResult, got); diff != "" {
+				t.Errorf("Reconcile() result -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantPushes, gnmiClient.pushes, cmp.AllowUnexported(push{})); diff != "" {
+				t.Errorf("Set() -want, +got:\n%s", diff)
+			}
+
+			cr := &configv1alpha1.Network{}
+			if err := r.Get(context.Background(), req.NamespacedName, cr); err != nil {
+				t.Fatalf("cannot get network config: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantReason, cr.GetCondition(configv1alpha1.ConditionTypeReady).Reason); diff != "" {
+				t.Errorf("Ready reason -want, +got:\n%s", diff)
+			}
+			if msg := cr.GetCondition(configv1alpha1.ConditionTypeReady).Message; !strings.Contains(msg, tc.wantMessage) {
+				t.Errorf("Ready message: want %q in %q", tc.wantMessage, msg)
+			}
+			if diff := cmp.Diff(tc.wantConfig, string(cr.Status.LastAppliedConfig.Raw)); diff != "" {
+				t.Errorf("LastAppliedConfig -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReconcileConfigChanged(t *testing.T) {
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "vpc-ran-leaf1"}}
+	nc := &configv1alpha1.Network{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "vpc-ran-leaf1",
+			Labels:    map[string]string{invv1alpha1.NephioNodeNameKey: "leaf1"},
+		},
+		Spec: configv1alpha1.NetworkSpec{Config: runtime.RawExtension{Raw: []byte(testConfig)}},
+	}
+	target := &invv1alpha1.Target{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "leaf1"},
+		Spec: invv1alpha1.TargetSpec{
+			Provider:   "srl.nokia.com",
+			Address:    pointer.String("172.18.0.10:57400"),
+			SecretName: "srl-secret",
+			SkipVerify: pointer.Bool(true),
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "srl-secret"},
+		Data: map[string][]byte{
+			corev1.BasicAuthUsernameKey: []byte("admin"),
+			corev1.BasicAuthPasswordKey: []byte("secret"),
+		},
+	}
+	gnmiClient := &fakeGnmiClient{}
+	r := newTestReconciler(t, gnmiClient, nc, target, secret)
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() unexpected error: %v", err)
+	}
+
+	// the network reconciler renders a new config for the node
+	cr := &configv1alpha1.Network{}
+	if err := r.Get(context.Background(), req.NamespacedName, cr); err != nil {
+		t.Fatalf("cannot get network config: %v", err)
+	}
+	newConfig := `{"srl_nokia-interfaces:interface":[{"name":"ethernet-1/2"}]}`
+	cr.Spec.Config.Raw = []byte(newConfig)
+	if err := r.Update(context.Background(), cr); err != nil {
+		t.Fatalf("cannot update network config: %v", err)
+	}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() unexpected error: %v", err)
+	}
+
+	got := []string{}
+	for _, p := range gnmiClient.pushes {
+		got = append(got, p.config)
+	}
+	if diff := cmp.Diff([]string{testConfig, newConfig}, got); diff != "" {
+		t.Errorf("Set() configs -want, +got:\n%s", diff)
+	}
+	if err := r.Get(context.Background(), req.NamespacedName, cr); err != nil {
+		t.Fatalf("cannot get network config: %v", err)
+	}
+	if diff := cmp.Diff(newConfig, string(cr.Status.LastAppliedConfig.Raw)); diff != "" {
+		t.Errorf("LastAppliedConfig -want, +got:\n%s", diff)
+	}
+}
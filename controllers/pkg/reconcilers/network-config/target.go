@@ -0,0 +1,76 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkconfig
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nephio-project/nephio/controllers/pkg/gnmiclient"
+	invv1alpha1 "github.com/nokia/k8s-ipam/apis/inv/v1alpha1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// getTarget returns the gNMI target of the node the config is rendered for.
+// The target has the name of the node, its secret provides the username and
+// password and its tls secret the certificate authority.
+func (r *reconciler) getTarget(ctx context.Context, cr client.Object) (*gnmiclient.Target, error) {
+	nodeName, ok := cr.GetLabels()[invv1alpha1.NephioNodeNameKey]
+	if !ok {
+		return nil, fmt.Errorf("missing label %s", invv1alpha1.NephioNodeNameKey)
+	}
+	target := &invv1alpha1.Target{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: cr.GetNamespace(), Name: nodeName}, target); err != nil {
+		return nil, errors.Wrapf(err, "cannot get target %s", nodeName)
+	}
+	if target.Spec.Protocol != nil && string(*target.Spec.Protocol) != string(invv1alpha1.Protocol_GNMI) {
+		return nil, fmt.Errorf("target %s: unsupported protocol %s", nodeName, *target.Spec.Protocol)
+	}
+	if target.Spec.Address == nil {
+		return nil, fmt.Errorf("target %s: missing address", nodeName)
+	}
+
+	t := &gnmiclient.Target{
+		Address:    *target.Spec.Address,
+		Insecure:   target.Spec.Insecure != nil && *target.Spec.Insecure,
+		SkipVerify: target.Spec.SkipVerify != nil && *target.Spec.SkipVerify,
+		Encoding:   gnmiclient.EncodingJSONIETF,
+	}
+	if target.Spec.Encoding != nil {
+		t.Encoding = gnmiclient.Encoding(*target.Spec.Encoding)
+	}
+
+	if target.Spec.SecretName != "" {
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: cr.GetNamespace(), Name: target.Spec.SecretName}, secret); err != nil {
+			return nil, errors.Wrapf(err, "target %s: cannot get secret %s", nodeName, target.Spec.SecretName)
+		}
+		t.Username = string(secret.Data[corev1.BasicAuthUsernameKey])
+		t.Password = string(secret.Data[corev1.BasicAuthPasswordKey])
+	}
+	if target.Spec.TLSSecretName != nil && !t.Insecure {
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: cr.GetNamespace(), Name: *target.Spec.TLSSecretName}, secret); err != nil {
+			return nil, errors.Wrapf(err, "target %s: cannot get tls secret %s", nodeName, *target.Spec.TLSSecretName)
+		}
+		t.CA = secret.Data[corev1.ServiceAccountRootCAKey]
+	}
+	return t, nil
+}
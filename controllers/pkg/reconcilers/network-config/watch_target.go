@@ -0,0 +1,83 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkconfig
+
+import (
+	"context"
+
+	configv1alpha1 "github.com/henderiw-nephio/network/apis/config/v1alpha1"
+	invv1alpha1 "github.com/nokia/k8s-ipam/apis/inv/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type adder interface {
+	Add(item interface{})
+}
+
+type targetEventHandler struct {
+	client client.Client
+}
+
+// Create enqueues a request for the configs of the node of the target
+func (e *targetEventHandler) Create(ctx context.Context, evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	e.add(ctx, evt.Object, q)
+}
+
+// Update enqueues a request for the configs of the node of the target
+func (e *targetEventHandler) Update(ctx context.Context, evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	e.add(ctx, evt.ObjectOld, q)
+	e.add(ctx, evt.ObjectNew, q)
+}
+
+// Delete enqueues a request for the configs of the node of the target
+func (e *targetEventHandler) Delete(ctx context.Context, evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.add(ctx, evt.Object, q)
+}
+
+// Generic enqueues a request for the configs of the node of the target
+func (e *targetEventHandler) Generic(ctx context.Context, evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	e.add(ctx, evt.Object, q)
+}
+
+func (e *targetEventHandler) add(ctx context.Context, obj runtime.Object, queue adder) {
+	cr, ok := obj.(*invv1alpha1.Target)
+	if !ok {
+		return
+	}
+	log := log.FromContext(ctx)
+	log.Info("event", "kind", obj.GetObjectKind(), "name", cr.GetName())
+
+	configs := &configv1alpha1.NetworkList{}
+	if err := e.client.List(ctx, configs, client.InNamespace(cr.GetNamespace()), client.MatchingLabels{
+		invv1alpha1.NephioNodeNameKey: cr.GetName(),
+	}); err != nil {
+		return
+	}
+
+	for _, config := range configs.Items {
+		log.Info("event requeue network config", "name", config.GetName())
+		queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: config.GetNamespace(),
+			Name:      config.GetName()}})
+	}
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	configv1alpha1 "github.com/henderiw-nephio/network/apis/config/v1alpha1"
+	infra2v1alpha1 "github.com/henderiw-nephio/network/apis/infra2/v1alpha1"
+	"github.com/henderiw-nephio/network/pkg/endpoints"
+	"github.com/henderiw-nephio/network/pkg/ipam"
+	"github.com/henderiw-nephio/network/pkg/network"
+	"github.com/henderiw-nephio/network/pkg/nodes"
+	"github.com/henderiw-nephio/network/pkg/resources"
+	"github.com/henderiw-nephio/network/pkg/vlan"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	reqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	invv1alpha1 "github.com/nokia/k8s-ipam/apis/inv/v1alpha1"
+	resourcev1alpha1 "github.com/nokia/k8s-ipam/apis/resource/common/v1alpha1"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
+	vlanv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/vlan/v1alpha1"
+	"github.com/srl-labs/ygotsrl/v22"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// benchmarkProvider renders an empty config. Rendering is the same whichever
+// way the network is rendered, the validation of the SR Linux device config
+// would dominate the benchmark.
+type benchmarkProvider struct{}
+
+func (r benchmarkProvider) Name() string                                  { return "benchmark.nephio.org" }
+func (r benchmarkProvider) Render(device *ygotsrl.Device) ([]byte, error) { return []byte("{}"), nil }
+
+// getBenchmarkInventory returns a topology of leaves with 4 endpoints each,
+// the endpoints are spread over 4 clusters
+func getBenchmarkInventory(leaves int) *inventory {
+	eps := &invv1alpha1.EndpointList{}
+	nos := &invv1alpha1.NodeList{}
+	for i := 0; i < leaves; i++ {
+		nodeName := fmt.Sprintf("leaf%d", i)
+		nos.Items = append(nos.Items, invv1alpha1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name:   nodeName,
+			Labels: map[string]string{invv1alpha1.NephioProviderKey: benchmarkProvider{}.Name()},
+		}})
+		for j := 1; j <= 4; j++ {
+			itfceName := fmt.Sprintf("e1-%d", j)
+			eps.Items = append(eps.Items, invv1alpha1.Endpoint{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: nodeName + "-" + itfceName,
+					Labels: map[string]string{
+						invv1alpha1.NephioProviderKey:      benchmarkProvider{}.Name(),
+						invv1alpha1.NephioNodeNameKey:      nodeName,
+						invv1alpha1.NephioInterfaceNameKey: itfceName,
+						invv1alpha1.NephioClusterNameKey:   fmt.Sprintf("edge%02d", j),
+					},
+				},
+				Spec: invv1alpha1.EndpointSpec{EndpointProperties: invv1alpha1.EndpointProperties{
+					NodeName:      nodeName,
+					InterfaceName: itfceName,
+				}},
+			})
+		}
+	}
+	return newInventory(&endpoints.Endpoints{EndpointList: eps}, &nodes.Nodes{NodeList: nos})
+}
+
+func getBenchmarkNetwork() *infrav1alpha1.Network {
+	return &infrav1alpha1.Network{
+		TypeMeta:   metav1.TypeMeta{APIVersion: infrav1alpha1.GroupVersion.String(), Kind: infrav1alpha1.NetworkKind},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vpc-ran", UID: "1234"},
+		Spec: infrav1alpha1.NetworkSpec{
+			Topology: "nephio",
+			BridgeDomains: []infrav1alpha1.BridgeDomain{{
+				Name: "ran-bd",
+				Interfaces: []infrav1alpha1.Interface{{
+					Kind:           infrav1alpha1.InterfaceKindInterface,
+					Selector:       &metav1.LabelSelector{MatchLabels: map[string]string{invv1alpha1.NephioClusterNameKey: "edge01"}},
+					AttachmentType: reqv1alpha1.AttachmentTypeVLAN,
+				}},
+			}},
+			RoutingTables: []infrav1alpha1.RoutingTable{{
+				Name: "ran-rt",
+				Interfaces: []infrav1alpha1.Interface{{
+					Kind:           infrav1alpha1.InterfaceKindInterface,
+					Selector:       &metav1.LabelSelector{MatchLabels: map[string]string{invv1alpha1.NephioClusterNameKey: "edge02"}},
+					AttachmentType: reqv1alpha1.AttachmentTypeVLAN,
+				}},
+				Prefixes: []ipamv1alpha1.Prefix{{Prefix: "10.0.0.0/8"}},
+			}},
+		},
+	}
+}
+
+// newBenchmarkReconciler returns a reconciler with client proxies counting
+// the claims and a client counting the writes
+func newBenchmarkReconciler(b *testing.B) (*reconciler, *int, *countingClient) {
+	claims := 0
+	vlanIDs := map[string]uint16{}
+	scheme := runtime.NewScheme()
+	for _, addToScheme := range []func(*runtime.Scheme) error{
+		ipamv1alpha1.AddToScheme,
+		vlanv1alpha1.AddToScheme,
+		configv1alpha1.AddToScheme,
+	} {
+		if err := addToScheme(scheme); err != nil {
+			b.Fatalf("cannot add to scheme: %v", err)
+		}
+	}
+	c := &countingClient{Client: fake.NewClientBuilder().WithScheme(scheme).Build()}
+	return &reconciler{
+		APIPatchingApplicator: resource.NewAPIPatchingApplicator(c),
+		IpamClientProxy: &testProxy[*ipamv1alpha1.NetworkInstance, *ipamv1alpha1.IPClaim]{claim: func(cr client.Object) *ipamv1alpha1.IPClaim {
+			claims++
+			claim := cr.(*ipamv1alpha1.IPClaim).DeepCopy()
+			claim.Status.Prefix = pointer.String("10.0.0.1/24")
+			if claim.Spec.CreatePrefix != nil {
+				claim.Status.Prefix = pointer.String("10.0.0.0/24")
+			}
+			return claim
+		}},
+		VlanClientProxy: &testProxy[*vlanv1alpha1.VLANIndex, *vlanv1alpha1.VLANClaim]{claim: func(cr client.Object) *vlanv1alpha1.VLANClaim {
+			claims++
+			claim := cr.(*vlanv1alpha1.VLANClaim).DeepCopy()
+			key := claim.Spec.VLANIndex.Name + "/" + claim.GetName()
+			if _, ok := vlanIDs[key]; !ok {
+				vlanIDs[key] = uint16(len(vlanIDs) + 1)
+			}
+			id := vlanIDs[key]
+			claim.Status.VLANID = &id
+			return claim
+		}},
+	}, &claims, c
+}
+
+func newBenchmarkResources(r *reconciler, cr *infrav1alpha1.Network) resources.Resources {
+	return resources.New(r.APIPatchingApplicator, resources.Config{
+		CR:             cr,
+		MatchingLabels: resourcev1alpha1.GetOwnerLabelsFromCR(cr),
+		Owns:           []schema.GroupVersionKind{configv1alpha1.NetworkGroupVersionKind},
+	})
+}
+
+// renderSinglePass renders the network the way the reconciler does
+func renderSinglePass(ctx context.Context, r *reconciler, cr *infrav1alpha1.Network, inv *inventory) error {
+	r.resources = newBenchmarkResources(r, cr)
+	if err := r.applyDatabases(ctx, cr, inv); err != nil {
+		return err
+	}
+	if _, err := r.getNewResources(ctx, cr, inv); err != nil {
+		return err
+	}
+	return r.resources.APIApply(ctx)
+}
+
+// renderTwoPass renders the network running the network library twice, once
+// to apply the databases and once to render the node configs, which is how
+// the reconciler used to render networks. The first pass claims nothing, the
+// databases it adds to the resources are applied again with the node configs.
+func renderTwoPass(ctx context.Context, r *reconciler, cr *infrav1alpha1.Network, inv *inventory) error {
+	r.resources = newBenchmarkResources(r, cr)
+	n := network.New(&network.Config{
+		Config:    &infra2v1alpha1.NetworkConfig{},
+		Apply:     true,
+		Resources: r.resources,
+		Endpoints: &endpoints.Endpoints{EndpointList: inv.eps.EndpointList},
+		Nodes:     inv.nodes,
+		Ipam:      ipam.NewIPAM(r.IpamClientProxy),
+		Vlan:      vlan.NewVLAN(r.VlanClientProxy),
+	})
+	if err := n.Run(ctx, cr); err != nil {
+		return err
+	}
+	if err := r.resources.APIApply(ctx); err != nil {
+		return err
+	}
+	// the second pass does not share the lookups of the first one
+	if _, err := r.getNewResources(ctx, cr, newInventory(inv.eps, inv.nodes)); err != nil {
+		return err
+	}
+	return r.resources.APIApply(ctx)
+}
+
+func BenchmarkRender(b *testing.B) {
+	RegisterProvider(benchmarkProvider{})
+	for _, leaves := range []int{10, 100} {
+		for name, render := range map[string]func(context.Context, *reconciler, *infrav1alpha1.Network, *inventory) error{
+			"TwoPass":    renderTwoPass,
+			"SinglePass": renderSinglePass,
+		} {
+			b.Run(fmt.Sprintf("%s/leaves=%d", name, leaves), func(b *testing.B) {
+				ctx := context.Background()
+				r, claims, c := newBenchmarkReconciler(b)
+				cr := getBenchmarkNetwork()
+				// the first render creates every object, the benchmark measures
+				// the reconciles of an unchanged network
+				if err := render(ctx, r, cr, getBenchmarkInventory(leaves)); err != nil {
+					b.Fatalf("unexpected error: %v", err)
+				}
+				*claims = 0
+				c.written = nil
+
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if err := render(ctx, r, cr, getBenchmarkInventory(leaves)); err != nil {
+						b.Fatalf("unexpected error: %v", err)
+					}
+				}
+				b.ReportMetric(float64(*claims)/float64(b.N), "claims/op")
+				b.ReportMetric(float64(len(c.written))/float64(b.N), "writes/op")
+			})
+		}
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+
+	"github.com/henderiw-nephio/network/pkg/ipam"
+	"github.com/henderiw-nephio/network/pkg/resources"
+	"github.com/henderiw-nephio/network/pkg/vlan"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	reqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
+	vlanv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/vlan/v1alpha1"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// getDatabases returns the ipam network instances and vlan databases the ip
+// prefixes and vlans of the Network are claimed from: a network instance per
+// routing table and a vlan database per selector name of the vlan attached
+// interfaces of a routing table
+func (r *reconciler) getDatabases(cr *infrav1alpha1.Network, inv *inventory) ([]client.Object, error) {
+	ipamDBs := ipam.NewIPAM(r.IpamClientProxy)
+	vlanDBs := vlan.NewVLAN(r.VlanClientProxy)
+
+	dbs := []client.Object{}
+	vlanDBNames := map[string]struct{}{}
+	for _, rt := range cr.Spec.RoutingTables {
+		dbs = append(dbs, ipamDBs.ClaimIPAMDB(cr, rt.Name, rt.Prefixes))
+		for _, itfce := range rt.Interfaces {
+			if itfce.Kind == infrav1alpha1.InterfaceKindBridgeDomain || itfce.AttachmentType != reqv1alpha1.AttachmentTypeVLAN {
+				continue
+			}
+			selected, err := inv.getSelectedEndpoints(itfce)
+			if err != nil {
+				return nil, err
+			}
+			for selectorName := range selected {
+				vlanDBNames[selectorName] = struct{}{}
+			}
+		}
+	}
+	for _, name := range sortedKeys(vlanDBNames) {
+		dbs = append(dbs, vlanDBs.ClaimVLANDB(cr, name))
+	}
+	return dbs, nil
+}
+
+// applyDatabases applies the databases of the Network that do not exist or
+// changed, the ip prefixes and vlans can only be claimed once they exist
+func (r *reconciler) applyDatabases(ctx context.Context, cr *infrav1alpha1.Network, inv *inventory) error {
+	dbs, err := r.getDatabases(cr, inv)
+	if err != nil {
+		return err
+	}
+	for _, db := range dbs {
+		upToDate, err := r.isDatabaseUpToDate(ctx, db)
+		if err != nil {
+			return err
+		}
+		if upToDate {
+			continue
+		}
+		log.FromContext(ctx).Info("apply database", "kind", db.GetObjectKind().GroupVersionKind().Kind, "name", db.GetName())
+		if err := r.Apply(ctx, db); err != nil {
+			return errors.Wrapf(err, "cannot apply database %s", db.GetName())
+		}
+	}
+	return nil
+}
+
+// isDatabaseUpToDate returns true when the database exists with the spec of
+// the desired database
+func (r *reconciler) isDatabaseUpToDate(ctx context.Context, db client.Object) (bool, error) {
+	var existing client.Object
+	var spec func(o client.Object) any
+	switch db.(type) {
+	case *ipamv1alpha1.NetworkInstance:
+		existing = &ipamv1alpha1.NetworkInstance{}
+		spec = func(o client.Object) any { return o.(*ipamv1alpha1.NetworkInstance).Spec }
+	case *vlanv1alpha1.VLANIndex:
+		existing = &vlanv1alpha1.VLANIndex{}
+		spec = func(o client.Object) any { return o.(*vlanv1alpha1.VLANIndex).Spec }
+	default:
+		return false, nil
+	}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(db), existing); err != nil {
+		if resource.IgnoreNotFound(err) != nil {
+			return false, err
+		}
+		return false, nil
+	}
+	return equality.Semantic.DeepEqual(spec(existing), spec(db)), nil
+}
+
+// withoutDatabases are the resources rendered by the network library without
+// the databases, as the databases are applied by applyDatabases when they do
+// not exist or changed and do not need to be applied again with the configs
+type withoutDatabases struct {
+	resources.Resources
+}
+
+func (r withoutDatabases) AddNewResource(o client.Object) {
+	switch o.(type) {
+	case *ipamv1alpha1.NetworkInstance, *vlanv1alpha1.VLANIndex:
+		return
+	}
+	r.Resources.AddNewResource(o)
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	configv1alpha1 "github.com/henderiw-nephio/network/apis/config/v1alpha1"
+	"github.com/henderiw-nephio/network/pkg/resources"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	reqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	invv1alpha1 "github.com/nokia/k8s-ipam/apis/inv/v1alpha1"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
+	vlanv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/vlan/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetDatabases(t *testing.T) {
+	cr, inv := getTestNetwork()
+	// vlan attached interfaces of a routing table claim from the vlan
+	// database of their selector name, bridge domains do not create one
+	cr.Spec.RoutingTables[0].Interfaces[1].AttachmentType = reqv1alpha1.AttachmentTypeVLAN
+	cr.Spec.RoutingTables[1].Interfaces = []infrav1alpha1.Interface{{
+		Kind:           infrav1alpha1.InterfaceKindInterface,
+		Selector:       &metav1.LabelSelector{MatchLabels: map[string]string{invv1alpha1.NephioClusterNameKey: "edge01"}},
+		AttachmentType: reqv1alpha1.AttachmentTypeVLAN,
+	}}
+
+	r := &reconciler{}
+	dbs, err := r.getDatabases(cr, inv)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := []string{}
+	for _, db := range dbs {
+		got = append(got, db.GetObjectKind().GroupVersionKind().Kind+"/"+db.GetName())
+	}
+	want := []string{
+		"NetworkInstance/ran-rt",
+		"NetworkInstance/default",
+		"VLANIndex/e1-2-leaf1",
+		"VLANIndex/edge01",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+}
+
+func TestApplyDatabases(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := ipamv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("cannot add ipam to scheme: %v", err)
+	}
+	if err := vlanv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("cannot add vlan to scheme: %v", err)
+	}
+	cr, inv := getTestNetwork()
+	cases := map[string]struct {
+		existing    []client.Object
+		wantApplied []string
+	}{
+		"Missing": {
+			wantApplied: []string{"ran-rt", "default"},
+		},
+		"UpToDate": {
+			existing: []client.Object{
+				ipamv1alpha1.BuildNetworkInstance(metav1.ObjectMeta{Namespace: "default", Name: "ran-rt"}, ipamv1alpha1.NetworkInstanceSpec{Prefixes: cr.Spec.RoutingTables[0].Prefixes}, ipamv1alpha1.NetworkInstanceStatus{}),
+				ipamv1alpha1.BuildNetworkInstance(metav1.ObjectMeta{Namespace: "default", Name: "default"}, ipamv1alpha1.NetworkInstanceSpec{Prefixes: cr.Spec.RoutingTables[1].Prefixes}, ipamv1alpha1.NetworkInstanceStatus{}),
+			},
+		},
+		"Changed": {
+			existing: []client.Object{
+				ipamv1alpha1.BuildNetworkInstance(metav1.ObjectMeta{Namespace: "default", Name: "ran-rt"}, ipamv1alpha1.NetworkInstanceSpec{Prefixes: []ipamv1alpha1.Prefix{{Prefix: "10.1.0.0/16"}}}, ipamv1alpha1.NetworkInstanceStatus{}),
+				ipamv1alpha1.BuildNetworkInstance(metav1.ObjectMeta{Namespace: "default", Name: "default"}, ipamv1alpha1.NetworkInstanceSpec{Prefixes: cr.Spec.RoutingTables[1].Prefixes}, ipamv1alpha1.NetworkInstanceStatus{}),
+			},
+			wantApplied: []string{"ran-rt"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := &countingClient{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.existing...).Build()}
+			r := &reconciler{APIPatchingApplicator: resource.NewAPIPatchingApplicator(c)}
+			if err := r.applyDatabases(context.Background(), cr, inv); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantApplied, c.written); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+// countingClient records the names of the objects written through it
+type countingClient struct {
+	client.Client
+	written []string
+}
+
+func (r *countingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	r.written = append(r.written, obj.GetName())
+	return r.Client.Create(ctx, obj, opts...)
+}
+
+func (r *countingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	r.written = append(r.written, obj.GetName())
+	return r.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func TestWithoutDatabases(t *testing.T) {
+	res := resources.New(resource.APIPatchingApplicator{}, resources.Config{})
+	r := withoutDatabases{Resources: res}
+	r.AddNewResource(ipamv1alpha1.BuildNetworkInstance(metav1.ObjectMeta{Namespace: "default", Name: "ran-rt"}, ipamv1alpha1.NetworkInstanceSpec{}, ipamv1alpha1.NetworkInstanceStatus{}))
+	r.AddNewResource(vlanv1alpha1.BuildVLANIndex(metav1.ObjectMeta{Namespace: "default", Name: "edge01"}, vlanv1alpha1.VLANIndexSpec{}, vlanv1alpha1.VLANIndexStatus{}))
+	r.AddNewResource(configv1alpha1.BuildNetworkConfig(metav1.ObjectMeta{Namespace: "default", Name: "vpc-ran-leaf1"}, configv1alpha1.NetworkSpec{}, configv1alpha1.NetworkStatus{}))
+
+	got := []string{}
+	for ref := range res.GetNewResources() {
+		got = append(got, ref.Kind+"/"+ref.Name)
+	}
+	if diff := cmp.Diff([]string{"Network/vpc-ran-leaf1"}, got); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+}
+
+func TestInventorySelectedEndpoints(t *testing.T) {
+	_, inv := getTestNetwork()
+	itfce := infrav1alpha1.Interface{
+		Kind:     infrav1alpha1.InterfaceKindInterface,
+		Selector: &metav1.LabelSelector{MatchLabels: map[string]string{invv1alpha1.NephioClusterNameKey: "edge01"}},
+	}
+	selected, err := inv.getSelectedEndpoints(itfce)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(selected["edge01"]) != 2 {
+		t.Errorf("want 2 endpoints of edge01, got %v", selected)
+	}
+	// the endpoints are looked up once
+	inv.eps.Items = nil
+	again, err := inv.getSelectedEndpoints(itfce)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(selected, again); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+const (
+	changeOpAdd     = "add"
+	changeOpRemove  = "remove"
+	changeOpReplace = "replace"
+)
+
+// listKeys are the fields identifying the entries of the lists of the rendered
+// configs, so list entries are compared by key and not by position
+var listKeys = []string{"name", "index", "id", "sequence-id", "group-name", "peer-group-name", "afi-safi-name", "identifier", "ip-prefix", "ip"}
+
+// configChange is a change of a config, the path is a json pointer where list
+// entries are selected by their key, e.g. /interface[name=ethernet-1/1]/mtu
+type configChange struct {
+	Op   string          `json:"op"`
+	Path string          `json:"path"`
+	Old  json.RawMessage `json:"old,omitempty"`
+	New  json.RawMessage `json:"new,omitempty"`
+}
+
+// diffConfig returns the changes from the old to the new json config, an empty
+// config is a config without any value
+func diffConfig(oldConfig, newConfig []byte) ([]configChange, error) {
+	var o, n interface{}
+	if len(oldConfig) > 0 {
+		if err := json.Unmarshal(oldConfig, &o); err != nil {
+			return nil, fmt.Errorf("cannot unmarshal old config: %s", err.Error())
+		}
+	}
+	if len(newConfig) > 0 {
+		if err := json.Unmarshal(newConfig, &n); err != nil {
+			return nil, fmt.Errorf("cannot unmarshal new config: %s", err.Error())
+		}
+	}
+	changes := []configChange{}
+	diffValue("", o, n, &changes)
+	return changes, nil
+}
+
+func diffValue(path string, o, n interface{}, changes *[]configChange) {
+	switch {
+	case o == nil && n == nil:
+		return
+	case o == nil:
+		*changes = append(*changes, configChange{Op: changeOpAdd, Path: rootPath(path), New: mustMarshal(n)})
+		return
+	case n == nil:
+		*changes = append(*changes, configChange{Op: changeOpRemove, Path: rootPath(path), Old: mustMarshal(o)})
+		return
+	}
+
+	om, oIsMap := o.(map[string]interface{})
+	nm, nIsMap := n.(map[string]interface{})
+	if oIsMap && nIsMap {
+		keys := map[string]struct{}{}
+		for k := range om {
+			keys[k] = struct{}{}
+		}
+		for k := range nm {
+			keys[k] = struct{}{}
+		}
+		for _, k := range sortedKeys(keys) {
+			diffValue(path+"/"+k, om[k], nm[k], changes)
+		}
+		return
+	}
+
+	ol, oIsList := o.([]interface{})
+	nl, nIsList := n.([]interface{})
+	if oIsList && nIsList {
+		if key := getListKey(ol, nl); key != "" {
+			oEntries := getListEntries(ol, key)
+			nEntries := getListEntries(nl, key)
+			values := map[string]struct{}{}
+			for v := range oEntries {
+				values[v] = struct{}{}
+			}
+			for v := range nEntries {
+				values[v] = struct{}{}
+			}
+			for _, v := range sortedKeys(values) {
+				diffValue(fmt.Sprintf("%s[%s=%s]", path, key, v), oEntries[v], nEntries[v], changes)
+			}
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(o, n) {
+		*changes = append(*changes, configChange{Op: changeOpReplace, Path: rootPath(path), Old: mustMarshal(o), New: mustMarshal(n)})
+	}
+}
+
+// getListKey returns the key identifying the entries of both lists, the key is
+// a scalar field of every entry with a unique value in each list
+func getListKey(lists ...[]interface{}) string {
+	for _, key := range listKeys {
+		if isListKey(key, lists...) {
+			return key
+		}
+	}
+	return ""
+}
+
+func isListKey(key string, lists ...[]interface{}) bool {
+	for _, l := range lists {
+		values := map[string]struct{}{}
+		for _, entry := range l {
+			m, ok := entry.(map[string]interface{})
+			if !ok {
+				return false
+			}
+			v, ok := m[key]
+			if !ok {
+				return false
+			}
+			switch v.(type) {
+			case string, float64, bool:
+			default:
+				return false
+			}
+			if _, ok := values[fmt.Sprint(v)]; ok {
+				return false
+			}
+			values[fmt.Sprint(v)] = struct{}{}
+		}
+	}
+	return true
+}
+
+func getListEntries(l []interface{}, key string) map[string]interface{} {
+	entries := map[string]interface{}{}
+	for _, entry := range l {
+		entries[fmt.Sprint(entry.(map[string]interface{})[key])] = entry
+	}
+	return entries
+}
+
+func rootPath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+// mustMarshal marshals a value unmarshaled from json, which cannot fail
+func mustMarshal(v interface{}) json.RawMessage {
+	b, _ := json.Marshal(v)
+	return b
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiffConfig(t *testing.T) {
+	cases := map[string]struct {
+		old     string
+		new     string
+		want    []configChange
+		wantErr bool
+	}{
+		"Equal": {
+			old:  `{"interface":[{"name":"ethernet-1/1","mtu":9000}]}`,
+			new:  `{"interface":[{"mtu":9000,"name":"ethernet-1/1"}]}`,
+			want: []configChange{},
+		},
+		"Initial": {
+			old: ``,
+			new: `{"system":{"name":"leaf1"}}`,
+			want: []configChange{
+				{Op: changeOpAdd, Path: "/", New: json.RawMessage(`{"system":{"name":"leaf1"}}`)},
+			},
+		},
+		"Removed": {
+			old: `{"system":{"name":"leaf1"}}`,
+			new: ``,
+			want: []configChange{
+				{Op: changeOpRemove, Path: "/", Old: json.RawMessage(`{"system":{"name":"leaf1"}}`)},
+			},
+		},
+		"Replace": {
+			old: `{"system":{"name":"leaf1","mtu":1500}}`,
+			new: `{"system":{"name":"leaf1","mtu":9000}}`,
+			want: []configChange{
+				{Op: changeOpReplace, Path: "/system/mtu", Old: json.RawMessage(`1500`), New: json.RawMessage(`9000`)},
+			},
+		},
+		"KeyedList": {
+			old: `{"interface":[{"name":"ethernet-1/1","mtu":1500},{"name":"ethernet-1/2"}]}`,
+			new: `{"interface":[{"name":"ethernet-1/3"},{"name":"ethernet-1/1","mtu":9000}]}`,
+			want: []configChange{
+				{Op: changeOpReplace, Path: "/interface[name=ethernet-1/1]/mtu", Old: json.RawMessage(`1500`), New: json.RawMessage(`9000`)},
+				{Op: changeOpRemove, Path: "/interface[name=ethernet-1/2]", Old: json.RawMessage(`{"name":"ethernet-1/2"}`)},
+				{Op: changeOpAdd, Path: "/interface[name=ethernet-1/3]", New: json.RawMessage(`{"name":"ethernet-1/3"}`)},
+			},
+		},
+		"NestedKeyedList": {
+			old: `{"interface":[{"name":"ethernet-1/1","subinterface":[{"index":0}]}]}`,
+			new: `{"interface":[{"name":"ethernet-1/1","subinterface":[{"index":0},{"index":10,"vlan":10}]}]}`,
+			want: []configChange{
+				{Op: changeOpAdd, Path: "/interface[name=ethernet-1/1]/subinterface[index=10]", New: json.RawMessage(`{"index":10,"vlan":10}`)},
+			},
+		},
+		"UnkeyedList": {
+			old: `{"address":["10.0.0.1","10.0.0.2"]}`,
+			new: `{"address":["10.0.0.2"]}`,
+			want: []configChange{
+				{Op: changeOpReplace, Path: "/address", Old: json.RawMessage(`["10.0.0.1","10.0.0.2"]`), New: json.RawMessage(`["10.0.0.2"]`)},
+			},
+		},
+		"InvalidConfig": {
+			old:     `{`,
+			new:     `{}`,
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := diffConfig([]byte(tc.old), []byte(tc.new))
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("want error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+
+	"github.com/henderiw-nephio/network/pkg/endpoints"
+	"github.com/henderiw-nephio/network/pkg/nodes"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	invv1alpha1 "github.com/nokia/k8s-ipam/apis/inv/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// inventory are the endpoints and nodes of the topology of a Network. The
+// endpoints selected by an interface are looked up once per reconcile, the
+// validation, the allocation of the databases and the status share them.
+type inventory struct {
+	eps   *endpoints.Endpoints
+	nodes *nodes.Nodes
+
+	// selected are the endpoints per selector name, per formatted selector
+	selected map[string]map[string][]invv1alpha1.Endpoint
+}
+
+func newInventory(eps *endpoints.Endpoints, nodes *nodes.Nodes) *inventory {
+	return &inventory{
+		eps:      eps,
+		nodes:    nodes,
+		selected: map[string]map[string][]invv1alpha1.Endpoint{},
+	}
+}
+
+// getInventory returns the endpoints and nodes of the topology handled by a
+// registered provider
+func (r *reconciler) getInventory(ctx context.Context, topology string) (*inventory, error) {
+	eps, err := r.getProviderEndpoints(ctx, topology)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := r.getProviderNodes(ctx, topology)
+	if err != nil {
+		return nil, err
+	}
+	return newInventory(eps, nodes), nil
+}
+
+// getSelectedEndpoints returns the endpoints selected by the interface per
+// selector name
+func (r *inventory) getSelectedEndpoints(itfce infrav1alpha1.Interface) (map[string][]invv1alpha1.Endpoint, error) {
+	selector := endpoints.GetSelector(itfce)
+	key := metav1.FormatLabelSelector(selector)
+	if selected, ok := r.selected[key]; ok {
+		return selected, nil
+	}
+	selected, err := r.eps.GetSelectorEndpoints(selector)
+	if err != nil {
+		return nil, err
+	}
+	r.selected[key] = selected
+	return selected, nil
+}
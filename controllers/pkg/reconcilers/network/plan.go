@@ -0,0 +1,217 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	configv1alpha1 "github.com/henderiw-nephio/network/apis/config/v1alpha1"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	invv1alpha1 "github.com/nokia/k8s-ipam/apis/inv/v1alpha1"
+	resourcev1alpha1 "github.com/nokia/k8s-ipam/apis/resource/common/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// PlanAnnotationName enables the plan mode of the Network when "true". In
+	// plan mode the changes of the node configs are published as a plan and
+	// only rendered once the plan is approved.
+	PlanAnnotationName = "network.nephio.org/plan"
+	// ApprovedPlanAnnotationName approves the plan with the hash
+	ApprovedPlanAnnotationName = "network.nephio.org/approved-plan"
+
+	// ConditionReasonPlanPending is the reason of the Ready condition of a
+	// Network waiting for the approval of its plan
+	ConditionReasonPlanPending infrav1alpha1.ConditionReason = "PlanPending"
+
+	// planKey is the key of the plan in the data of the plan configmap
+	planKey = "plan.json"
+	// changesKey and configKey are the keys of the changes and the rendered
+	// config in the data of the configmap of a node
+	changesKey = "changes.json"
+	configKey  = "config.json"
+)
+
+// plan are the changes of the node configs of a Network
+type plan struct {
+	Hash  string     `json:"hash"`
+	Nodes []nodePlan `json:"nodes"`
+}
+
+// nodePlan are the changes of the config of a node against the config last
+// applied to the node
+type nodePlan struct {
+	NodeName   string         `json:"nodeName"`
+	ConfigName string         `json:"configName"`
+	Changes    []configChange `json:"changes"`
+
+	config []byte
+}
+
+// planIndex is the plan stored in the plan configmap, the changes and the
+// config of a node are stored in the configmap of the node so the size of
+// the plan configmap does not grow with the configs
+type planIndex struct {
+	Hash  string          `json:"hash"`
+	Nodes []nodePlanIndex `json:"nodes"`
+}
+
+type nodePlanIndex struct {
+	NodeName      string `json:"nodeName"`
+	ConfigName    string `json:"configName"`
+	ConfigMapName string `json:"configMapName"`
+	Changes       int    `json:"changes"`
+}
+
+func isPlanMode(cr client.Object) bool {
+	return cr.GetAnnotations()[PlanAnnotationName] == "true"
+}
+
+// isApproved returns true when the plan has no changes or was approved
+func (r *plan) isApproved(cr client.Object) bool {
+	for _, n := range r.Nodes {
+		if len(n.Changes) > 0 {
+			return cr.GetAnnotations()[ApprovedPlanAnnotationName] == r.Hash
+		}
+	}
+	return true
+}
+
+func getPlanConfigMapName(cr client.Object) string {
+	return fmt.Sprintf("%s-plan", cr.GetName())
+}
+
+func getNodePlanConfigMapName(cr client.Object, nodeName string) string {
+	return fmt.Sprintf("%s-plan-%s", cr.GetName(), nodeName)
+}
+
+// getPlan returns the plan of the node configs rendered for the Network
+// against the configs last applied to the nodes
+func (r *reconciler) getPlan() (*plan, error) {
+	configs := map[string]*configv1alpha1.Network{}
+	for _, o := range r.resources.GetNewResources() {
+		if nc, ok := o.(*configv1alpha1.Network); ok {
+			configs[nc.GetName()] = nc
+		}
+	}
+
+	p := &plan{Nodes: []nodePlan{}}
+	for _, name := range sortedKeys(configs) {
+		nc := configs[name]
+		changes, err := diffConfig(nc.Status.LastAppliedConfig.Raw, nc.Spec.Config.Raw)
+		if err != nil {
+			return nil, fmt.Errorf("cannot diff config %s: %s", name, err.Error())
+		}
+		p.Nodes = append(p.Nodes, nodePlan{
+			NodeName:   nc.GetLabels()[invv1alpha1.NephioNodeNameKey],
+			ConfigName: name,
+			Changes:    changes,
+			config:     nc.Spec.Config.Raw,
+		})
+	}
+
+	// the hash covers the rendered configs, so an approval only holds for the
+	// configs that were reviewed
+	h := sha256.New()
+	for _, n := range p.Nodes {
+		fmt.Fprintf(h, "%s\n%s\n", n.ConfigName, n.config)
+	}
+	p.Hash = hex.EncodeToString(h.Sum(nil))[:16]
+	return p, nil
+}
+
+// applyPlan publishes the plan in the plan configmap of the Network and the
+// changes and rendered config of every node in a configmap per node. The
+// configmaps of nodes no longer in the plan are deleted.
+func (r *reconciler) applyPlan(ctx context.Context, cr *infrav1alpha1.Network, p *plan) error {
+	index := &planIndex{Hash: p.Hash, Nodes: []nodePlanIndex{}}
+	cms := map[string]*corev1.ConfigMap{}
+	for _, n := range p.Nodes {
+		changes, err := json.MarshalIndent(n.Changes, "", "  ")
+		if err != nil {
+			return err
+		}
+		cm := buildPlanConfigMap(cr, getNodePlanConfigMapName(cr, n.NodeName), map[string]string{
+			changesKey: string(changes),
+			configKey:  string(n.config),
+		})
+		cms[cm.GetName()] = cm
+		index.Nodes = append(index.Nodes, nodePlanIndex{
+			NodeName:      n.NodeName,
+			ConfigName:    n.ConfigName,
+			ConfigMapName: cm.GetName(),
+			Changes:       len(n.Changes),
+		})
+	}
+	b, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	cm := buildPlanConfigMap(cr, getPlanConfigMapName(cr), map[string]string{planKey: string(b)})
+	cms[cm.GetName()] = cm
+
+	existing := &corev1.ConfigMapList{}
+	if err := r.List(ctx, existing, client.InNamespace(cr.Namespace), client.MatchingLabels(resourcev1alpha1.GetOwnerLabelsFromCR(cr))); err != nil {
+		return err
+	}
+	for i := range existing.Items {
+		if _, ok := cms[existing.Items[i].GetName()]; ok {
+			continue
+		}
+		if err := r.Delete(ctx, &existing.Items[i]); resource.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	for _, name := range sortedKeys(cms) {
+		if err := r.Apply(ctx, cms[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func buildPlanConfigMap(cr *infrav1alpha1.Network, name string, data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.Identifier(),
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       cr.Namespace,
+			Labels:          resourcev1alpha1.GetOwnerLabelsFromCR(cr),
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: cr.APIVersion, Kind: cr.Kind, Name: cr.Name, UID: cr.UID, Controller: pointer.Bool(true)}},
+		},
+		Data: data,
+	}
+}
+
+// planPending returns the ready condition of a Network waiting for the
+// approval of its plan
+func planPending(cr client.Object, p *plan) infrav1alpha1.Condition {
+	c := infrav1alpha1.Failed(fmt.Sprintf("plan %s awaits approval, see configmap %s", p.Hash, getPlanConfigMapName(cr)))
+	c.Reason = string(ConditionReasonPlanPending)
+	return c
+}
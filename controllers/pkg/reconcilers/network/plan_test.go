@@ -0,0 +1,236 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	configv1alpha1 "github.com/henderiw-nephio/network/apis/config/v1alpha1"
+	"github.com/henderiw-nephio/network/pkg/resources"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	invv1alpha1 "github.com/nokia/k8s-ipam/apis/inv/v1alpha1"
+	resourcev1alpha1 "github.com/nokia/k8s-ipam/apis/resource/common/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPlan(t *testing.T) {
+	cases := map[string]struct {
+		configs      []*configv1alpha1.Network
+		approvedPlan string
+		want         []nodePlan
+		wantApproved bool
+	}{
+		"Unchanged": {
+			configs: []*configv1alpha1.Network{
+				{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vpc-ran-leaf1", Labels: map[string]string{invv1alpha1.NephioNodeNameKey: "leaf1"}},
+					Spec:       configv1alpha1.NetworkSpec{Config: runtime.RawExtension{Raw: []byte(`{"system":{"name":"leaf1"}}`)}},
+					Status:     configv1alpha1.NetworkStatus{LastAppliedConfig: runtime.RawExtension{Raw: []byte(`{"system":{"name":"leaf1"}}`)}},
+				},
+			},
+			want: []nodePlan{
+				{NodeName: "leaf1", ConfigName: "vpc-ran-leaf1", Changes: []configChange{}},
+			},
+			wantApproved: true,
+		},
+		"Pending": {
+			configs: []*configv1alpha1.Network{
+				{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vpc-ran-leaf2", Labels: map[string]string{invv1alpha1.NephioNodeNameKey: "leaf2"}},
+					Spec:       configv1alpha1.NetworkSpec{Config: runtime.RawExtension{Raw: []byte(`{"system":{"name":"leaf2"}}`)}},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vpc-ran-leaf1", Labels: map[string]string{invv1alpha1.NephioNodeNameKey: "leaf1"}},
+					Spec:       configv1alpha1.NetworkSpec{Config: runtime.RawExtension{Raw: []byte(`{"system":{"name":"leaf1","mtu":9000}}`)}},
+					Status:     configv1alpha1.NetworkStatus{LastAppliedConfig: runtime.RawExtension{Raw: []byte(`{"system":{"name":"leaf1"}}`)}},
+				},
+			},
+			want: []nodePlan{
+				{NodeName: "leaf1", ConfigName: "vpc-ran-leaf1", Changes: []configChange{
+					{Op: changeOpAdd, Path: "/system/mtu", New: json.RawMessage(`9000`)},
+				}},
+				{NodeName: "leaf2", ConfigName: "vpc-ran-leaf2", Changes: []configChange{
+					{Op: changeOpAdd, Path: "/", New: json.RawMessage(`{"system":{"name":"leaf2"}}`)},
+				}},
+			},
+			wantApproved: false,
+		},
+		"Approved": {
+			configs: []*configv1alpha1.Network{
+				{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vpc-ran-leaf1", Labels: map[string]string{invv1alpha1.NephioNodeNameKey: "leaf1"}},
+					Spec:       configv1alpha1.NetworkSpec{Config: runtime.RawExtension{Raw: []byte(`{"system":{"name":"leaf1","mtu":9000}}`)}},
+					Status:     configv1alpha1.NetworkStatus{LastAppliedConfig: runtime.RawExtension{Raw: []byte(`{"system":{"name":"leaf1"}}`)}},
+				},
+			},
+			approvedPlan: "<hash>",
+			want: []nodePlan{
+				{NodeName: "leaf1", ConfigName: "vpc-ran-leaf1", Changes: []configChange{
+					{Op: changeOpAdd, Path: "/system/mtu", New: json.RawMessage(`9000`)},
+				}},
+			},
+			wantApproved: true,
+		},
+		"OutdatedApproval": {
+			configs: []*configv1alpha1.Network{
+				{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vpc-ran-leaf1", Labels: map[string]string{invv1alpha1.NephioNodeNameKey: "leaf1"}},
+					Spec:       configv1alpha1.NetworkSpec{Config: runtime.RawExtension{Raw: []byte(`{"system":{"name":"leaf1","mtu":9000}}`)}},
+					Status:     configv1alpha1.NetworkStatus{LastAppliedConfig: runtime.RawExtension{Raw: []byte(`{"system":{"name":"leaf1"}}`)}},
+				},
+			},
+			approvedPlan: "0123456789abcdef",
+			want: []nodePlan{
+				{NodeName: "leaf1", ConfigName: "vpc-ran-leaf1", Changes: []configChange{
+					{Op: changeOpAdd, Path: "/system/mtu", New: json.RawMessage(`9000`)},
+				}},
+			},
+			wantApproved: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := &reconciler{resources: resources.New(resource.APIPatchingApplicator{}, resources.Config{})}
+			for _, nc := range tc.configs {
+				r.resources.AddNewResource(nc)
+			}
+			p, err := r.getPlan()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, p.Nodes, cmp.AllowUnexported(nodePlan{}), cmp.FilterPath(func(p cmp.Path) bool {
+				return p.Last().String() == ".config"
+			}, cmp.Ignore())); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+
+			cr, _ := getTestNetwork()
+			cr.SetAnnotations(map[string]string{PlanAnnotationName: "true"})
+			if tc.approvedPlan == "<hash>" {
+				cr.Annotations[ApprovedPlanAnnotationName] = p.Hash
+			} else if tc.approvedPlan != "" {
+				cr.Annotations[ApprovedPlanAnnotationName] = tc.approvedPlan
+			}
+			if got := p.isApproved(cr); got != tc.wantApproved {
+				t.Errorf("isApproved: want %t, got %t", tc.wantApproved, got)
+			}
+		})
+	}
+}
+
+func TestPlanHash(t *testing.T) {
+	getHash := func(config string) string {
+		r := &reconciler{resources: resources.New(resource.APIPatchingApplicator{}, resources.Config{})}
+		r.resources.AddNewResource(&configv1alpha1.Network{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vpc-ran-leaf1", Labels: map[string]string{invv1alpha1.NephioNodeNameKey: "leaf1"}},
+			Spec:       configv1alpha1.NetworkSpec{Config: runtime.RawExtension{Raw: []byte(config)}},
+			Status:     configv1alpha1.NetworkStatus{LastAppliedConfig: runtime.RawExtension{Raw: []byte(`{}`)}},
+		})
+		p, err := r.getPlan()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return p.Hash
+	}
+	if getHash(`{"mtu":9000}`) != getHash(`{"mtu":9000}`) {
+		t.Errorf("want the same hash for the same configs")
+	}
+	if getHash(`{"mtu":9000}`) == getHash(`{"mtu":1500}`) {
+		t.Errorf("want a different hash for different configs")
+	}
+}
+
+func TestApplyPlan(t *testing.T) {
+	cr, _ := getTestNetwork()
+	cr.UID = types.UID("1234")
+	c := fake.NewClientBuilder().WithObjects(
+		// the configmap of a node no longer in the plan
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vpc-ran-plan-leaf2", Labels: resourcev1alpha1.GetOwnerLabelsFromCR(cr)}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "other"}},
+	).Build()
+	r := &reconciler{
+		APIPatchingApplicator: resource.NewAPIPatchingApplicator(c),
+		resources:             resources.New(resource.APIPatchingApplicator{}, resources.Config{}),
+	}
+	r.resources.AddNewResource(&configv1alpha1.Network{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vpc-ran-leaf1", Labels: map[string]string{invv1alpha1.NephioNodeNameKey: "leaf1"}},
+		Spec:       configv1alpha1.NetworkSpec{Config: runtime.RawExtension{Raw: []byte(`{"system":{"name":"leaf1"}}`)}},
+	})
+	p, err := r.getPlan()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+	if err := r.applyPlan(ctx, cr, p); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: "default", Name: "vpc-ran-plan"}, cm); err != nil {
+		t.Fatalf("cannot get plan configmap: %v", err)
+	}
+	got := &planIndex{}
+	if err := json.Unmarshal([]byte(cm.Data[planKey]), got); err != nil {
+		t.Fatalf("cannot unmarshal plan: %v", err)
+	}
+	want := &planIndex{Hash: p.Hash, Nodes: []nodePlanIndex{
+		{NodeName: "leaf1", ConfigName: "vpc-ran-leaf1", ConfigMapName: "vpc-ran-plan-leaf1", Changes: 1},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+	if len(cm.OwnerReferences) != 1 || cm.OwnerReferences[0].UID != cr.UID {
+		t.Errorf("want the plan owned by the network, got %v", cm.OwnerReferences)
+	}
+
+	if err := r.Get(ctx, types.NamespacedName{Namespace: "default", Name: "vpc-ran-plan-leaf1"}, cm); err != nil {
+		t.Fatalf("cannot get node plan configmap: %v", err)
+	}
+	gotChanges := []configChange{}
+	if err := json.Unmarshal([]byte(cm.Data[changesKey]), &gotChanges); err != nil {
+		t.Fatalf("cannot unmarshal changes: %v", err)
+	}
+	// the changes are indented in the configmap
+	wantJSON, _ := json.Marshal(p.Nodes[0].Changes)
+	gotJSON, _ := json.Marshal(gotChanges)
+	if diff := cmp.Diff(string(wantJSON), string(gotJSON)); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(`{"system":{"name":"leaf1"}}`, cm.Data[configKey]); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+
+	cms := &corev1.ConfigMapList{}
+	if err := r.List(ctx, cms); err != nil {
+		t.Fatalf("cannot list configmaps: %v", err)
+	}
+	gotNames := []string{}
+	for _, cm := range cms.Items {
+		gotNames = append(gotNames, cm.GetName())
+	}
+	if diff := cmp.Diff([]string{"other", "vpc-ran-plan", "vpc-ran-plan-leaf1"}, gotNames); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	invv1alpha1 "github.com/nokia/k8s-ipam/apis/inv/v1alpha1"
+	"github.com/srl-labs/ygotsrl/v22"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Provider renders the device config of the nodes of a vendor. The network
+// library models the config of every node with the SR Linux yang model, each
+// provider translates it to the config its devices accept.
+type Provider interface {
+	// Name is the value of the provider label of the endpoints and nodes
+	// handled by the provider
+	Name() string
+	// Render returns the config of the device
+	Render(device *ygotsrl.Device) ([]byte, error)
+}
+
+var (
+	providersLock sync.RWMutex
+	providers     = map[string]Provider{}
+)
+
+// RegisterProvider registers the provider of a vendor, the network reconciler
+// only handles the endpoints and nodes of registered providers
+func RegisterProvider(p Provider) {
+	providersLock.Lock()
+	defer providersLock.Unlock()
+	providers[p.Name()] = p
+}
+
+// getProvider returns the registered provider with the name
+func getProvider(name string) (Provider, error) {
+	providersLock.RLock()
+	defer providersLock.RUnlock()
+	p, ok := providers[name]
+	if !ok {
+		return nil, fmt.Errorf("provider %q is not registered", name)
+	}
+	return p, nil
+}
+
+// isRegisteredProvider returns true when the labels select a registered
+// provider
+func isRegisteredProvider(l map[string]string) bool {
+	_, err := getProvider(l[invv1alpha1.NephioProviderKey])
+	return err == nil
+}
+
+// getProviderNames returns the sorted names of the registered providers
+func getProviderNames() []string {
+	providersLock.RLock()
+	defer providersLock.RUnlock()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// getProviderSelector selects the endpoints or nodes of the topology handled
+// by a registered provider
+func getProviderSelector(topology string) (client.MatchingLabelsSelector, error) {
+	providerReq, err := labels.NewRequirement(invv1alpha1.NephioProviderKey, selection.In, getProviderNames())
+	if err != nil {
+		return client.MatchingLabelsSelector{}, err
+	}
+	topologyReq, err := labels.NewRequirement(invv1alpha1.NephioTopologyKey, selection.Equals, []string{topology})
+	if err != nil {
+		return client.MatchingLabelsSelector{}, err
+	}
+	return client.MatchingLabelsSelector{Selector: labels.NewSelector().Add(*providerReq, *topologyReq)}, nil
+}
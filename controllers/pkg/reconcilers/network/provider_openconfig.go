@@ -0,0 +1,610 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/srl-labs/ygotsrl/v22"
+)
+
+const (
+	openConfigProvider = "openconfig.net"
+
+	systemInterfaceName = "system0"
+	irbInterfaceName    = "irb0"
+)
+
+func init() {
+	RegisterProvider(&ocProvider{})
+}
+
+// ocProvider renders the config of devices supporting the OpenConfig models.
+// Interfaces, network instances, bgp and routing policies are translated; SR
+// Linux specific settings, like the anycast gateway of the irb interface, have
+// no OpenConfig counterpart and are left out.
+type ocProvider struct{}
+
+func (r *ocProvider) Name() string {
+	return openConfigProvider
+}
+
+// Render returns the device config as RFC7951 json of the OpenConfig models
+func (r *ocProvider) Render(device *ygotsrl.Device) ([]byte, error) {
+	d := &ocDevice{}
+	if len(device.Interface) > 0 {
+		d.Interfaces = &ocInterfaces{}
+		for _, name := range sortedKeys(device.Interface) {
+			itfce, err := toOCInterface(name, device.Interface[name])
+			if err != nil {
+				return nil, err
+			}
+			d.Interfaces.Interface = append(d.Interfaces.Interface, itfce)
+		}
+	}
+	if len(device.NetworkInstance) > 0 {
+		d.NetworkInstances = &ocNetworkInstances{}
+		for _, name := range sortedKeys(device.NetworkInstance) {
+			ni, err := toOCNetworkInstance(name, device.NetworkInstance[name])
+			if err != nil {
+				return nil, err
+			}
+			d.NetworkInstances.NetworkInstance = append(d.NetworkInstances.NetworkInstance, ni)
+		}
+	}
+	if device.RoutingPolicy != nil {
+		rp, err := toOCRoutingPolicy(device.RoutingPolicy)
+		if err != nil {
+			return nil, err
+		}
+		d.RoutingPolicy = rp
+	}
+	return json.MarshalIndent(d, "", "  ")
+}
+
+type ocDevice struct {
+	Interfaces       *ocInterfaces       `json:"openconfig-interfaces:interfaces,omitempty"`
+	NetworkInstances *ocNetworkInstances `json:"openconfig-network-instance:network-instances,omitempty"`
+	RoutingPolicy    *ocRoutingPolicy    `json:"openconfig-routing-policy:routing-policy,omitempty"`
+}
+
+type ocInterfaces struct {
+	Interface []*ocInterface `json:"interface"`
+}
+
+type ocInterface struct {
+	Name          string            `json:"name"`
+	Config        ocInterfaceConfig `json:"config"`
+	Subinterfaces *ocSubinterfaces  `json:"subinterfaces,omitempty"`
+}
+
+type ocInterfaceConfig struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
+}
+
+type ocSubinterfaces struct {
+	Subinterface []*ocSubinterface `json:"subinterface"`
+}
+
+type ocSubinterface struct {
+	Index  uint32               `json:"index"`
+	Config ocSubinterfaceConfig `json:"config"`
+	Vlan   *ocVlan              `json:"openconfig-vlan:vlan,omitempty"`
+	Ipv4   *ocIP                `json:"openconfig-if-ip:ipv4,omitempty"`
+	Ipv6   *ocIP                `json:"openconfig-if-ip:ipv6,omitempty"`
+}
+
+type ocSubinterfaceConfig struct {
+	Index   uint32 `json:"index"`
+	Enabled bool   `json:"enabled"`
+}
+
+type ocVlan struct {
+	Match ocVlanMatch `json:"match"`
+}
+
+type ocVlanMatch struct {
+	SingleTagged ocVlanSingleTagged `json:"single-tagged"`
+}
+
+type ocVlanSingleTagged struct {
+	Config ocVlanConfig `json:"config"`
+}
+
+type ocVlanConfig struct {
+	VlanID uint16 `json:"vlan-id"`
+}
+
+type ocIP struct {
+	Addresses ocAddresses `json:"addresses"`
+}
+
+type ocAddresses struct {
+	Address []*ocAddress `json:"address"`
+}
+
+type ocAddress struct {
+	IP     string          `json:"ip"`
+	Config ocAddressConfig `json:"config"`
+}
+
+type ocAddressConfig struct {
+	IP           string `json:"ip"`
+	PrefixLength int    `json:"prefix-length"`
+}
+
+type ocNetworkInstances struct {
+	NetworkInstance []*ocNetworkInstance `json:"network-instance"`
+}
+
+type ocNetworkInstance struct {
+	Name       string                  `json:"name"`
+	Config     ocNetworkInstanceConfig `json:"config"`
+	Interfaces *ocNiInterfaces         `json:"interfaces,omitempty"`
+	Protocols  *ocProtocols            `json:"protocols,omitempty"`
+}
+
+type ocNetworkInstanceConfig struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type ocNiInterfaces struct {
+	Interface []*ocNiInterface `json:"interface"`
+}
+
+type ocNiInterface struct {
+	ID     string              `json:"id"`
+	Config ocNiInterfaceConfig `json:"config"`
+}
+
+type ocNiInterfaceConfig struct {
+	ID           string `json:"id"`
+	Interface    string `json:"interface"`
+	Subinterface uint32 `json:"subinterface"`
+}
+
+type ocProtocols struct {
+	Protocol []*ocProtocol `json:"protocol"`
+}
+
+type ocProtocol struct {
+	Identifier string           `json:"identifier"`
+	Name       string           `json:"name"`
+	Config     ocProtocolConfig `json:"config"`
+	Bgp        *ocBgp           `json:"bgp,omitempty"`
+}
+
+type ocProtocolConfig struct {
+	Identifier string `json:"identifier"`
+	Name       string `json:"name"`
+}
+
+type ocBgp struct {
+	Global     ocBgpGlobal      `json:"global"`
+	PeerGroups *ocBgpPeerGroups `json:"peer-groups,omitempty"`
+}
+
+type ocBgpGlobal struct {
+	Config   ocBgpGlobalConfig `json:"config"`
+	AfiSafis *ocAfiSafis       `json:"afi-safis,omitempty"`
+}
+
+type ocBgpGlobalConfig struct {
+	As       uint32 `json:"as"`
+	RouterID string `json:"router-id,omitempty"`
+}
+
+type ocAfiSafis struct {
+	AfiSafi []*ocAfiSafi `json:"afi-safi"`
+}
+
+type ocAfiSafi struct {
+	AfiSafiName string          `json:"afi-safi-name"`
+	Config      ocAfiSafiConfig `json:"config"`
+}
+
+type ocAfiSafiConfig struct {
+	AfiSafiName string `json:"afi-safi-name"`
+	Enabled     bool   `json:"enabled"`
+}
+
+type ocBgpPeerGroups struct {
+	PeerGroup []*ocBgpPeerGroup `json:"peer-group"`
+}
+
+type ocBgpPeerGroup struct {
+	PeerGroupName string               `json:"peer-group-name"`
+	Config        ocBgpPeerGroupConfig `json:"config"`
+	AfiSafis      *ocAfiSafis          `json:"afi-safis,omitempty"`
+	ApplyPolicy   *ocApplyPolicy       `json:"apply-policy,omitempty"`
+}
+
+type ocBgpPeerGroupConfig struct {
+	PeerGroupName string `json:"peer-group-name"`
+	PeerAs        uint32 `json:"peer-as,omitempty"`
+}
+
+type ocApplyPolicy struct {
+	Config ocApplyPolicyConfig `json:"config"`
+}
+
+type ocApplyPolicyConfig struct {
+	ImportPolicy []string `json:"import-policy,omitempty"`
+	ExportPolicy []string `json:"export-policy,omitempty"`
+}
+
+type ocRoutingPolicy struct {
+	DefinedSets       *ocDefinedSets       `json:"defined-sets,omitempty"`
+	PolicyDefinitions *ocPolicyDefinitions `json:"policy-definitions,omitempty"`
+}
+
+type ocDefinedSets struct {
+	PrefixSets ocPrefixSets `json:"prefix-sets"`
+}
+
+type ocPrefixSets struct {
+	PrefixSet []*ocPrefixSet `json:"prefix-set"`
+}
+
+type ocPrefixSet struct {
+	Name     string            `json:"name"`
+	Config   ocPrefixSetConfig `json:"config"`
+	Prefixes ocPrefixes        `json:"prefixes"`
+}
+
+type ocPrefixSetConfig struct {
+	Name string `json:"name"`
+	Mode string `json:"mode"`
+}
+
+type ocPrefixes struct {
+	Prefix []*ocPrefix `json:"prefix"`
+}
+
+type ocPrefix struct {
+	IPPrefix        string         `json:"ip-prefix"`
+	MasklengthRange string         `json:"masklength-range"`
+	Config          ocPrefixConfig `json:"config"`
+}
+
+type ocPrefixConfig struct {
+	IPPrefix        string `json:"ip-prefix"`
+	MasklengthRange string `json:"masklength-range"`
+}
+
+type ocPolicyDefinitions struct {
+	PolicyDefinition []*ocPolicyDefinition `json:"policy-definition"`
+}
+
+type ocPolicyDefinition struct {
+	Name       string                   `json:"name"`
+	Config     ocPolicyDefinitionConfig `json:"config"`
+	Statements ocStatements             `json:"statements"`
+}
+
+type ocPolicyDefinitionConfig struct {
+	Name string `json:"name"`
+}
+
+type ocStatements struct {
+	Statement []*ocStatement `json:"statement"`
+}
+
+type ocStatement struct {
+	Name       string            `json:"name"`
+	Config     ocStatementConfig `json:"config"`
+	Conditions *ocConditions     `json:"conditions,omitempty"`
+	Actions    ocActions         `json:"actions"`
+}
+
+type ocStatementConfig struct {
+	Name string `json:"name"`
+}
+
+type ocConditions struct {
+	MatchPrefixSet ocMatchPrefixSet `json:"match-prefix-set"`
+}
+
+type ocMatchPrefixSet struct {
+	Config ocMatchPrefixSetConfig `json:"config"`
+}
+
+type ocMatchPrefixSetConfig struct {
+	PrefixSet string `json:"prefix-set"`
+}
+
+type ocActions struct {
+	Config ocActionsConfig `json:"config"`
+}
+
+type ocActionsConfig struct {
+	PolicyResult string `json:"policy-result"`
+}
+
+func toOCInterface(name string, itfce *ygotsrl.SrlNokiaInterfaces_Interface) (*ocInterface, error) {
+	oc := &ocInterface{
+		Name:   name,
+		Config: ocInterfaceConfig{Name: name, Type: toOCInterfaceType(name), Enabled: true},
+	}
+	if len(itfce.Subinterface) == 0 {
+		return oc, nil
+	}
+	oc.Subinterfaces = &ocSubinterfaces{}
+	for _, index := range sortedKeys(itfce.Subinterface) {
+		si := itfce.Subinterface[index]
+		ocSi := &ocSubinterface{
+			Index:  index,
+			Config: ocSubinterfaceConfig{Index: index, Enabled: true},
+		}
+		if si.Vlan != nil && si.Vlan.Encap != nil && si.Vlan.Encap.SingleTagged != nil {
+			vlanID, ok := si.Vlan.Encap.SingleTagged.VlanId.(ygotsrl.UnionUint16)
+			if !ok {
+				return nil, fmt.Errorf("interface %s.%d: unsupported vlan id %v", name, index, si.Vlan.Encap.SingleTagged.VlanId)
+			}
+			ocSi.Vlan = &ocVlan{Match: ocVlanMatch{SingleTagged: ocVlanSingleTagged{Config: ocVlanConfig{VlanID: uint16(vlanID)}}}}
+		}
+		if si.Ipv4 != nil {
+			prefixes := []string{}
+			for prefix := range si.Ipv4.Address {
+				prefixes = append(prefixes, prefix)
+			}
+			ip, err := toOCIP(prefixes)
+			if err != nil {
+				return nil, fmt.Errorf("interface %s.%d: %s", name, index, err.Error())
+			}
+			ocSi.Ipv4 = ip
+		}
+		if si.Ipv6 != nil {
+			prefixes := []string{}
+			for prefix := range si.Ipv6.Address {
+				prefixes = append(prefixes, prefix)
+			}
+			ip, err := toOCIP(prefixes)
+			if err != nil {
+				return nil, fmt.Errorf("interface %s.%d: %s", name, index, err.Error())
+			}
+			ocSi.Ipv6 = ip
+		}
+		oc.Subinterfaces.Subinterface = append(oc.Subinterfaces.Subinterface, ocSi)
+	}
+	return oc, nil
+}
+
+func toOCInterfaceType(name string) string {
+	switch name {
+	case systemInterfaceName:
+		return "iana-if-type:softwareLoopback"
+	case irbInterfaceName:
+		return "iana-if-type:l3ipvlan"
+	default:
+		return "iana-if-type:ethernetCsmacd"
+	}
+}
+
+// toOCIP splits the prefixes, e.g. 10.0.0.1/24, into the address and its
+// prefix length
+func toOCIP(prefixes []string) (*ocIP, error) {
+	sort.Strings(prefixes)
+	ip := &ocIP{}
+	for _, prefix := range prefixes {
+		pfx, err := netip.ParsePrefix(prefix)
+		if err != nil {
+			return nil, err
+		}
+		addr := pfx.Addr().String()
+		ip.Addresses.Address = append(ip.Addresses.Address, &ocAddress{
+			IP:     addr,
+			Config: ocAddressConfig{IP: addr, PrefixLength: pfx.Bits()},
+		})
+	}
+	return ip, nil
+}
+
+func toOCNetworkInstance(name string, ni *ygotsrl.SrlNokiaNetworkInstance_NetworkInstance) (*ocNetworkInstance, error) {
+	niType, err := toOCNetworkInstanceType(ni.Type)
+	if err != nil {
+		return nil, fmt.Errorf("network instance %s: %s", name, err.Error())
+	}
+	oc := &ocNetworkInstance{
+		Name:   name,
+		Config: ocNetworkInstanceConfig{Name: name, Type: niType},
+	}
+	if len(ni.Interface) > 0 {
+		oc.Interfaces = &ocNiInterfaces{}
+		for _, id := range sortedKeys(ni.Interface) {
+			// the interfaces of the network instance are named <interface>.<subinterface>
+			idx := strings.LastIndex(id, ".")
+			if idx < 0 {
+				return nil, fmt.Errorf("network instance %s: invalid interface %s", name, id)
+			}
+			index, err := strconv.ParseUint(id[idx+1:], 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("network instance %s: invalid interface %s", name, id)
+			}
+			oc.Interfaces.Interface = append(oc.Interfaces.Interface, &ocNiInterface{
+				ID:     id,
+				Config: ocNiInterfaceConfig{ID: id, Interface: id[:idx], Subinterface: uint32(index)},
+			})
+		}
+	}
+	if ni.Protocols != nil && ni.Protocols.Bgp != nil {
+		oc.Protocols = &ocProtocols{Protocol: []*ocProtocol{{
+			Identifier: "openconfig-policy-types:BGP",
+			Name:       "BGP",
+			Config:     ocProtocolConfig{Identifier: "openconfig-policy-types:BGP", Name: "BGP"},
+			Bgp:        toOCBgp(ni.Protocols.Bgp),
+		}}}
+	}
+	return oc, nil
+}
+
+func toOCNetworkInstanceType(niType ygotsrl.E_SrlNokiaNetworkInstance_NiType) (string, error) {
+	switch niType {
+	case ygotsrl.SrlNokiaNetworkInstance_NiType_default:
+		return "openconfig-network-instance-types:DEFAULT_INSTANCE", nil
+	case ygotsrl.SrlNokiaNetworkInstance_NiType_ip_vrf:
+		return "openconfig-network-instance-types:L3VRF", nil
+	case ygotsrl.SrlNokiaNetworkInstance_NiType_mac_vrf:
+		return "openconfig-network-instance-types:L2VSI", nil
+	default:
+		return "", fmt.Errorf("unsupported network instance type %s", niType.String())
+	}
+}
+
+func toOCBgp(bgp *ygotsrl.SrlNokiaNetworkInstance_NetworkInstance_Protocols_Bgp) *ocBgp {
+	oc := &ocBgp{}
+	if bgp.AutonomousSystem != nil {
+		oc.Global.Config.As = *bgp.AutonomousSystem
+	}
+	if bgp.RouterId != nil {
+		oc.Global.Config.RouterID = *bgp.RouterId
+	}
+	oc.Global.AfiSafis = toOCAfiSafis(bgp.Ipv4Unicast != nil, bgp.Ipv6Unicast != nil, bgp.Evpn != nil)
+	if len(bgp.Group) > 0 {
+		oc.PeerGroups = &ocBgpPeerGroups{}
+		for _, name := range sortedKeys(bgp.Group) {
+			group := bgp.Group[name]
+			pg := &ocBgpPeerGroup{
+				PeerGroupName: name,
+				Config:        ocBgpPeerGroupConfig{PeerGroupName: name},
+				AfiSafis:      toOCAfiSafis(group.Ipv4Unicast != nil, group.Ipv6Unicast != nil, group.Evpn != nil),
+			}
+			if group.PeerAs != nil {
+				pg.Config.PeerAs = *group.PeerAs
+			}
+			if group.ImportPolicy != nil || group.ExportPolicy != nil {
+				pg.ApplyPolicy = &ocApplyPolicy{}
+				if group.ImportPolicy != nil {
+					pg.ApplyPolicy.Config.ImportPolicy = []string{*group.ImportPolicy}
+				}
+				if group.ExportPolicy != nil {
+					pg.ApplyPolicy.Config.ExportPolicy = []string{*group.ExportPolicy}
+				}
+			}
+			oc.PeerGroups.PeerGroup = append(oc.PeerGroups.PeerGroup, pg)
+		}
+	}
+	return oc
+}
+
+func toOCAfiSafis(ipv4, ipv6, evpn bool) *ocAfiSafis {
+	afiSafis := &ocAfiSafis{}
+	for _, afiSafi := range []struct {
+		name    string
+		enabled bool
+	}{
+		{name: "openconfig-bgp-types:IPV4_UNICAST", enabled: ipv4},
+		{name: "openconfig-bgp-types:IPV6_UNICAST", enabled: ipv6},
+		{name: "openconfig-bgp-types:L2VPN_EVPN", enabled: evpn},
+	} {
+		if afiSafi.enabled {
+			afiSafis.AfiSafi = append(afiSafis.AfiSafi, &ocAfiSafi{
+				AfiSafiName: afiSafi.name,
+				Config:      ocAfiSafiConfig{AfiSafiName: afiSafi.name, Enabled: true},
+			})
+		}
+	}
+	if len(afiSafis.AfiSafi) == 0 {
+		return nil
+	}
+	return afiSafis
+}
+
+func toOCRoutingPolicy(rp *ygotsrl.SrlNokiaRoutingPolicy_RoutingPolicy) (*ocRoutingPolicy, error) {
+	oc := &ocRoutingPolicy{}
+	if len(rp.PrefixSet) > 0 {
+		oc.DefinedSets = &ocDefinedSets{}
+		for _, name := range sortedKeys(rp.PrefixSet) {
+			ps := &ocPrefixSet{Name: name, Config: ocPrefixSetConfig{Name: name, Mode: "IPV4"}}
+			prefixes := rp.PrefixSet[name].Prefix
+			keys := make([]ygotsrl.SrlNokiaRoutingPolicy_RoutingPolicy_PrefixSet_Prefix_Key, 0, len(prefixes))
+			for key := range prefixes {
+				keys = append(keys, key)
+			}
+			sort.Slice(keys, func(i, j int) bool {
+				if keys[i].IpPrefix != keys[j].IpPrefix {
+					return keys[i].IpPrefix < keys[j].IpPrefix
+				}
+				return keys[i].MaskLengthRange < keys[j].MaskLengthRange
+			})
+			for _, key := range keys {
+				pfx, err := netip.ParsePrefix(key.IpPrefix)
+				if err != nil {
+					return nil, fmt.Errorf("prefix set %s: %s", name, err.Error())
+				}
+				if pfx.Addr().Is6() {
+					ps.Config.Mode = "IPV6"
+				}
+				ps.Prefixes.Prefix = append(ps.Prefixes.Prefix, &ocPrefix{
+					IPPrefix:        key.IpPrefix,
+					MasklengthRange: key.MaskLengthRange,
+					Config:          ocPrefixConfig{IPPrefix: key.IpPrefix, MasklengthRange: key.MaskLengthRange},
+				})
+			}
+			oc.DefinedSets.PrefixSets.PrefixSet = append(oc.DefinedSets.PrefixSets.PrefixSet, ps)
+		}
+	}
+	if len(rp.Policy) > 0 {
+		oc.PolicyDefinitions = &ocPolicyDefinitions{}
+		for _, name := range sortedKeys(rp.Policy) {
+			pd := &ocPolicyDefinition{Name: name, Config: ocPolicyDefinitionConfig{Name: name}}
+			statements := rp.Policy[name].Statement
+			for _, seq := range sortedKeys(statements) {
+				statement := statements[seq]
+				id := strconv.FormatUint(uint64(seq), 10)
+				st := &ocStatement{Name: id, Config: ocStatementConfig{Name: id}}
+				if statement.Match != nil && statement.Match.PrefixSet != nil {
+					st.Conditions = &ocConditions{MatchPrefixSet: ocMatchPrefixSet{Config: ocMatchPrefixSetConfig{PrefixSet: *statement.Match.PrefixSet}}}
+				}
+				result := ygotsrl.SrlNokiaPolicyTypes_PolicyResultType_UNSET
+				if statement.Action != nil {
+					result = statement.Action.PolicyResult
+				}
+				switch result {
+				case ygotsrl.SrlNokiaPolicyTypes_PolicyResultType_accept:
+					st.Actions.Config.PolicyResult = "ACCEPT_ROUTE"
+				case ygotsrl.SrlNokiaPolicyTypes_PolicyResultType_reject:
+					st.Actions.Config.PolicyResult = "REJECT_ROUTE"
+				default:
+					return nil, fmt.Errorf("policy %s statement %s: unsupported policy result %s", name, id, result.String())
+				}
+				pd.Statements.Statement = append(pd.Statements.Statement, st)
+			}
+			oc.PolicyDefinitions.PolicyDefinition = append(oc.PolicyDefinitions.PolicyDefinition, pd)
+		}
+	}
+	return oc, nil
+}
+
+// sortedKeys returns the keys of the map in order, so the rendered config is
+// stable across reconciles
+func sortedKeys[K string | uint32, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"github.com/openconfig/ygot/ygot"
+	"github.com/srl-labs/ygotsrl/v22"
+)
+
+const nokiaSRLProvider = "srl.nokia.com"
+
+func init() {
+	RegisterProvider(&srlProvider{})
+}
+
+// srlProvider renders the config of SR Linux devices
+type srlProvider struct{}
+
+func (r *srlProvider) Name() string {
+	return nokiaSRLProvider
+}
+
+// Render returns the device config as RFC7951 json of the SR Linux yang model
+func (r *srlProvider) Render(device *ygotsrl.Device) ([]byte, error) {
+	j, err := ygot.EmitJSON(device, &ygot.EmitJSONConfig{
+		Format: ygot.RFC7951,
+		Indent: "  ",
+		RFC7951Config: &ygot.RFC7951JSONConfig{
+			AppendModuleName: true,
+		},
+		SkipValidation: false,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []byte(j), nil
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	invv1alpha1 "github.com/nokia/k8s-ipam/apis/inv/v1alpha1"
+	"github.com/srl-labs/ygotsrl/v22"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/pointer"
+)
+
+func TestOpenConfigRender(t *testing.T) {
+	d := &ygotsrl.Device{}
+	si := d.GetOrCreateInterface("ethernet-1/1").GetOrCreateSubinterface(10)
+	si.GetOrCreateVlan().GetOrCreateEncap().GetOrCreateSingleTagged().VlanId = ygotsrl.UnionUint16(10)
+	si.GetOrCreateIpv4().GetOrCreateAddress("10.0.0.1/24")
+	si.GetOrCreateIpv6().GetOrCreateAddress("1000::1/64")
+	d.GetOrCreateInterface("system0").GetOrCreateSubinterface(0).GetOrCreateIpv4().GetOrCreateAddress("100.0.0.1/32")
+
+	ni := d.GetOrCreateNetworkInstance("default")
+	ni.Type = ygotsrl.SrlNokiaNetworkInstance_NiType_default
+	ni.GetOrCreateInterface("ethernet-1/1.10")
+	ni.GetOrCreateInterface("system0.0")
+	bgp := ni.GetOrCreateProtocols().GetOrCreateBgp()
+	bgp.AutonomousSystem = pointer.Uint32(65000)
+	bgp.RouterId = pointer.String("100.0.0.1")
+	bgp.GetOrCreateIpv4Unicast()
+	group := bgp.GetOrCreateGroup("underlay")
+	group.PeerAs = pointer.Uint32(65001)
+	group.ExportPolicy = pointer.String("export-underlay")
+	group.GetOrCreateIpv4Unicast()
+
+	rp := d.GetOrCreateRoutingPolicy()
+	rp.GetOrCreatePrefixSet("system").GetOrCreatePrefix("100.0.0.0/24", "32..32")
+	st := rp.GetOrCreatePolicy("export-underlay").GetOrCreateStatement(10)
+	st.GetOrCreateMatch().PrefixSet = pointer.String("system")
+	st.GetOrCreateAction().PolicyResult = ygotsrl.SrlNokiaPolicyTypes_PolicyResultType_accept
+
+	p, err := getProvider(openConfigProvider)
+	if err != nil {
+		t.Fatalf("getProvider() unexpected error: %v", err)
+	}
+	b, err := p.Render(d)
+	if err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+	got := &ocDevice{}
+	if err := json.Unmarshal(b, got); err != nil {
+		t.Fatalf("cannot unmarshal rendered config: %v", err)
+	}
+
+	want := &ocDevice{
+		Interfaces: &ocInterfaces{Interface: []*ocInterface{
+			{
+				Name:   "ethernet-1/1",
+				Config: ocInterfaceConfig{Name: "ethernet-1/1", Type: "iana-if-type:ethernetCsmacd", Enabled: true},
+				Subinterfaces: &ocSubinterfaces{Subinterface: []*ocSubinterface{{
+					Index:  10,
+					Config: ocSubinterfaceConfig{Index: 10, Enabled: true},
+					Vlan:   &ocVlan{Match: ocVlanMatch{SingleTagged: ocVlanSingleTagged{Config: ocVlanConfig{VlanID: 10}}}},
+					Ipv4: &ocIP{Addresses: ocAddresses{Address: []*ocAddress{
+						{IP: "10.0.0.1", Config: ocAddressConfig{IP: "10.0.0.1", PrefixLength: 24}},
+					}}},
+					Ipv6: &ocIP{Addresses: ocAddresses{Address: []*ocAddress{
+						{IP: "1000::1", Config: ocAddressConfig{IP: "1000::1", PrefixLength: 64}},
+					}}},
+				}}},
+			},
+			{
+				Name:   "system0",
+				Config: ocInterfaceConfig{Name: "system0", Type: "iana-if-type:softwareLoopback", Enabled: true},
+				Subinterfaces: &ocSubinterfaces{Subinterface: []*ocSubinterface{{
+					Index:  0,
+					Config: ocSubinterfaceConfig{Index: 0, Enabled: true},
+					Ipv4: &ocIP{Addresses: ocAddresses{Address: []*ocAddress{
+						{IP: "100.0.0.1", Config: ocAddressConfig{IP: "100.0.0.1", PrefixLength: 32}},
+					}}},
+				}}},
+			},
+		}},
+		NetworkInstances: &ocNetworkInstances{NetworkInstance: []*ocNetworkInstance{{
+			Name:   "default",
+			Config: ocNetworkInstanceConfig{Name: "default", Type: "openconfig-network-instance-types:DEFAULT_INSTANCE"},
+			Interfaces: &ocNiInterfaces{Interface: []*ocNiInterface{
+				{ID: "ethernet-1/1.10", Config: ocNiInterfaceConfig{ID: "ethernet-1/1.10", Interface: "ethernet-1/1", Subinterface: 10}},
+				{ID: "system0.0", Config: ocNiInterfaceConfig{ID: "system0.0", Interface: "system0", Subinterface: 0}},
+			}},
+			Protocols: &ocProtocols{Protocol: []*ocProtocol{{
+				Identifier: "openconfig-policy-types:BGP",
+				Name:       "BGP",
+				Config:     ocProtocolConfig{Identifier: "openconfig-policy-types:BGP", Name: "BGP"},
+				Bgp: &ocBgp{
+					Global: ocBgpGlobal{
+						Config: ocBgpGlobalConfig{As: 65000, RouterID: "100.0.0.1"},
+						AfiSafis: &ocAfiSafis{AfiSafi: []*ocAfiSafi{
+							{AfiSafiName: "openconfig-bgp-types:IPV4_UNICAST", Config: ocAfiSafiConfig{AfiSafiName: "openconfig-bgp-types:IPV4_UNICAST", Enabled: true}},
+						}},
+					},
+					PeerGroups: &ocBgpPeerGroups{PeerGroup: []*ocBgpPeerGroup{{
+						PeerGroupName: "underlay",
+						Config:        ocBgpPeerGroupConfig{PeerGroupName: "underlay", PeerAs: 65001},
+						AfiSafis: &ocAfiSafis{AfiSafi: []*ocAfiSafi{
+							{AfiSafiName: "openconfig-bgp-types:IPV4_UNICAST", Config: ocAfiSafiConfig{AfiSafiName: "openconfig-bgp-types:IPV4_UNICAST", Enabled: true}},
+						}},
+						ApplyPolicy: &ocApplyPolicy{Config: ocApplyPolicyConfig{ExportPolicy: []string{"export-underlay"}}},
+					}}},
+				},
+			}}},
+		}}},
+		RoutingPolicy: &ocRoutingPolicy{
+			DefinedSets: &ocDefinedSets{PrefixSets: ocPrefixSets{PrefixSet: []*ocPrefixSet{{
+				Name:   "system",
+				Config: ocPrefixSetConfig{Name: "system", Mode: "IPV4"},
+				Prefixes: ocPrefixes{Prefix: []*ocPrefix{
+					{IPPrefix: "100.0.0.0/24", MasklengthRange: "32..32", Config: ocPrefixConfig{IPPrefix: "100.0.0.0/24", MasklengthRange: "32..32"}},
+				}},
+			}}}},
+			PolicyDefinitions: &ocPolicyDefinitions{PolicyDefinition: []*ocPolicyDefinition{{
+				Name:   "export-underlay",
+				Config: ocPolicyDefinitionConfig{Name: "export-underlay"},
+				Statements: ocStatements{Statement: []*ocStatement{{
+					Name:       "10",
+					Config:     ocStatementConfig{Name: "10"},
+					Conditions: &ocConditions{MatchPrefixSet: ocMatchPrefixSet{Config: ocMatchPrefixSetConfig{PrefixSet: "system"}}},
+					Actions:    ocActions{Config: ocActionsConfig{PolicyResult: "ACCEPT_ROUTE"}},
+				}}},
+			}}},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Render() -want, +got:\n%s", diff)
+	}
+
+	// the rendered config is stable across reconciles
+	again, err := p.Render(d)
+	if err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(string(b), string(again)); diff != "" {
+		t.Errorf("Render() not stable -want, +got:\n%s", diff)
+	}
+}
+
+func TestOpenConfigRenderUnsupported(t *testing.T) {
+	d := &ygotsrl.Device{}
+	d.GetOrCreateNetworkInstance("mgmt").Type = ygotsrl.SrlNokiaNetworkInstance_NiType_UNSET
+	p, err := getProvider(openConfigProvider)
+	if err != nil {
+		t.Fatalf("getProvider() unexpected error: %v", err)
+	}
+	if _, err := p.Render(d); err == nil {
+		t.Errorf("Render() want error for unsupported network instance type, got nil")
+	}
+}
+
+func TestSRLRender(t *testing.T) {
+	d := &ygotsrl.Device{}
+	d.GetOrCreateInterface("ethernet-1/1").GetOrCreateSubinterface(10).GetOrCreateIpv4().GetOrCreateAddress("10.0.0.1/24")
+	d.GetOrCreateNetworkInstance("default").Type = ygotsrl.SrlNokiaNetworkInstance_NiType_default
+	d.GetOrCreateRoutingPolicy().GetOrCreatePrefixSet("system").GetOrCreatePrefix("100.0.0.0/24", "32..32")
+
+	p, err := getProvider(nokiaSRLProvider)
+	if err != nil {
+		t.Fatalf("getProvider() unexpected error: %v", err)
+	}
+	b, err := p.Render(d)
+	if err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+	got := map[string]any{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("cannot unmarshal rendered config: %v", err)
+	}
+	for _, key := range []string{"srl_nokia-interfaces:interface", "srl_nokia-network-instance:network-instance", "srl_nokia-routing-policy:routing-policy"} {
+		if _, ok := got[key]; !ok {
+			t.Errorf("Render() want %s in the rendered config, got: %v", key, got)
+		}
+	}
+}
+
+func TestProviderSelector(t *testing.T) {
+	cases := map[string]struct {
+		labels map[string]string
+		want   bool
+	}{
+		"SRL": {
+			labels: map[string]string{invv1alpha1.NephioProviderKey: nokiaSRLProvider, invv1alpha1.NephioTopologyKey: "nephio"},
+			want:   true,
+		},
+		"OpenConfig": {
+			labels: map[string]string{invv1alpha1.NephioProviderKey: openConfigProvider, invv1alpha1.NephioTopologyKey: "nephio"},
+			want:   true,
+		},
+		"UnknownProvider": {
+			labels: map[string]string{invv1alpha1.NephioProviderKey: "unknown.example.com", invv1alpha1.NephioTopologyKey: "nephio"},
+			want:   false,
+		},
+		"OtherTopology": {
+			labels: map[string]string{invv1alpha1.NephioProviderKey: nokiaSRLProvider, invv1alpha1.NephioTopologyKey: "other"},
+			want:   false,
+		},
+	}
+	selector, err := getProviderSelector("nephio")
+	if err != nil {
+		t.Fatalf("getProviderSelector() unexpected error: %v", err)
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := selector.Matches(labels.Set(tc.labels)); got != tc.want {
+				t.Errorf("Matches() want %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
@@ -41,15 +41,16 @@ import (
 	vlanv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/vlan/v1alpha1"
 	"github.com/nokia/k8s-ipam/pkg/meta"
 	"github.com/nokia/k8s-ipam/pkg/proxy/clientproxy"
-	"github.com/openconfig/ygot/ygot"
 
 	"github.com/pkg/errors"
 	"github.com/srl-labs/ygotsrl/v22"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -60,8 +61,7 @@ func init() {
 }
 
 const (
-	finalizer        = "infra.nephio.org/finalizer"
-	nokiaSRLProvider = "srl.nokia.com"
+	finalizer = "infra.nephio.org/finalizer"
 	// errors
 	errGetCr        = "cannot get cr"
 	errUpdateStatus = "cannot update status"
@@ -77,6 +77,9 @@ const (
 //+kubebuilder:rbac:groups=config.resource.nephio.org,resources=networks/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=inv.nephio.org,resources=endpoints,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=inv.nephio.org,resources=endpoints/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=inv.nephio.org,resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=inv.nephio.org,resources=links,verbs=get;list;watch
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c interface{}) (map[schema.GroupVersionKind]chan event.GenericEvent, error) {
@@ -109,14 +112,25 @@ func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c i
 	r.IpamClientProxy = cfg.IpamClientProxy
 	//r.targets = cfg.Targets
 
+	if cfg.EnableWebhooks {
+		if err := ctrl.NewWebhookManagedBy(mgr).
+			For(&infrav1alpha1.Network{}).
+			WithValidator(&networkValidator{r: r}).
+			Complete(); err != nil {
+			return nil, err
+		}
+	}
+
 	return nil, ctrl.NewControllerManagedBy(mgr).
 		Named("NetworkController").
-		For(&infrav1alpha1.Network{}).
+		For(&infrav1alpha1.Network{}, builder.WithPredicates(networkChanged())).
 		Owns(&ipamv1alpha1.NetworkInstance{}).
 		Owns(&vlanv1alpha1.VLANIndex{}).
 		Owns(&configv1alpha1.Network{}).
+		Owns(&corev1.ConfigMap{}).
 		Watches(&invv1alpha1.Endpoint{}, &endpointEventHandler{client: mgr.GetClient()}).
-		Watches(&invv1alpha1.Endpoint{}, &nodeEventHandler{client: mgr.GetClient()}).
+		Watches(&invv1alpha1.Node{}, &nodeEventHandler{client: mgr.GetClient()}).
+		Watches(&invv1alpha1.Link{}, &linkEventHandler{client: mgr.GetClient()}).
 		Complete(r)
 
 }
@@ -146,10 +160,6 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{}, nil
 	}
 
-	// TODO validation
-	// validate interface/node or selector
-	// validate in rt + bd -> the interface/node or selector is coming from the bd
-
 	if meta.WasDeleted(cr) {
 		if err := r.finalizer.RemoveFinalizer(ctx, cr); err != nil {
 			log.Error(err, "cannot remove finalizer")
@@ -168,18 +178,19 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 	}
 
-	eps, err := r.getProviderEndpoints(ctx, cr.Spec.Topology)
+	inv, err := r.getInventory(ctx, cr.Spec.Topology)
 	if err != nil {
-		log.Error(err, "cannot list provider endpoints")
+		log.Error(err, "cannot get inventory")
 		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
 		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 	}
 
-	nodes, err := r.getProviderNodes(ctx, cr.Spec.Topology)
-	if err != nil {
-		log.Error(err, "cannot list provider nodes")
-		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
-		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	// the network is validated before any resource gets allocated, every
+	// problem is reported at once
+	if errs := append(validateSpec(cr), validateInventory(cr, inv)...); len(errs) > 0 {
+		log.Info("invalid network", "errors", errs.ToAggregate().Error())
+		cr.SetConditions(invalid(errs))
+		return ctrl.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 	}
 
 	r.resources = resources.New(
@@ -193,20 +204,51 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		},
 	)
 
-	log.Info("apply initial resources")
-	if err := r.applyInitialresources(ctx, cr, eps, nodes); err != nil {
-		log.Error(err, "cannot apply initial resources")
+	// the databases are applied before the network is rendered, as the ip
+	// prefixes and vlans are claimed from them while rendering
+	log.Info("apply databases")
+	if err := r.applyDatabases(ctx, cr, inv); err != nil {
+		log.Error(err, "cannot apply databases")
 		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
 		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 	}
 
 	log.Info("get new resources")
-	if err := r.getNewResources(ctx, cr, eps, nodes); err != nil {
+	allocs, err := r.getNewResources(ctx, cr, inv)
+	if err != nil {
 		log.Error(err, "cannot get new resources")
 		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
 		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 	}
 
+	// in plan mode the ip prefixes and vlans are claimed above, before the
+	// plan is approved, as the rendered node configs hold them
+	if isPlanMode(cr) {
+		p, err := r.getPlan()
+		if err != nil {
+			log.Error(err, "cannot get plan")
+			cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+			return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+		}
+		if err := r.applyPlan(ctx, cr, p); err != nil {
+			log.Error(err, "cannot apply plan")
+			cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+			return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+		}
+		// the node configs are only updated once the plan is approved
+		if !p.isApproved(cr) {
+			log.Info("plan awaits approval", "hash", p.Hash)
+			if err := r.updateNetworkStatus(ctx, cr, inv, allocs); err != nil {
+				log.Error(err, "cannot record network status")
+				cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+				return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+			}
+			cr.SetConditions(planPending(cr, p))
+			return ctrl.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+		}
+		log.Info("plan approved", "hash", p.Hash)
+	}
+
 	log.Info("apply all resources")
 	if err := r.resources.APIApply(ctx); err != nil {
 		log.Error(err, "cannot apply resources to the API")
@@ -214,6 +256,12 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 	}
 
+	if err := r.updateNetworkStatus(ctx, cr, inv, allocs); err != nil {
+		log.Error(err, "cannot record network status")
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	}
+
 	cr.SetConditions(infrav1alpha1.Ready())
 	return ctrl.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 }
@@ -224,72 +272,53 @@ func getMatchingNodeLabels(cr client.Object, nodeName string) client.MatchingLab
 	return labels
 }
 
+// getProviderEndpoints returns the endpoints of the topology handled by a
+// registered provider
 func (r *reconciler) getProviderEndpoints(ctx context.Context, topology string) (*endpoints.Endpoints, error) {
-	opts := []client.ListOption{
-		client.MatchingLabels{
-			invv1alpha1.NephioProviderKey: nokiaSRLProvider,
-			invv1alpha1.NephioTopologyKey: topology,
-		},
+	selector, err := getProviderSelector(topology)
+	if err != nil {
+		return nil, err
 	}
 	eps := &invv1alpha1.EndpointList{}
-	if err := r.List(ctx, eps, opts...); err != nil {
+	if err := r.List(ctx, eps, selector); err != nil {
 		log.FromContext(ctx).Error(err, "cannot list endpoints")
 		return nil, err
 	}
 	return &endpoints.Endpoints{EndpointList: eps}, nil
 }
 
+// getProviderNodes returns the nodes of the topology handled by a registered
+// provider
 func (r *reconciler) getProviderNodes(ctx context.Context, topology string) (*nodes.Nodes, error) {
-	opts := []client.ListOption{
-		client.MatchingLabels{
-			invv1alpha1.NephioProviderKey: nokiaSRLProvider,
-			invv1alpha1.NephioTopologyKey: topology,
-		},
+	selector, err := getProviderSelector(topology)
+	if err != nil {
+		return nil, err
 	}
 	nos := &invv1alpha1.NodeList{}
-	if err := r.List(ctx, nos, opts...); err != nil {
+	if err := r.List(ctx, nos, selector); err != nil {
 		log.FromContext(ctx).Error(err, "cannot list nodes")
 		return nil, err
 	}
 	return &nodes.Nodes{NodeList: nos}, nil
 }
 
-func (r *reconciler) applyInitialresources(ctx context.Context, cr *infrav1alpha1.Network, eps *endpoints.Endpoints, nodes *nodes.Nodes) error {
-	n := network.New(&network.Config{
-		Config:    &infra2v1alpha1.NetworkConfig{},
-		Apply:     true,
-		Resources: r.resources,
-		Endpoints: eps,
-		Nodes:     nodes,
-		Ipam:      ipam.NewIPAM(r.IpamClientProxy),
-		Vlan:      vlan.NewVLAN(r.VlanClientProxy),
-	})
-
-	if err := n.Run(ctx, cr); err != nil {
-		log.FromContext(ctx).Error(err, "cannot execute network run")
-		return err
-	}
-	if err := r.resources.APIApply(ctx); err != nil {
-		log.FromContext(ctx).Error(err, "cannot apply resources to the API")
-		return err
-	}
-	return nil
-}
-
-func (r *reconciler) getNewResources(ctx context.Context, cr *infrav1alpha1.Network, eps *endpoints.Endpoints, nodes *nodes.Nodes) error {
+// getNewResources renders the node configs of the Network and returns the ip
+// prefixes and vlans claimed for them
+func (r *reconciler) getNewResources(ctx context.Context, cr *infrav1alpha1.Network, inv *inventory) (*allocations, error) {
+	allocs := newAllocations()
 	n := network.New(&network.Config{
 		Config:    &infra2v1alpha1.NetworkConfig{},
 		Apply:     false,
-		Resources: r.resources,
-		Endpoints: eps,
-		Nodes:     nodes,
-		Ipam:      ipam.NewIPAM(r.IpamClientProxy),
-		Vlan:      vlan.NewVLAN(r.VlanClientProxy),
+		Resources: withoutDatabases{Resources: r.resources},
+		Endpoints: inv.eps,
+		Nodes:     inv.nodes,
+		Ipam:      ipam.NewIPAM(&ipamRecorder{Proxy: r.IpamClientProxy, allocs: allocs}),
+		Vlan:      vlan.NewVLAN(&vlanRecorder{Proxy: r.VlanClientProxy, allocs: allocs}),
 	})
 
 	if err := n.Run(ctx, cr); err != nil {
 		log.FromContext(ctx).Error(err, "cannot execute network run")
-		return err
+		return nil, err
 	}
 
 	// list all networkConfigs
@@ -299,38 +328,44 @@ func (r *reconciler) getNewResources(ctx context.Context, cr *infrav1alpha1.Netw
 	}
 	ncs := &configv1alpha1.NetworkList{}
 	if err := r.List(ctx, ncs, opts...); err != nil {
-		return err
+		return nil, err
 	}
 	networkConfigs := map[string]configv1alpha1.Network{}
 	for _, nc := range ncs.Items {
 		networkConfigs[nc.Name] = nc
 	}
 
+	// the device config of a node is rendered by the provider of the node
+	nodeProviders := map[string]string{}
+	for _, node := range inv.nodes.Items {
+		nodeProviders[node.GetName()] = node.GetLabels()[invv1alpha1.NephioProviderKey]
+	}
+
 	for nodeName, device := range n.GetDevices() {
-		log.FromContext(ctx).Info("node config", "nodeName", nodeName)
+		log.FromContext(ctx).Info("node config", "nodeName", nodeName, "provider", nodeProviders[nodeName])
 
-		j, err := ygot.EmitJSON(device, &ygot.EmitJSONConfig{
-			Format: ygot.RFC7951,
-			Indent: "  ",
-			RFC7951Config: &ygot.RFC7951JSONConfig{
-				AppendModuleName: true,
-			},
-			SkipValidation: false,
-		})
+		p, err := getProvider(nodeProviders[nodeName])
+		if err != nil {
+			log.FromContext(ctx).Error(err, "cannot render device config", "nodeName", nodeName)
+			return nil, errors.Wrapf(err, "cannot render device config of node %s", nodeName)
+		}
+		j, err := p.Render(device)
 		if err != nil {
 			log.FromContext(ctx).Error(err, "cannot construct json device info")
-			return err
+			return nil, err
 		}
 
+		labels := getMatchingNodeLabels(cr, nodeName)
+		labels[invv1alpha1.NephioProviderKey] = p.Name()
 		o := configv1alpha1.BuildNetworkConfig(
 			metav1.ObjectMeta{
 				Name:            fmt.Sprintf("%s-%s", cr.Name, nodeName),
 				Namespace:       cr.Namespace,
-				Labels:          getMatchingNodeLabels(cr, nodeName),
+				Labels:          labels,
 				OwnerReferences: []metav1.OwnerReference{{APIVersion: cr.APIVersion, Kind: cr.Kind, Name: cr.Name, UID: cr.UID, Controller: pointer.Bool(true)}},
 			}, configv1alpha1.NetworkSpec{
 				Config: runtime.RawExtension{
-					Raw: []byte(j),
+					Raw: j,
 				},
 			}, configv1alpha1.NetworkStatus{})
 		if existingNetwNodeConfig, ok := networkConfigs[fmt.Sprintf("%s-%s", cr.Name, nodeName)]; ok {
@@ -339,5 +374,5 @@ func (r *reconciler) getNewResources(ctx context.Context, cr *infrav1alpha1.Netw
 
 		r.resources.AddNewResource(o)
 	}
-	return nil
+	return allocs, nil
 }
@@ -0,0 +1,333 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"sync"
+
+	configv1alpha1 "github.com/henderiw-nephio/network/apis/config/v1alpha1"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	invv1alpha1 "github.com/nokia/k8s-ipam/apis/inv/v1alpha1"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
+	vlanv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/vlan/v1alpha1"
+	"github.com/nokia/k8s-ipam/pkg/proxy/clientproxy"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	// StatusAnnotationName records the networkStatus on the Network. The
+	// Network type is owned by the nephio api module, which does not define
+	// these fields in its status, so the status only holds the conditions.
+	StatusAnnotationName = "network.nephio.org/status"
+
+	nodeStateApplied = "applied"
+	nodeStatePending = "pending"
+)
+
+// networkStatus is what the Network rendered and allocated where
+type networkStatus struct {
+	// Endpoints is the amount of endpoints selected by the Network
+	Endpoints     int                `json:"endpoints"`
+	Nodes         []nodeStatus       `json:"nodes,omitempty"`
+	BridgeDomains []allocationStatus `json:"bridgeDomains,omitempty"`
+	RoutingTables []allocationStatus `json:"routingTables,omitempty"`
+}
+
+// nodeStatus is the state of the config of a node, the config is applied
+// once the config last applied to the node is the rendered config
+type nodeStatus struct {
+	NodeName   string `json:"nodeName"`
+	ConfigName string `json:"configName"`
+	ConfigHash string `json:"configHash"`
+	State      string `json:"state"`
+}
+
+// allocationStatus are the endpoints selected by a bridge domain or routing
+// table and the ip prefixes and vlans allocated for it
+type allocationStatus struct {
+	Name      string       `json:"name"`
+	Endpoints int          `json:"endpoints"`
+	Prefixes  []string     `json:"prefixes,omitempty"`
+	VLANs     []vlanStatus `json:"vlans,omitempty"`
+}
+
+// vlanStatus is a vlan id allocated from a vlan database
+type vlanStatus struct {
+	Database string `json:"database"`
+	ID       uint16 `json:"id"`
+}
+
+// allocations are the ip prefixes and vlans claimed while rendering a Network
+type allocations struct {
+	m sync.Mutex
+	// prefixes per ipam network instance, which is named after the routing table
+	prefixes map[string]map[string]struct{}
+	// vlans per vlan claim, which is named after the network instance
+	vlans map[string]map[vlanStatus]struct{}
+}
+
+func newAllocations() *allocations {
+	return &allocations{
+		prefixes: map[string]map[string]struct{}{},
+		vlans:    map[string]map[vlanStatus]struct{}{},
+	}
+}
+
+func (r *allocations) addPrefix(networkInstance, prefix string) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if _, ok := r.prefixes[networkInstance]; !ok {
+		r.prefixes[networkInstance] = map[string]struct{}{}
+	}
+	r.prefixes[networkInstance][prefix] = struct{}{}
+}
+
+func (r *allocations) addVLAN(claimName string, vlan vlanStatus) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if _, ok := r.vlans[claimName]; !ok {
+		r.vlans[claimName] = map[vlanStatus]struct{}{}
+	}
+	r.vlans[claimName][vlan] = struct{}{}
+}
+
+func (r *allocations) getPrefixes(networkInstance string) []string {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if len(r.prefixes[networkInstance]) == 0 {
+		return nil
+	}
+	return sortedKeys(r.prefixes[networkInstance])
+}
+
+func (r *allocations) getVLANs(claimNames ...string) []vlanStatus {
+	r.m.Lock()
+	defer r.m.Unlock()
+	vlans := []vlanStatus{}
+	for _, claimName := range claimNames {
+		for vlan := range r.vlans[claimName] {
+			vlans = append(vlans, vlan)
+		}
+	}
+	if len(vlans) == 0 {
+		return nil
+	}
+	sort.Slice(vlans, func(i, j int) bool {
+		if vlans[i].Database != vlans[j].Database {
+			return vlans[i].Database < vlans[j].Database
+		}
+		return vlans[i].ID < vlans[j].ID
+	})
+	return vlans
+}
+
+// ipamRecorder records the prefixes claimed through the ipam client proxy,
+// addresses are not recorded
+type ipamRecorder struct {
+	clientproxy.Proxy[*ipamv1alpha1.NetworkInstance, *ipamv1alpha1.IPClaim]
+	allocs *allocations
+}
+
+func (r *ipamRecorder) Claim(ctx context.Context, cr client.Object, d any) (*ipamv1alpha1.IPClaim, error) {
+	claim, err := r.Proxy.Claim(ctx, cr, d)
+	if err != nil {
+		return claim, err
+	}
+	if req, ok := cr.(*ipamv1alpha1.IPClaim); ok && req.Spec.CreatePrefix != nil && *req.Spec.CreatePrefix && claim.Status.Prefix != nil {
+		r.allocs.addPrefix(req.Spec.NetworkInstance.Name, *claim.Status.Prefix)
+	}
+	return claim, nil
+}
+
+// vlanRecorder records the vlan ids claimed through the vlan client proxy
+type vlanRecorder struct {
+	clientproxy.Proxy[*vlanv1alpha1.VLANIndex, *vlanv1alpha1.VLANClaim]
+	allocs *allocations
+}
+
+func (r *vlanRecorder) Claim(ctx context.Context, cr client.Object, d any) (*vlanv1alpha1.VLANClaim, error) {
+	claim, err := r.Proxy.Claim(ctx, cr, d)
+	if err != nil {
+		return claim, err
+	}
+	if req, ok := cr.(*vlanv1alpha1.VLANClaim); ok && claim.Status.VLANID != nil {
+		r.allocs.addVLAN(req.GetName(), vlanStatus{Database: req.Spec.VLANIndex.Name, ID: *claim.Status.VLANID})
+	}
+	return claim, nil
+}
+
+// getNetworkStatus returns the status of the node configs rendered for the
+// Network and the endpoints selected and resources allocated per bridge
+// domain and routing table
+func (r *reconciler) getNetworkStatus(cr *infrav1alpha1.Network, inv *inventory, allocs *allocations) (*networkStatus, error) {
+	status := &networkStatus{}
+
+	configs := map[string]*configv1alpha1.Network{}
+	for _, o := range r.resources.GetNewResources() {
+		if nc, ok := o.(*configv1alpha1.Network); ok {
+			configs[nc.GetName()] = nc
+		}
+	}
+	for _, name := range sortedKeys(configs) {
+		nc := configs[name]
+		state := nodeStatePending
+		if bytes.Equal(nc.Spec.Config.Raw, nc.Status.LastAppliedConfig.Raw) {
+			state = nodeStateApplied
+		}
+		status.Nodes = append(status.Nodes, nodeStatus{
+			NodeName:   nc.GetLabels()[invv1alpha1.NephioNodeNameKey],
+			ConfigName: name,
+			ConfigHash: getConfigHash(nc.Spec.Config.Raw),
+			State:      state,
+		})
+	}
+
+	selected := map[string]struct{}{}
+	for _, bd := range cr.Spec.BridgeDomains {
+		bdEps := map[string]struct{}{}
+		claimNames := []string{}
+		for _, itfce := range bd.Interfaces {
+			selectorEps, err := inv.getSelectedEndpoints(itfce)
+			if err != nil {
+				return nil, err
+			}
+			for selectorName, eps := range selectorEps {
+				claimNames = append(claimNames, itfce.GetBridgeDomainName(bd.Name, selectorName))
+				for _, ep := range eps {
+					bdEps[ep.GetName()] = struct{}{}
+					selected[ep.GetName()] = struct{}{}
+				}
+			}
+		}
+		status.BridgeDomains = append(status.BridgeDomains, allocationStatus{
+			Name:      bd.Name,
+			Endpoints: len(bdEps),
+			VLANs:     allocs.getVLANs(claimNames...),
+		})
+	}
+	for _, rt := range cr.Spec.RoutingTables {
+		rtEps := map[string]struct{}{}
+		for _, itfce := range rt.Interfaces {
+			// the endpoints of bridgedomain interfaces belong to the bridge domain
+			if itfce.Kind == infrav1alpha1.InterfaceKindBridgeDomain {
+				continue
+			}
+			selectorEps, err := inv.getSelectedEndpoints(itfce)
+			if err != nil {
+				return nil, err
+			}
+			for _, eps := range selectorEps {
+				for _, ep := range eps {
+					rtEps[ep.GetName()] = struct{}{}
+					selected[ep.GetName()] = struct{}{}
+				}
+			}
+		}
+		status.RoutingTables = append(status.RoutingTables, allocationStatus{
+			Name:      rt.Name,
+			Endpoints: len(rtEps),
+			Prefixes:  allocs.getPrefixes(rt.Name),
+			VLANs:     allocs.getVLANs(rt.Name),
+		})
+	}
+	status.Endpoints = len(selected)
+	return status, nil
+}
+
+// getConfigHash returns a short hash identifying the config
+func getConfigHash(config []byte) string {
+	h := sha256.Sum256(config)
+	return hex.EncodeToString(h[:])[:16]
+}
+
+// recordStatus persists the networkStatus on the Network when it changed.
+// Only the annotations are patched, so the conditions of the Network are kept
+// to be updated at the end of the reconcile.
+func (r *reconciler) recordStatus(ctx context.Context, cr *infrav1alpha1.Network, status *networkStatus) error {
+	b, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	if cr.GetAnnotations()[StatusAnnotationName] == string(b) {
+		return nil
+	}
+	patched := cr.DeepCopy()
+	resource.AddAnnotations(patched, map[string]string{StatusAnnotationName: string(b)})
+	if err := r.Patch(ctx, patched, client.MergeFrom(cr)); err != nil {
+		return errors.Wrap(err, "cannot record network status")
+	}
+	cr.SetAnnotations(patched.GetAnnotations())
+	cr.SetResourceVersion(patched.GetResourceVersion())
+	return nil
+}
+
+// updateNetworkStatus records the status of the Network on the Network
+func (r *reconciler) updateNetworkStatus(ctx context.Context, cr *infrav1alpha1.Network, inv *inventory, allocs *allocations) error {
+	status, err := r.getNetworkStatus(cr, inv, allocs)
+	if err != nil {
+		return err
+	}
+	return r.recordStatus(ctx, cr, status)
+}
+
+// networkChanged filters the updates of a Network to those changing its spec
+// or an annotation other than the status annotation, so recording the status
+// and updating the conditions do not trigger another reconcile
+func networkChanged() predicate.Predicate {
+	return predicate.Or(
+		predicate.GenerationChangedPredicate{},
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				if e.ObjectOld == nil || e.ObjectNew == nil {
+					return false
+				}
+				return !equalWithoutStatus(e.ObjectOld.GetAnnotations(), e.ObjectNew.GetAnnotations())
+			},
+		},
+	)
+}
+
+// equalWithoutStatus returns true when the annotations only differ in the
+// status annotation
+func equalWithoutStatus(oldAnnotations, newAnnotations map[string]string) bool {
+	count := 0
+	for k, v := range newAnnotations {
+		if k == StatusAnnotationName {
+			continue
+		}
+		if old, ok := oldAnnotations[k]; !ok || old != v {
+			return false
+		}
+		count++
+	}
+	for k := range oldAnnotations {
+		if k != StatusAnnotationName {
+			count--
+		}
+	}
+	return count == 0
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	configv1alpha1 "github.com/henderiw-nephio/network/apis/config/v1alpha1"
+	"github.com/henderiw-nephio/network/pkg/resources"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	invv1alpha1 "github.com/nokia/k8s-ipam/apis/inv/v1alpha1"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
+	vlanv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/vlan/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// testProxy is a client proxy returning the claim with the status of the
+// claim function
+type testProxy[T1, T2 client.Object] struct {
+	claim func(cr client.Object) T2
+}
+
+func (r *testProxy[T1, T2]) AddEventChs(map[schema.GroupVersionKind]chan event.GenericEvent) {}
+func (r *testProxy[T1, T2]) CreateIndex(ctx context.Context, cr T1) error                    { return nil }
+func (r *testProxy[T1, T2]) DeleteIndex(ctx context.Context, cr T1) error                    { return nil }
+func (r *testProxy[T1, T2]) GetClaim(ctx context.Context, cr client.Object, d any) (T2, error) {
+	return r.claim(cr), nil
+}
+func (r *testProxy[T1, T2]) Claim(ctx context.Context, cr client.Object, d any) (T2, error) {
+	return r.claim(cr), nil
+}
+func (r *testProxy[T1, T2]) DeleteClaim(ctx context.Context, cr client.Object, d any) error {
+	return nil
+}
+
+func TestRecorders(t *testing.T) {
+	allocs := newAllocations()
+	ipamProxy := &ipamRecorder{
+		Proxy: &testProxy[*ipamv1alpha1.NetworkInstance, *ipamv1alpha1.IPClaim]{claim: func(cr client.Object) *ipamv1alpha1.IPClaim {
+			claim := cr.(*ipamv1alpha1.IPClaim).DeepCopy()
+			if claim.Spec.CreatePrefix != nil {
+				claim.Status.Prefix = pointer.String("10.0.0.0/24")
+			} else {
+				claim.Status.Prefix = pointer.String("10.0.0.1/24")
+			}
+			return claim
+		}},
+		allocs: allocs,
+	}
+	vlanProxy := &vlanRecorder{
+		Proxy: &testProxy[*vlanv1alpha1.VLANIndex, *vlanv1alpha1.VLANClaim]{claim: func(cr client.Object) *vlanv1alpha1.VLANClaim {
+			claim := cr.(*vlanv1alpha1.VLANClaim).DeepCopy()
+			vlanID := uint16(10)
+			claim.Status.VLANID = &vlanID
+			return claim
+		}},
+		allocs: allocs,
+	}
+
+	ctx := context.Background()
+	for _, claim := range []*ipamv1alpha1.IPClaim{
+		{Spec: ipamv1alpha1.IPClaimSpec{NetworkInstance: corev1.ObjectReference{Name: "ran-rt"}, CreatePrefix: pointer.Bool(true)}},
+		{Spec: ipamv1alpha1.IPClaimSpec{NetworkInstance: corev1.ObjectReference{Name: "ran-rt"}}},
+	} {
+		if _, err := ipamProxy.Claim(ctx, claim, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		claim := &vlanv1alpha1.VLANClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "ran-bd-edge01-bd"},
+			Spec:       vlanv1alpha1.VLANClaimSpec{VLANIndex: corev1.ObjectReference{Name: "edge01"}},
+		}
+		if _, err := vlanProxy.Claim(ctx, claim, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if diff := cmp.Diff([]string{"10.0.0.0/24"}, allocs.getPrefixes("ran-rt")); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff([]vlanStatus{{Database: "edge01", ID: 10}}, allocs.getVLANs("ran-bd-edge01-bd")); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+}
+
+func TestGetNetworkStatus(t *testing.T) {
+	r := &reconciler{resources: resources.New(resource.APIPatchingApplicator{}, resources.Config{})}
+	r.resources.AddNewResource(&configv1alpha1.Network{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vpc-ran-leaf1", Labels: map[string]string{invv1alpha1.NephioNodeNameKey: "leaf1"}},
+		Spec:       configv1alpha1.NetworkSpec{Config: runtime.RawExtension{Raw: []byte(`{"system":{"name":"leaf1"}}`)}},
+		Status:     configv1alpha1.NetworkStatus{LastAppliedConfig: runtime.RawExtension{Raw: []byte(`{"system":{"name":"leaf1"}}`)}},
+	})
+	r.resources.AddNewResource(&configv1alpha1.Network{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vpc-ran-leaf2", Labels: map[string]string{invv1alpha1.NephioNodeNameKey: "leaf2"}},
+		Spec:       configv1alpha1.NetworkSpec{Config: runtime.RawExtension{Raw: []byte(`{"system":{"name":"leaf2"}}`)}},
+	})
+	allocs := newAllocations()
+	allocs.addPrefix("ran-rt", "10.0.1.0/24")
+	allocs.addPrefix("ran-rt", "10.0.0.0/24")
+	allocs.addVLAN("ran-bd-edge01-bd", vlanStatus{Database: "edge01", ID: 10})
+
+	cr, inv := getTestNetwork()
+	got, err := r.getNetworkStatus(cr, inv, allocs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := &networkStatus{
+		Endpoints: 3,
+		Nodes: []nodeStatus{
+			{NodeName: "leaf1", ConfigName: "vpc-ran-leaf1", ConfigHash: getConfigHash([]byte(`{"system":{"name":"leaf1"}}`)), State: nodeStateApplied},
+			{NodeName: "leaf2", ConfigName: "vpc-ran-leaf2", ConfigHash: getConfigHash([]byte(`{"system":{"name":"leaf2"}}`)), State: nodeStatePending},
+		},
+		BridgeDomains: []allocationStatus{
+			{Name: "ran-bd", Endpoints: 2, VLANs: []vlanStatus{{Database: "edge01", ID: 10}}},
+		},
+		RoutingTables: []allocationStatus{
+			{Name: "ran-rt", Endpoints: 1, Prefixes: []string{"10.0.0.0/24", "10.0.1.0/24"}},
+			{Name: "default", Endpoints: 0},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+}
+
+func TestRecordStatus(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := infrav1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("cannot add infra to scheme: %v", err)
+	}
+	cr, _ := getTestNetwork()
+	key := client.ObjectKeyFromObject(cr)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cr).WithStatusSubresource(cr).Build()
+	r := &reconciler{APIPatchingApplicator: resource.NewAPIPatchingApplicator(c)}
+
+	ctx := context.Background()
+	cr = &infrav1alpha1.Network{}
+	if err := c.Get(ctx, key, cr); err != nil {
+		t.Fatalf("cannot get network: %v", err)
+	}
+	cr.SetConditions(infrav1alpha1.Ready())
+	status := &networkStatus{Endpoints: 1, Nodes: []nodeStatus{{NodeName: "leaf1", ConfigName: "vpc-ran-leaf1", ConfigHash: "1234", State: nodeStatePending}}}
+	if err := r.recordStatus(ctx, cr, status); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the conditions are kept to be updated at the end of the reconcile
+	if len(cr.Status.Conditions) != 1 {
+		t.Errorf("want the conditions kept, got %v", cr.Status.Conditions)
+	}
+	if err := r.Status().Update(ctx, cr); err != nil {
+		t.Fatalf("cannot update status after recording the network status: %v", err)
+	}
+
+	got := &infrav1alpha1.Network{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(cr), got); err != nil {
+		t.Fatalf("cannot get network: %v", err)
+	}
+	gotStatus := &networkStatus{}
+	if err := json.Unmarshal([]byte(got.GetAnnotations()[StatusAnnotationName]), gotStatus); err != nil {
+		t.Fatalf("cannot unmarshal network status: %v", err)
+	}
+	if diff := cmp.Diff(status, gotStatus); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+
+	// an unchanged status is not patched again
+	rv := got.GetResourceVersion()
+	if err := r.recordStatus(ctx, got, status); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.GetResourceVersion() != rv {
+		t.Errorf("want an unchanged status not recorded again")
+	}
+}
+
+func TestNetworkChanged(t *testing.T) {
+	oldCR := &infrav1alpha1.Network{ObjectMeta: metav1.ObjectMeta{
+		Name:        "vpc-ran",
+		Generation:  1,
+		Annotations: map[string]string{PlanAnnotationName: "true"},
+	}}
+
+	cases := map[string]struct {
+		mutate func(cr *infrav1alpha1.Network)
+		want   bool
+	}{
+		"Status": {
+			mutate: func(cr *infrav1alpha1.Network) {
+				cr.SetAnnotations(map[string]string{PlanAnnotationName: "true", StatusAnnotationName: "{}"})
+				cr.SetConditions(infrav1alpha1.Ready())
+			},
+			want: false,
+		},
+		"Spec": {
+			mutate: func(cr *infrav1alpha1.Network) {
+				cr.SetGeneration(2)
+			},
+			want: true,
+		},
+		"Annotation": {
+			mutate: func(cr *infrav1alpha1.Network) {
+				cr.SetAnnotations(map[string]string{PlanAnnotationName: "true", ApprovedPlanAnnotationName: "1234"})
+			},
+			want: true,
+		},
+		"RemovedAnnotation": {
+			mutate: func(cr *infrav1alpha1.Network) {
+				cr.SetAnnotations(map[string]string{StatusAnnotationName: "{}"})
+			},
+			want: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := oldCR.DeepCopy()
+			tc.mutate(cr)
+			got := networkChanged().Update(event.UpdateEvent{ObjectOld: oldCR, ObjectNew: cr})
+			if got != tc.want {
+				t.Errorf("networkChanged() got %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
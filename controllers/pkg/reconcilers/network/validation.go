@@ -0,0 +1,242 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+
+	"github.com/henderiw-nephio/network/pkg/endpoints"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	reqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	// ConditionReasonInvalid is the reason of the Ready condition of a
+	// Network that failed the validation, no resources are allocated for it
+	ConditionReasonInvalid infrav1alpha1.ConditionReason = "Invalid"
+
+	// maxVLANs is the amount of usable vlan ids of a vlan database, 1-4094
+	maxVLANs = 4094
+)
+
+// validateSpec returns the problems of the Network that do not depend on the
+// inventory: missing or duplicate names, interfaces that are not selected by
+// either a selector or a node and interface name, references to unknown
+// bridge domains and invalid or overlapping prefixes of a routing table
+func validateSpec(cr *infrav1alpha1.Network) field.ErrorList {
+	allErrs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+
+	if cr.Spec.Topology == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("topology"), ""))
+	}
+
+	bdNames := map[string]struct{}{}
+	for i, bd := range cr.Spec.BridgeDomains {
+		bdPath := specPath.Child("bridgeDomains").Index(i)
+		allErrs = append(allErrs, validateName(bdPath.Child("name"), bd.Name, bdNames)...)
+		for j, itfce := range bd.Interfaces {
+			itfcePath := bdPath.Child("interfaces").Index(j)
+			if itfce.Kind == infrav1alpha1.InterfaceKindBridgeDomain {
+				allErrs = append(allErrs, field.NotSupported(itfcePath.Child("kind"), itfce.Kind, []string{string(infrav1alpha1.InterfaceKindInterface)}))
+				continue
+			}
+			allErrs = append(allErrs, validateInterface(itfcePath, itfce)...)
+		}
+	}
+
+	rtNames := map[string]struct{}{}
+	for i, rt := range cr.Spec.RoutingTables {
+		rtPath := specPath.Child("routingTables").Index(i)
+		allErrs = append(allErrs, validateName(rtPath.Child("name"), rt.Name, rtNames)...)
+		for j, itfce := range rt.Interfaces {
+			itfcePath := rtPath.Child("interfaces").Index(j)
+			if itfce.Kind == infrav1alpha1.InterfaceKindBridgeDomain {
+				if itfce.BridgeDomainName == nil {
+					allErrs = append(allErrs, field.Required(itfcePath.Child("bridgeDomainName"), "bridgedomain interfaces reference a bridge domain"))
+					continue
+				}
+				if _, ok := bdNames[*itfce.BridgeDomainName]; !ok {
+					allErrs = append(allErrs, field.NotFound(itfcePath.Child("bridgeDomainName"), *itfce.BridgeDomainName))
+				}
+				continue
+			}
+			allErrs = append(allErrs, validateInterface(itfcePath, itfce)...)
+		}
+		allErrs = append(allErrs, validatePrefixes(rtPath.Child("prefixes"), rt)...)
+	}
+	return allErrs
+}
+
+// invalid returns the ready condition of a Network that failed the validation
+func invalid(errs field.ErrorList) infrav1alpha1.Condition {
+	c := infrav1alpha1.Failed(errs.ToAggregate().Error())
+	c.Reason = string(ConditionReasonInvalid)
+	return c
+}
+
+func validateName(fldPath *field.Path, name string, names map[string]struct{}) field.ErrorList {
+	if name == "" {
+		return field.ErrorList{field.Required(fldPath, "")}
+	}
+	if _, ok := names[name]; ok {
+		return field.ErrorList{field.Duplicate(fldPath, name)}
+	}
+	names[name] = struct{}{}
+	return nil
+}
+
+func validateInterface(fldPath *field.Path, itfce infrav1alpha1.Interface) field.ErrorList {
+	allErrs := field.ErrorList{}
+	switch itfce.Kind {
+	case infrav1alpha1.InterfaceKindInterface, "":
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("kind"), itfce.Kind, []string{string(infrav1alpha1.InterfaceKindInterface), string(infrav1alpha1.InterfaceKindBridgeDomain)}))
+	}
+	switch itfce.AttachmentType {
+	case reqv1alpha1.AttachmentTypeNone, reqv1alpha1.AttachmentTypeVLAN, "":
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("attachmentType"), itfce.AttachmentType, []string{string(reqv1alpha1.AttachmentTypeNone), string(reqv1alpha1.AttachmentTypeVLAN)}))
+	}
+
+	if itfce.Selector != nil {
+		if itfce.InterfaceName != nil || itfce.NodeName != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath, "selector and interfaceName/nodeName are mutually exclusive"))
+		}
+		if _, err := metav1.LabelSelectorAsSelector(itfce.Selector); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("selector"), itfce.Selector, err.Error()))
+		}
+		return allErrs
+	}
+	if itfce.InterfaceName == nil {
+		allErrs = append(allErrs, field.Required(fldPath.Child("interfaceName"), "interfaces are selected by a selector or by an interfaceName and nodeName"))
+	}
+	if itfce.NodeName == nil {
+		allErrs = append(allErrs, field.Required(fldPath.Child("nodeName"), "interfaces are selected by a selector or by an interfaceName and nodeName"))
+	}
+	return allErrs
+}
+
+// validatePrefixes checks the prefixes of the routing table are valid and do
+// not overlap, prefixes of different routing tables can overlap
+func validatePrefixes(fldPath *field.Path, rt infrav1alpha1.RoutingTable) field.ErrorList {
+	allErrs := field.ErrorList{}
+	pfxs := make([]netip.Prefix, len(rt.Prefixes))
+	for i, prefix := range rt.Prefixes {
+		pfx, err := netip.ParsePrefix(prefix.Prefix)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("prefix"), prefix.Prefix, err.Error()))
+			continue
+		}
+		pfxs[i] = pfx.Masked()
+		for j := 0; j < i; j++ {
+			if pfxs[j].IsValid() && pfxs[j].Overlaps(pfxs[i]) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("prefix"), prefix.Prefix, fmt.Sprintf("overlaps with %s", rt.Prefixes[j].Prefix)))
+			}
+		}
+	}
+	return allErrs
+}
+
+// validateInventory returns the problems of the Network against the endpoints
+// and nodes of its topology: a topology without nodes, interfaces that select
+// no endpoint and vlan databases running out of vlan ids
+func validateInventory(cr *infrav1alpha1.Network, inv *inventory) field.ErrorList {
+	allErrs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+
+	if len(inv.nodes.Items) == 0 {
+		allErrs = append(allErrs, field.NotFound(specPath.Child("topology"), cr.Spec.Topology))
+	}
+
+	// vlan claims per vlan database, the network library claims a vlan id per
+	// network instance from the database of the selected endpoints
+	vlanClaims := map[string]map[string]struct{}{}
+	addVLANClaims := func(itfce infrav1alpha1.Interface, selectorNames []string, niName func(selectorName string) string) {
+		if itfce.AttachmentType != reqv1alpha1.AttachmentTypeVLAN {
+			return
+		}
+		for _, selectorName := range selectorNames {
+			if _, ok := vlanClaims[selectorName]; !ok {
+				vlanClaims[selectorName] = map[string]struct{}{}
+			}
+			vlanClaims[selectorName][niName(selectorName)] = struct{}{}
+		}
+	}
+
+	for i, bd := range cr.Spec.BridgeDomains {
+		for j, itfce := range bd.Interfaces {
+			selected, errs := validateSelectedEndpoints(specPath.Child("bridgeDomains").Index(i).Child("interfaces").Index(j), itfce, inv)
+			allErrs = append(allErrs, errs...)
+			addVLANClaims(itfce, selected, func(selectorName string) string {
+				return itfce.GetBridgeDomainName(bd.Name, selectorName)
+			})
+		}
+	}
+	for i, rt := range cr.Spec.RoutingTables {
+		for j, itfce := range rt.Interfaces {
+			if itfce.Kind == infrav1alpha1.InterfaceKindBridgeDomain {
+				continue
+			}
+			selected, errs := validateSelectedEndpoints(specPath.Child("routingTables").Index(i).Child("interfaces").Index(j), itfce, inv)
+			allErrs = append(allErrs, errs...)
+			addVLANClaims(itfce, selected, func(string) string { return rt.Name })
+		}
+	}
+
+	selectorNames := make([]string, 0, len(vlanClaims))
+	for selectorName := range vlanClaims {
+		selectorNames = append(selectorNames, selectorName)
+	}
+	sort.Strings(selectorNames)
+	for _, selectorName := range selectorNames {
+		if claims := len(vlanClaims[selectorName]); claims > maxVLANs {
+			allErrs = append(allErrs, field.Forbidden(specPath, fmt.Sprintf("vlan database %s needs %d vlan ids, %d are available", selectorName, claims, maxVLANs)))
+		}
+	}
+	return allErrs
+}
+
+// validateSelectedEndpoints returns the selector names of the endpoints
+// selected by the interface, an interface that selects no endpoint is reported
+func validateSelectedEndpoints(fldPath *field.Path, itfce infrav1alpha1.Interface, inv *inventory) ([]string, field.ErrorList) {
+	// interfaces failing the spec validation cannot be resolved
+	if len(validateInterface(fldPath, itfce)) > 0 {
+		return nil, nil
+	}
+	selector := endpoints.GetSelector(itfce)
+	selected, err := inv.getSelectedEndpoints(itfce)
+	if err != nil {
+		return nil, field.ErrorList{field.Invalid(fldPath.Child("selector"), selector, err.Error())}
+	}
+	if len(selected) == 0 {
+		if itfce.Selector != nil {
+			return nil, field.ErrorList{field.Invalid(fldPath.Child("selector"), metav1.FormatLabelSelector(selector), "selects no endpoint of the topology")}
+		}
+		return nil, field.ErrorList{field.NotFound(fldPath.Child("interfaceName"), fmt.Sprintf("%s/%s", *itfce.NodeName, *itfce.InterfaceName))}
+	}
+	names := make([]string, 0, len(selected))
+	for selectorName := range selected {
+		names = append(names, selectorName)
+	}
+	sort.Strings(names)
+	return names, nil
+}
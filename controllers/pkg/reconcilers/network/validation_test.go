@@ -0,0 +1,252 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/henderiw-nephio/network/pkg/endpoints"
+	"github.com/henderiw-nephio/network/pkg/nodes"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	reqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	invv1alpha1 "github.com/nokia/k8s-ipam/apis/inv/v1alpha1"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+// getTestNetwork returns a network and the inventory of its topology
+func getTestNetwork() (*infrav1alpha1.Network, *inventory) {
+	eps := &invv1alpha1.EndpointList{}
+	for _, ep := range []struct{ node, itfce, cluster string }{
+		{node: "leaf1", itfce: "e1-1", cluster: "edge01"},
+		{node: "leaf1", itfce: "e1-2", cluster: "edge02"},
+		{node: "leaf2", itfce: "e1-1", cluster: "edge01"},
+	} {
+		eps.Items = append(eps.Items, invv1alpha1.Endpoint{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: ep.node + "-" + ep.itfce,
+				Labels: map[string]string{
+					invv1alpha1.NephioNodeNameKey:      ep.node,
+					invv1alpha1.NephioInterfaceNameKey: ep.itfce,
+					invv1alpha1.NephioClusterNameKey:   ep.cluster,
+				},
+			},
+			Spec: invv1alpha1.EndpointSpec{EndpointProperties: invv1alpha1.EndpointProperties{
+				NodeName:      ep.node,
+				InterfaceName: ep.itfce,
+			}},
+		})
+	}
+	nos := &invv1alpha1.NodeList{Items: []invv1alpha1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "leaf1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "leaf2"}},
+	}}
+	inv := newInventory(&endpoints.Endpoints{EndpointList: eps}, &nodes.Nodes{NodeList: nos})
+
+	return &infrav1alpha1.Network{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vpc-ran"},
+		Spec: infrav1alpha1.NetworkSpec{
+			Topology: "nephio",
+			BridgeDomains: []infrav1alpha1.BridgeDomain{
+				{
+					Name: "ran-bd",
+					Interfaces: []infrav1alpha1.Interface{{
+						Kind:           infrav1alpha1.InterfaceKindInterface,
+						Selector:       &metav1.LabelSelector{MatchLabels: map[string]string{invv1alpha1.NephioClusterNameKey: "edge01"}},
+						AttachmentType: reqv1alpha1.AttachmentTypeVLAN,
+					}},
+				},
+			},
+			RoutingTables: []infrav1alpha1.RoutingTable{
+				{
+					Name: "ran-rt",
+					Interfaces: []infrav1alpha1.Interface{
+						{Kind: infrav1alpha1.InterfaceKindBridgeDomain, BridgeDomainName: pointer.String("ran-bd")},
+						{Kind: infrav1alpha1.InterfaceKindInterface, NodeName: pointer.String("leaf1"), InterfaceName: pointer.String("e1-2")},
+					},
+					Prefixes: []ipamv1alpha1.Prefix{{Prefix: "10.0.0.0/16"}, {Prefix: "1000::/32"}},
+				},
+				{
+					Name:     "default",
+					Prefixes: []ipamv1alpha1.Prefix{{Prefix: "10.0.0.0/16"}},
+				},
+			},
+		},
+	}, inv
+}
+
+func TestValidate(t *testing.T) {
+	cases := map[string]struct {
+		mutate        func(cr *infrav1alpha1.Network)
+		noNodes       bool
+		wantSpec      []string
+		wantInventory []string
+	}{
+		"Valid": {
+			mutate: func(cr *infrav1alpha1.Network) {},
+		},
+		"DuplicateNames": {
+			mutate: func(cr *infrav1alpha1.Network) {
+				cr.Spec.BridgeDomains = append(cr.Spec.BridgeDomains, infrav1alpha1.BridgeDomain{Name: "ran-bd"})
+				cr.Spec.RoutingTables[1].Name = "ran-rt"
+			},
+			wantSpec: []string{
+				`spec.bridgeDomains[1].name: Duplicate value: "ran-bd"`,
+				`spec.routingTables[1].name: Duplicate value: "ran-rt"`,
+			},
+		},
+		"OverlappingPrefixes": {
+			mutate: func(cr *infrav1alpha1.Network) {
+				cr.Spec.RoutingTables[0].Prefixes = append(cr.Spec.RoutingTables[0].Prefixes,
+					ipamv1alpha1.Prefix{Prefix: "10.0.1.0/24"},
+					ipamv1alpha1.Prefix{Prefix: "10.0.0"},
+				)
+			},
+			wantSpec: []string{
+				`spec.routingTables[0].prefixes[2].prefix: Invalid value: "10.0.1.0/24": overlaps with 10.0.0.0/16`,
+				`spec.routingTables[0].prefixes[3].prefix: Invalid value: "10.0.0": netip.ParsePrefix("10.0.0"): no '/'`,
+			},
+		},
+		"InvalidInterfaces": {
+			mutate: func(cr *infrav1alpha1.Network) {
+				cr.Spec.BridgeDomains[0].Interfaces = append(cr.Spec.BridgeDomains[0].Interfaces,
+					infrav1alpha1.Interface{Kind: infrav1alpha1.InterfaceKindInterface, NodeName: pointer.String("leaf1")},
+					infrav1alpha1.Interface{Kind: infrav1alpha1.InterfaceKindBridgeDomain, BridgeDomainName: pointer.String("ran-bd")},
+				)
+				cr.Spec.RoutingTables[0].Interfaces[0].BridgeDomainName = pointer.String("core-bd")
+			},
+			wantSpec: []string{
+				`spec.bridgeDomains[0].interfaces[1].interfaceName: Required value: interfaces are selected by a selector or by an interfaceName and nodeName`,
+				`spec.bridgeDomains[0].interfaces[2].kind: Unsupported value: "bridgedomain": supported values: "interface"`,
+				`spec.routingTables[0].interfaces[0].bridgeDomainName: Not found: "core-bd"`,
+			},
+		},
+		"UnknownTopology": {
+			mutate: func(cr *infrav1alpha1.Network) {
+				cr.Spec.Topology = "other"
+			},
+			noNodes: true,
+			wantInventory: []string{
+				`spec.topology: Not found: "other"`,
+			},
+		},
+		"NoMatchingEndpoints": {
+			mutate: func(cr *infrav1alpha1.Network) {
+				cr.Spec.BridgeDomains[0].Interfaces[0].Selector.MatchLabels[invv1alpha1.NephioClusterNameKey] = "edge03"
+				cr.Spec.RoutingTables[0].Interfaces[1].InterfaceName = pointer.String("e1-3")
+			},
+			wantInventory: []string{
+				`spec.bridgeDomains[0].interfaces[0].selector: Invalid value: "nephio.org/cluster-name=edge03": selects no endpoint of the topology`,
+				`spec.routingTables[0].interfaces[1].interfaceName: Not found: "leaf1/e1-3"`,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr, inv := getTestNetwork()
+			tc.mutate(cr)
+			if tc.noNodes {
+				inv.nodes.Items = nil
+			}
+
+			gotSpec := []string{}
+			for _, err := range validateSpec(cr) {
+				gotSpec = append(gotSpec, err.Error())
+			}
+			if diff := cmp.Diff(append([]string{}, tc.wantSpec...), gotSpec); diff != "" {
+				t.Errorf("validateSpec() -want, +got:\n%s", diff)
+			}
+			gotInventory := []string{}
+			for _, err := range validateInventory(cr, inv) {
+				gotInventory = append(gotInventory, err.Error())
+			}
+			if diff := cmp.Diff(append([]string{}, tc.wantInventory...), gotInventory); diff != "" {
+				t.Errorf("validateInventory() -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateVLANs(t *testing.T) {
+	cr, inv := getTestNetwork()
+	cr.Spec.BridgeDomains = nil
+	cr.Spec.RoutingTables = nil
+	for i := 0; i <= maxVLANs; i++ {
+		cr.Spec.BridgeDomains = append(cr.Spec.BridgeDomains, infrav1alpha1.BridgeDomain{
+			Name: fmt.Sprintf("bd%d", i),
+			Interfaces: []infrav1alpha1.Interface{{
+				Kind:           infrav1alpha1.InterfaceKindInterface,
+				NodeName:       pointer.String("leaf1"),
+				InterfaceName:  pointer.String("e1-1"),
+				AttachmentType: reqv1alpha1.AttachmentTypeVLAN,
+			}},
+		})
+	}
+	got := []string{}
+	for _, err := range validateInventory(cr, inv) {
+		got = append(got, err.Error())
+	}
+	want := []string{`spec: Forbidden: vlan database e1-1-leaf1 needs 4095 vlan ids, 4094 are available`}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("validateInventory() -want, +got:\n%s", diff)
+	}
+}
+
+func TestValidateUpdate(t *testing.T) {
+	oldCR, _ := getTestNetwork()
+	oldCR.Spec.RoutingTables[1].Name = oldCR.Spec.RoutingTables[0].Name
+
+	cases := map[string]struct {
+		mutate  func(cr *infrav1alpha1.Network)
+		wantErr bool
+	}{
+		"UnchangedSpec": {
+			mutate: func(cr *infrav1alpha1.Network) {
+				cr.SetAnnotations(map[string]string{"a": "b"})
+				cr.SetFinalizers(nil)
+			},
+		},
+		"Deleting": {
+			mutate: func(cr *infrav1alpha1.Network) {
+				now := metav1.Now()
+				cr.SetDeletionTimestamp(&now)
+				cr.Spec.Topology = "other"
+			},
+		},
+		"ChangedSpec": {
+			mutate: func(cr *infrav1alpha1.Network) {
+				cr.Spec.Topology = "other"
+			},
+			wantErr: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := oldCR.DeepCopy()
+			tc.mutate(cr)
+			v := &networkValidator{}
+			_, err := v.ValidateUpdate(context.Background(), oldCR, cr)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("ValidateUpdate() error = %v, wantErr %t", err, tc.wantErr)
+			}
+		})
+	}
+}
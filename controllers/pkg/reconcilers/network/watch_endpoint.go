@@ -22,35 +22,34 @@ import (
 	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
 	invv1alpha1 "github.com/nokia/k8s-ipam/apis/inv/v1alpha1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log"
-	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 type endpointEventHandler struct {
 	client client.Client
 }
 
-// Create enqueues a request for all ip allocation within the ipam
+// Create enqueues a request for the networks selecting the endpoint
 func (e *endpointEventHandler) Create(ctx context.Context, evt event.CreateEvent, q workqueue.RateLimitingInterface) {
 	e.add(ctx, evt.Object, q)
 }
 
-// Create enqueues a request for all ip allocation within the ipam
+// Update enqueues a request for the networks selecting the endpoint before or
+// after the update, so label changes are picked up by both
 func (e *endpointEventHandler) Update(ctx context.Context, evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
 	e.add(ctx, evt.ObjectOld, q)
 	e.add(ctx, evt.ObjectNew, q)
 }
 
-// Create enqueues a request for all ip allocation within the ipam
+// Delete enqueues a request for the networks selecting the endpoint
 func (e *endpointEventHandler) Delete(ctx context.Context, evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
 	e.add(ctx, evt.Object, q)
 }
 
-// Create enqueues a request for all ip allocation within the ipam
+// Generic enqueues a request for the networks selecting the endpoint
 func (e *endpointEventHandler) Generic(ctx context.Context, evt event.GenericEvent, q workqueue.RateLimitingInterface) {
 	e.add(ctx, evt.Object, q)
 }
@@ -63,19 +62,11 @@ func (e *endpointEventHandler) add(ctx context.Context, obj runtime.Object, queu
 	log := log.FromContext(ctx)
 	log.Info("event", "kind", obj.GetObjectKind(), "name", cr.GetName())
 
-	networks := &infrav1alpha1.NetworkList{}
-	if err := e.client.List(ctx, networks); err != nil {
+	// only endpoints of registered providers are used by the networks
+	if !isRegisteredProvider(cr.Labels) {
 		return
 	}
-
-	for _, network := range networks.Items {
-		// only enqueue if the provider and the network topology match
-		if cr.Labels[invv1alpha1.NephioProviderKey] == nokiaSRLProvider &&
-			cr.Labels[invv1alpha1.NephioTopologyKey] == network.Spec.Topology {
-			log.Info("event requeue network", "name", network.GetName())
-			queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{
-				Namespace: network.GetNamespace(),
-				Name:      network.GetName()}})
-		}
-	}
+	enqueueNetworks(ctx, e.client, cr.Labels[invv1alpha1.NephioTopologyKey], func(network *infrav1alpha1.Network) bool {
+		return selectsEndpoint(network, cr.Labels)
+	}, queue)
 }
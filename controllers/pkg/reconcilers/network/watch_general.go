@@ -16,6 +16,93 @@ limitations under the License.
 
 package network
 
+import (
+	"context"
+
+	"github.com/henderiw-nephio/network/pkg/endpoints"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// defaultRoutingTableName is the routing table the network library configures
+// on every node of the topology
+const defaultRoutingTableName = "default"
+
 type adder interface {
 	Add(item interface{})
 }
+
+// enqueueNetworks enqueues the networks of the topology affected by the change
+// of inventory, not ready networks of the topology are always enqueued as they
+// may wait for the inventory
+func enqueueNetworks(ctx context.Context, c client.Client, topology string, affected func(network *infrav1alpha1.Network) bool, queue adder) {
+	log := log.FromContext(ctx)
+
+	networks := &infrav1alpha1.NetworkList{}
+	if err := c.List(ctx, networks); err != nil {
+		log.Error(err, "cannot list networks")
+		return
+	}
+	for i := range networks.Items {
+		network := &networks.Items[i]
+		if network.Spec.Topology != topology {
+			continue
+		}
+		if network.GetCondition(infrav1alpha1.ConditionTypeReady).Status == metav1.ConditionTrue && !affected(network) {
+			continue
+		}
+		log.Info("event requeue network", "name", network.GetName())
+		queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: network.GetNamespace(),
+			Name:      network.GetName()}})
+	}
+}
+
+// selectsEndpoint returns true when an interface of the network selects an
+// endpoint with the labels
+func selectsEndpoint(network *infrav1alpha1.Network, l map[string]string) bool {
+	for _, bd := range network.Spec.BridgeDomains {
+		for _, itfce := range bd.Interfaces {
+			if interfaceSelects(itfce, l) {
+				return true
+			}
+		}
+	}
+	// bridgedomain interfaces of the routing tables use the interfaces of the
+	// bridge domains
+	for _, rt := range network.Spec.RoutingTables {
+		for _, itfce := range rt.Interfaces {
+			if itfce.Kind != infrav1alpha1.InterfaceKindBridgeDomain && interfaceSelects(itfce, l) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func interfaceSelects(itfce infrav1alpha1.Interface, l map[string]string) bool {
+	if itfce.Selector == nil && (itfce.NodeName == nil || itfce.InterfaceName == nil) {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(endpoints.GetSelector(itfce))
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(l))
+}
+
+// hasDefaultRoutingTable returns true when the network configures every node
+// of the topology
+func hasDefaultRoutingTable(network *infrav1alpha1.Network) bool {
+	for _, rt := range network.Spec.RoutingTables {
+		if rt.Name == defaultRoutingTableName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	invv1alpha1 "github.com/nokia/k8s-ipam/apis/inv/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+type linkEventHandler struct {
+	client client.Client
+}
+
+// Create enqueues a request for the networks selecting an endpoint of the link
+func (e *linkEventHandler) Create(ctx context.Context, evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	e.add(ctx, evt.Object, q)
+}
+
+// Update enqueues a request for the networks selecting an endpoint of the link
+// before or after the update
+func (e *linkEventHandler) Update(ctx context.Context, evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	e.add(ctx, evt.ObjectOld, q)
+	e.add(ctx, evt.ObjectNew, q)
+}
+
+// Delete enqueues a request for the networks selecting an endpoint of the link
+func (e *linkEventHandler) Delete(ctx context.Context, evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.add(ctx, evt.Object, q)
+}
+
+// Generic enqueues a request for the networks selecting an endpoint of the link
+func (e *linkEventHandler) Generic(ctx context.Context, evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	e.add(ctx, evt.Object, q)
+}
+
+func (e *linkEventHandler) add(ctx context.Context, obj runtime.Object, queue adder) {
+	cr, ok := obj.(*invv1alpha1.Link)
+	if !ok {
+		return
+	}
+	log := log.FromContext(ctx)
+	log.Info("event", "kind", obj.GetObjectKind(), "name", cr.GetName())
+
+	// a link connects the endpoints of two nodes, the networks selecting one of
+	// the endpoints are affected
+	for _, lep := range cr.Spec.Endpoints {
+		eps := &invv1alpha1.EndpointList{}
+		if err := e.client.List(ctx, eps, client.InNamespace(cr.GetNamespace()), client.MatchingLabels{
+			invv1alpha1.NephioNodeNameKey:      lep.NodeName,
+			invv1alpha1.NephioInterfaceNameKey: lep.InterfaceName,
+		}); err != nil {
+			log.Error(err, "cannot list endpoints")
+			return
+		}
+		for _, ep := range eps.Items {
+			if !isRegisteredProvider(ep.Labels) {
+				continue
+			}
+			enqueueNetworks(ctx, e.client, ep.Labels[invv1alpha1.NephioTopologyKey], func(network *infrav1alpha1.Network) bool {
+				return selectsEndpoint(network, ep.Labels)
+			}, queue)
+		}
+	}
+}
@@ -22,35 +22,34 @@ import (
 	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
 	invv1alpha1 "github.com/nokia/k8s-ipam/apis/inv/v1alpha1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log"
-	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 type nodeEventHandler struct {
 	client client.Client
 }
 
-// Create enqueues a request for all ip allocation within the ipam
+// Create enqueues a request for the networks configuring the node
 func (e *nodeEventHandler) Create(ctx context.Context, evt event.CreateEvent, q workqueue.RateLimitingInterface) {
 	e.add(ctx, evt.Object, q)
 }
 
-// Create enqueues a request for all ip allocation within the ipam
+// Update enqueues a request for the networks configuring the node before or
+// after the update, so label changes are picked up by both
 func (e *nodeEventHandler) Update(ctx context.Context, evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
 	e.add(ctx, evt.ObjectOld, q)
 	e.add(ctx, evt.ObjectNew, q)
 }
 
-// Create enqueues a request for all ip allocation within the ipam
+// Delete enqueues a request for the networks configuring the node
 func (e *nodeEventHandler) Delete(ctx context.Context, evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
 	e.add(ctx, evt.Object, q)
 }
 
-// Create enqueues a request for all ip allocation within the ipam
+// Generic enqueues a request for the networks configuring the node
 func (e *nodeEventHandler) Generic(ctx context.Context, evt event.GenericEvent, q workqueue.RateLimitingInterface) {
 	e.add(ctx, evt.Object, q)
 }
@@ -63,19 +62,31 @@ func (e *nodeEventHandler) add(ctx context.Context, obj runtime.Object, queue ad
 	log := log.FromContext(ctx)
 	log.Info("event", "kind", obj.GetObjectKind(), "name", cr.GetName())
 
-	networks := &infrav1alpha1.NetworkList{}
-	if err := e.client.List(ctx, networks); err != nil {
+	// only nodes of registered providers are used by the networks
+	if !isRegisteredProvider(cr.Labels) {
 		return
 	}
+	topology := cr.Labels[invv1alpha1.NephioTopologyKey]
 
-	for _, network := range networks.Items {
-		// only enqueue if the provider and the network topology match
-		if cr.Labels[invv1alpha1.NephioProviderKey] == nokiaSRLProvider &&
-			cr.Labels[invv1alpha1.NephioTopologyKey] == network.Spec.Topology {
-			log.Info("event requeue network", "name", network.GetName())
-			queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{
-				Namespace: network.GetNamespace(),
-				Name:      network.GetName()}})
-		}
+	// the endpoints of the node tell which networks select interfaces of the node
+	eps := &invv1alpha1.EndpointList{}
+	if err := e.client.List(ctx, eps, client.MatchingLabels{
+		invv1alpha1.NephioTopologyKey: topology,
+		invv1alpha1.NephioNodeNameKey: cr.GetName(),
+	}); err != nil {
+		log.Error(err, "cannot list endpoints")
+		return
 	}
+
+	enqueueNetworks(ctx, e.client, topology, func(network *infrav1alpha1.Network) bool {
+		if hasDefaultRoutingTable(network) {
+			return true
+		}
+		for _, ep := range eps.Items {
+			if selectsEndpoint(network, ep.Labels) {
+				return true
+			}
+		}
+		return false
+	}, queue)
 }
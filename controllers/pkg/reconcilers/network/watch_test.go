@@ -0,0 +1,237 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	invv1alpha1 "github.com/nokia/k8s-ipam/apis/inv/v1alpha1"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type testQueue struct {
+	names map[string]struct{}
+}
+
+func (r *testQueue) Add(item interface{}) {
+	r.names[item.(reconcile.Request).Name] = struct{}{}
+}
+
+func (r *testQueue) getNames() []string {
+	names := []string{}
+	for name := range r.names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestEventHandlers(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := infrav1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("cannot add infra to scheme: %v", err)
+	}
+	if err := invv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("cannot add inv to scheme: %v", err)
+	}
+	ready := infrav1alpha1.NetworkStatus{}
+	ready.SetConditions(infrav1alpha1.Ready())
+	objs := []client.Object{
+		// selects the endpoints of the edge01 cluster
+		&infrav1alpha1.Network{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ran"},
+			Spec: infrav1alpha1.NetworkSpec{
+				Topology: "nephio",
+				BridgeDomains: []infrav1alpha1.BridgeDomain{{
+					Name: "ran-bd",
+					Interfaces: []infrav1alpha1.Interface{{
+						Kind:     infrav1alpha1.InterfaceKindInterface,
+						Selector: &metav1.LabelSelector{MatchLabels: map[string]string{invv1alpha1.NephioClusterNameKey: "edge01"}},
+					}},
+				}},
+			},
+			Status: ready,
+		},
+		// configures every node of the topology
+		&infrav1alpha1.Network{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "core"},
+			Spec: infrav1alpha1.NetworkSpec{
+				Topology: "nephio",
+				RoutingTables: []infrav1alpha1.RoutingTable{{
+					Name:     "default",
+					Prefixes: []ipamv1alpha1.Prefix{{Prefix: "10.0.0.0/16"}},
+				}},
+			},
+			Status: ready,
+		},
+		// waits for the inventory of its topology
+		&infrav1alpha1.Network{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pending"},
+			Spec:       infrav1alpha1.NetworkSpec{Topology: "nephio"},
+		},
+		&infrav1alpha1.Network{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "other"},
+			Spec:       infrav1alpha1.NetworkSpec{Topology: "other"},
+		},
+	}
+	for _, ep := range []struct{ node, cluster string }{
+		{node: "leaf1", cluster: "edge01"},
+		{node: "leaf2", cluster: "edge02"},
+	} {
+		objs = append(objs, &invv1alpha1.Endpoint{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      ep.node + "-e1-1",
+			Labels: map[string]string{
+				invv1alpha1.NephioTopologyKey:      "nephio",
+				invv1alpha1.NephioProviderKey:      nokiaSRLProvider,
+				invv1alpha1.NephioNodeNameKey:      ep.node,
+				invv1alpha1.NephioInterfaceNameKey: "e1-1",
+				invv1alpha1.NephioClusterNameKey:   ep.cluster,
+			},
+		}})
+	}
+
+	cases := map[string]struct {
+		kind string
+		objs []runtime.Object
+		want []string
+	}{
+		"EndpointSelected": {
+			kind: invv1alpha1.EndpointKind,
+			objs: []runtime.Object{
+				&invv1alpha1.Endpoint{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "leaf1-e1-2", Labels: map[string]string{
+					invv1alpha1.NephioTopologyKey:    "nephio",
+					invv1alpha1.NephioProviderKey:    nokiaSRLProvider,
+					invv1alpha1.NephioClusterNameKey: "edge01",
+				}}},
+			},
+			want: []string{"pending", "ran"},
+		},
+		"EndpointNotSelected": {
+			kind: invv1alpha1.EndpointKind,
+			objs: []runtime.Object{
+				&invv1alpha1.Endpoint{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "leaf1-e1-2", Labels: map[string]string{
+					invv1alpha1.NephioTopologyKey:    "nephio",
+					invv1alpha1.NephioProviderKey:    nokiaSRLProvider,
+					invv1alpha1.NephioClusterNameKey: "edge02",
+				}}},
+			},
+			want: []string{"pending"},
+		},
+		"EndpointLabelChanged": {
+			kind: invv1alpha1.EndpointKind,
+			objs: []runtime.Object{
+				&invv1alpha1.Endpoint{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "leaf1-e1-2", Labels: map[string]string{
+					invv1alpha1.NephioTopologyKey:    "nephio",
+					invv1alpha1.NephioProviderKey:    nokiaSRLProvider,
+					invv1alpha1.NephioClusterNameKey: "edge01",
+				}}},
+				&invv1alpha1.Endpoint{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "leaf1-e1-2", Labels: map[string]string{
+					invv1alpha1.NephioTopologyKey:    "nephio",
+					invv1alpha1.NephioProviderKey:    nokiaSRLProvider,
+					invv1alpha1.NephioClusterNameKey: "edge02",
+				}}},
+			},
+			want: []string{"pending", "ran"},
+		},
+		"EndpointUnregisteredProvider": {
+			kind: invv1alpha1.EndpointKind,
+			objs: []runtime.Object{
+				&invv1alpha1.Endpoint{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "leaf1-e1-2", Labels: map[string]string{
+					invv1alpha1.NephioTopologyKey:    "nephio",
+					invv1alpha1.NephioProviderKey:    "unknown.example.com",
+					invv1alpha1.NephioClusterNameKey: "edge01",
+				}}},
+			},
+			want: []string{},
+		},
+		"NodeSelectedEndpoints": {
+			kind: invv1alpha1.NodeKind,
+			objs: []runtime.Object{
+				&invv1alpha1.Node{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "leaf1", Labels: map[string]string{
+					invv1alpha1.NephioTopologyKey: "nephio",
+					invv1alpha1.NephioProviderKey: nokiaSRLProvider,
+				}}},
+			},
+			want: []string{"core", "pending", "ran"},
+		},
+		"NodeNoSelectedEndpoints": {
+			kind: invv1alpha1.NodeKind,
+			objs: []runtime.Object{
+				&invv1alpha1.Node{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "leaf2", Labels: map[string]string{
+					invv1alpha1.NephioTopologyKey: "nephio",
+					invv1alpha1.NephioProviderKey: nokiaSRLProvider,
+				}}},
+			},
+			want: []string{"core", "pending"},
+		},
+		"LinkSelectedEndpoint": {
+			kind: invv1alpha1.LinkKind,
+			objs: []runtime.Object{
+				&invv1alpha1.Link{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "leaf1-spine1"},
+					Spec: invv1alpha1.LinkSpec{Endpoints: []invv1alpha1.LinkEndpoint{
+						{NodeName: "leaf1", InterfaceName: "e1-1"},
+						{NodeName: "spine1", InterfaceName: "e1-1"},
+					}},
+				},
+			},
+			want: []string{"pending", "ran"},
+		},
+		"LinkNotSelectedEndpoint": {
+			kind: invv1alpha1.LinkKind,
+			objs: []runtime.Object{
+				&invv1alpha1.Link{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "leaf2-spine1"},
+					Spec: invv1alpha1.LinkSpec{Endpoints: []invv1alpha1.LinkEndpoint{
+						{NodeName: "leaf2", InterfaceName: "e1-1"},
+						{NodeName: "spine1", InterfaceName: "e1-1"},
+					}},
+				},
+			},
+			want: []string{"pending"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+			handlers := map[string]interface {
+				add(ctx context.Context, obj runtime.Object, queue adder)
+			}{
+				invv1alpha1.EndpointKind: &endpointEventHandler{client: c},
+				invv1alpha1.NodeKind:     &nodeEventHandler{client: c},
+				invv1alpha1.LinkKind:     &linkEventHandler{client: c},
+			}
+			q := &testQueue{names: map[string]struct{}{}}
+			for _, obj := range tc.objs {
+				handlers[tc.kind].add(context.Background(), obj, q)
+			}
+			if diff := cmp.Diff(tc.want, q.getNames()); diff != "" {
+				t.Errorf("add() -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+//+kubebuilder:webhook:path=/validate-infra-nephio-org-v1alpha1-network,mutating=false,failurePolicy=fail,sideEffects=None,groups=infra.nephio.org,resources=networks,verbs=create;update,versions=v1alpha1,name=vnetwork.infra.nephio.org,admissionReviewVersions=v1
+
+// networkValidator rejects Networks with an invalid spec. The problems found
+// against the inventory are returned as warnings, as the endpoints and nodes
+// may be created after the Network; the reconciler reports them in the status.
+type networkValidator struct {
+	r *reconciler
+}
+
+func (v *networkValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return v.validate(ctx, obj)
+}
+
+// ValidateUpdate only validates a changed spec of a Network that is not being
+// deleted, so a Network created before a rule was enforced can still be
+// updated by the reconciler, e.g. to record its status or remove its
+// finalizer, and deleted
+func (v *networkValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldCR, ok := oldObj.(*infrav1alpha1.Network)
+	if !ok {
+		return nil, fmt.Errorf("expecting Network, got: %s", reflect.TypeOf(oldObj).Name())
+	}
+	cr, ok := newObj.(*infrav1alpha1.Network)
+	if !ok {
+		return nil, fmt.Errorf("expecting Network, got: %s", reflect.TypeOf(newObj).Name())
+	}
+	if cr.GetDeletionTimestamp() != nil || equality.Semantic.DeepEqual(oldCR.Spec, cr.Spec) {
+		return nil, nil
+	}
+	return v.validate(ctx, newObj)
+}
+
+func (v *networkValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *networkValidator) validate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	cr, ok := obj.(*infrav1alpha1.Network)
+	if !ok {
+		return nil, fmt.Errorf("expecting Network, got: %s", reflect.TypeOf(obj).Name())
+	}
+	if errs := validateSpec(cr); len(errs) > 0 {
+		return nil, apierrors.NewInvalid(schema.GroupKind{Group: infrav1alpha1.GroupVersion.Group, Kind: infrav1alpha1.NetworkKind}, cr.GetName(), errs)
+	}
+
+	inv, err := v.r.getInventory(ctx, cr.Spec.Topology)
+	if err != nil {
+		return nil, err
+	}
+	var warnings admission.Warnings
+	for _, err := range validateInventory(cr, inv) {
+		warnings = append(warnings, err.Error())
+	}
+	return warnings, nil
+}
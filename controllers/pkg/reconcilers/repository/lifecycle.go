@@ -0,0 +1,129 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	porchconfigv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// ExternalNameAnnotationName records the identity of the git repository
+	// managed by the repository as <owner>/<name>. It is set by the
+	// reconciler once the git repository is created or adopted and is copied
+	// to the objects that are orphaned with the git repository.
+	ExternalNameAnnotationName = "repository.nephio.org/external-name"
+	// AdoptAnnotationName set to true allows the repository to take over an
+	// existing git repository it does not manage yet
+	AdoptAnnotationName = "repository.nephio.org/adopt"
+)
+
+func getExternalName(owner string, cr *infrav1alpha1.Repository) string {
+	return owner + "/" + cr.GetName()
+}
+
+// isManaged returns true when the git repository with the external name is
+// managed by the repository
+func isManaged(externalName string, cr *infrav1alpha1.Repository) bool {
+	if name, ok := cr.GetAnnotations()[ExternalNameAnnotationName]; ok {
+		return name == externalName
+	}
+	// repositories that were reconciled before the external name was recorded
+	return cr.Status.URL != nil
+}
+
+// adoptRepo ensures the existing git repository is managed by the repository.
+// A git repository that is not managed yet is only taken over when the
+// repository has the adopt annotation.
+func adoptRepo(ctx context.Context, owner string, cr *infrav1alpha1.Repository) error {
+	externalName := getExternalName(owner, cr)
+	if !isManaged(externalName, cr) {
+		if cr.GetAnnotations()[AdoptAnnotationName] != "true" {
+			return fmt.Errorf("repository %s already exists, set the annotation %s to adopt it", externalName, AdoptAnnotationName)
+		}
+		log.FromContext(ctx).Info("repository adopted", "name", externalName)
+	}
+	resource.AddAnnotations(cr, map[string]string{ExternalNameAnnotationName: externalName})
+	return nil
+}
+
+// recordExternalName persists the external name recorded on the repository
+// during the reconcile. Only the annotations are patched, so the status of
+// the repository is kept to be updated at the end of the reconcile.
+func (r *reconciler) recordExternalName(ctx context.Context, orig, cr *infrav1alpha1.Repository) error {
+	externalName := cr.GetAnnotations()[ExternalNameAnnotationName]
+	if externalName == "" || orig.GetAnnotations()[ExternalNameAnnotationName] == externalName {
+		return nil
+	}
+	patched := orig.DeepCopy()
+	resource.AddAnnotations(patched, map[string]string{ExternalNameAnnotationName: externalName})
+	if err := r.Patch(ctx, patched, client.MergeFrom(orig)); err != nil {
+		return errors.Wrap(err, "cannot record external name")
+	}
+	cr.SetResourceVersion(patched.GetResourceVersion())
+	return nil
+}
+
+// orphanPorchRepo detaches the porch Repository and the Token from the
+// repository, so they are not garbage collected with it and porch keeps using
+// the orphaned git repository. The external name is recorded on both, a new
+// repository adopting the git repository takes them over again.
+func (r *reconciler) orphanPorchRepo(ctx context.Context, cr *infrav1alpha1.Repository) error {
+	for name, o := range map[string]client.Object{
+		cr.GetName():                    &porchconfigv1alpha1.Repository{},
+		cr.GetName() + porchTokenSuffix: &infrav1alpha1.Token{},
+	} {
+		if err := r.Get(ctx, types.NamespacedName{Namespace: cr.GetNamespace(), Name: name}, o); err != nil {
+			if resource.IgnoreNotFound(err) != nil {
+				return errors.Wrap(err, "cannot get porch registration")
+			}
+			continue
+		}
+		if !metav1.IsControlledBy(o, cr) {
+			continue
+		}
+		o.SetOwnerReferences(removeOwnerReference(o.GetOwnerReferences(), cr.GetUID()))
+		if externalName := cr.GetAnnotations()[ExternalNameAnnotationName]; externalName != "" {
+			resource.AddAnnotations(o, map[string]string{ExternalNameAnnotationName: externalName})
+		}
+		if err := r.Update(ctx, o); err != nil {
+			cr.SetConditions(infrav1alpha1.Failed("cannot orphan porch registration"))
+			return errors.Wrap(err, "cannot orphan porch registration")
+		}
+		log.FromContext(ctx).Info("porch registration orphaned", "name", name)
+	}
+	return nil
+}
+
+func removeOwnerReference(refs []metav1.OwnerReference, uid types.UID) []metav1.OwnerReference {
+	kept := []metav1.OwnerReference{}
+	for _, ref := range refs {
+		if ref.UID != uid {
+			kept = append(kept, ref)
+		}
+	}
+	return kept
+}
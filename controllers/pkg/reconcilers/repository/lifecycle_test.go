@@ -0,0 +1,124 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	porchconfigv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestOrphanPorchRepo(t *testing.T) {
+	r := newPorchTestReconciler(t)
+	ctx := context.Background()
+	url := "http://gitea/nephio/edge01.git"
+	cr := &infrav1alpha1.Repository{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "edge01",
+			UID:       "1234",
+			Annotations: map[string]string{
+				PorchRoleAnnotationName:    string(PorchRoleDeployment),
+				ExternalNameAnnotationName: "nephio/edge01",
+			},
+		},
+		Status: infrav1alpha1.RepositoryStatus{URL: &url},
+	}
+	if err := r.syncPorchRepo(ctx, cr); err != nil {
+		t.Fatalf("syncPorchRepo() unexpected error: %v", err)
+	}
+	if err := r.orphanPorchRepo(ctx, cr); err != nil {
+		t.Fatalf("orphanPorchRepo() unexpected error: %v", err)
+	}
+	objects := map[string]client.Object{
+		"edge01":                    &porchconfigv1alpha1.Repository{},
+		"edge01-access-token-porch": &infrav1alpha1.Token{},
+	}
+	for name, o := range objects {
+		if err := r.Get(ctx, types.NamespacedName{Namespace: "default", Name: name}, o); err != nil {
+			t.Fatalf("orphaned object %s not found: %v", name, err)
+		}
+		if diff := cmp.Diff(0, len(o.GetOwnerReferences())); diff != "" {
+			t.Errorf("owner references of %s -want, +got:\n%s", name, diff)
+		}
+		if diff := cmp.Diff("nephio/edge01", o.GetAnnotations()[ExternalNameAnnotationName]); diff != "" {
+			t.Errorf("external name of %s -want, +got:\n%s", name, diff)
+		}
+	}
+	// the orphaned registration is not deleted with the repository
+	if err := r.deletePorchRepo(ctx, cr); err != nil {
+		t.Fatalf("deletePorchRepo() unexpected error: %v", err)
+	}
+
+	// a new repository adopting the git repository takes the registration
+	// over again
+	adopted := cr.DeepCopy()
+	adopted.SetUID("5678")
+	if err := r.syncPorchRepo(ctx, adopted); err != nil {
+		t.Fatalf("syncPorchRepo() unexpected error: %v", err)
+	}
+	for name, o := range objects {
+		if err := r.Get(ctx, types.NamespacedName{Namespace: "default", Name: name}, o); err != nil {
+			t.Fatalf("adopted object %s not found: %v", name, err)
+		}
+		if !metav1.IsControlledBy(o, adopted) {
+			t.Errorf("%s is not controlled by the adopting repository", name)
+		}
+	}
+}
+
+func TestRecordExternalName(t *testing.T) {
+	r := newPorchTestReconciler(t)
+	ctx := context.Background()
+	orig := &infrav1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "edge01"}}
+	if err := r.Create(ctx, orig); err != nil {
+		t.Fatal(err)
+	}
+	cr := orig.DeepCopy()
+	if err := adoptRepo(ctx, "nephio", cr); err == nil {
+		t.Fatalf("adoptRepo() expected error for a repository that is not managed")
+	}
+	cr.SetAnnotations(map[string]string{AdoptAnnotationName: "true"})
+	if err := adoptRepo(ctx, "nephio", cr); err != nil {
+		t.Fatalf("adoptRepo() unexpected error: %v", err)
+	}
+	cr.SetConditions(infrav1alpha1.Ready())
+	if err := r.recordExternalName(ctx, orig, cr); err != nil {
+		t.Fatalf("recordExternalName() unexpected error: %v", err)
+	}
+	// the status set during the reconcile is kept
+	if diff := cmp.Diff(metav1.ConditionTrue, cr.GetCondition(infrav1alpha1.ConditionTypeReady).Status); diff != "" {
+		t.Errorf("ready condition -want, +got:\n%s", diff)
+	}
+
+	got := &infrav1alpha1.Repository{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: "default", Name: "edge01"}, got); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("nephio/edge01", got.GetAnnotations()[ExternalNameAnnotationName]); diff != "" {
+		t.Errorf("external name -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(got.GetResourceVersion(), cr.GetResourceVersion()); diff != "" {
+		t.Errorf("resource version -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	porchconfigv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	commonv1alpha1 "github.com/nephio-project/api/common/v1alpha1"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// PorchRoleAnnotationName opts the repository in to be registered in
+	// porch with the role: blueprint, deployment or staging
+	PorchRoleAnnotationName = "repository.nephio.org/porch-role"
+
+	// stagingAnnotationName marks the porch repository as staging repository
+	// for the bootstrap-packages reconciler
+	stagingAnnotationName = "nephio.org/staging"
+	// porchTokenSuffix is appended to the name of the repository to name the
+	// token and the secret porch uses to authenticate to the repository
+	porchTokenSuffix = "-access-token-porch"
+	defaultBranch    = "main"
+)
+
+// PorchRole is the role of the repository in porch
+type PorchRole string
+
+const (
+	PorchRoleBlueprint  PorchRole = "blueprint"
+	PorchRoleDeployment PorchRole = "deployment"
+	PorchRoleStaging    PorchRole = "staging"
+)
+
+func getPorchRole(cr *infrav1alpha1.Repository) (PorchRole, error) {
+	switch role := PorchRole(cr.GetAnnotations()[PorchRoleAnnotationName]); role {
+	case "", PorchRoleBlueprint, PorchRoleDeployment, PorchRoleStaging:
+		return role, nil
+	default:
+		return "", fmt.Errorf("unsupported porch role %s", role)
+	}
+}
+
+// syncPorchRepo registers the repository in porch when the repository has a
+// porch role. The credentials porch uses are provided by a Token, for which
+// the token reconciler creates the secret. Both the Token and the porch
+// Repository are owned by the repository, so they are garbage collected with
+// it.
+func (r *reconciler) syncPorchRepo(ctx context.Context, cr *infrav1alpha1.Repository) error {
+	log := log.FromContext(ctx)
+	role, err := getPorchRole(cr)
+	if err != nil {
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return err
+	}
+	if role == "" {
+		return nil
+	}
+	if cr.Status.URL == nil {
+		err := fmt.Errorf("cannot register repository in porch without url")
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return err
+	}
+
+	token := buildToken(cr)
+	if err := r.Apply(ctx, token); err != nil {
+		log.Error(err, "cannot apply token for porch")
+		cr.SetConditions(infrav1alpha1.Failed("cannot apply token for porch"))
+		return err
+	}
+	repo := buildPorchRepo(cr, role, token.GetName())
+	if err := r.Apply(ctx, repo); err != nil {
+		log.Error(err, "cannot apply porch repository")
+		cr.SetConditions(infrav1alpha1.Failed("cannot apply porch repository"))
+		return err
+	}
+	log.Info("repository registered in porch", "role", role)
+	return nil
+}
+
+// deletePorchRepo deletes the porch Repository and the Token when they are
+// owned by the repository, so porch stops using the git repository before it
+// is deleted
+func (r *reconciler) deletePorchRepo(ctx context.Context, cr *infrav1alpha1.Repository) error {
+	if role, _ := getPorchRole(cr); role == "" {
+		return nil
+	}
+	for name, o := range map[string]client.Object{
+		cr.GetName():                    &porchconfigv1alpha1.Repository{},
+		cr.GetName() + porchTokenSuffix: &infrav1alpha1.Token{},
+	} {
+		if err := r.Get(ctx, types.NamespacedName{Namespace: cr.GetNamespace(), Name: name}, o); err != nil {
+			if resource.IgnoreNotFound(err) != nil {
+				return errors.Wrap(err, "cannot get porch registration")
+			}
+			continue
+		}
+		if !metav1.IsControlledBy(o, cr) {
+			continue
+		}
+		if err := r.Delete(ctx, o); resource.IgnoreNotFound(err) != nil {
+			cr.SetConditions(infrav1alpha1.Failed("cannot delete porch registration"))
+			return errors.Wrap(err, "cannot delete porch registration")
+		}
+		log.FromContext(ctx).Info("porch registration deleted", "name", name)
+	}
+	return nil
+}
+
+func buildToken(cr *infrav1alpha1.Repository) *infrav1alpha1.Token {
+	return &infrav1alpha1.Token{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: infrav1alpha1.GroupVersion.String(),
+			Kind:       infrav1alpha1.TokenKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       cr.GetNamespace(),
+			Name:            cr.GetName() + porchTokenSuffix,
+			OwnerReferences: []metav1.OwnerReference{getOwnerReference(cr)},
+		},
+		Spec: infrav1alpha1.TokenSpec{
+			Lifecycle: commonv1alpha1.Lifecycle{DeletionPolicy: commonv1alpha1.DeletionDelete},
+		},
+	}
+}
+
+func buildPorchRepo(cr *infrav1alpha1.Repository, role PorchRole, secretName string) *porchconfigv1alpha1.Repository {
+	repo := &porchconfigv1alpha1.Repository{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: porchconfigv1alpha1.GroupVersion.String(),
+			Kind:       porchconfigv1alpha1.TypeRepository.Kind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       cr.GetNamespace(),
+			Name:            cr.GetName(),
+			OwnerReferences: []metav1.OwnerReference{getOwnerReference(cr)},
+		},
+		Spec: porchconfigv1alpha1.RepositorySpec{
+			Deployment: role == PorchRoleDeployment,
+			Type:       porchconfigv1alpha1.RepositoryTypeGit,
+			Content:    porchconfigv1alpha1.RepositoryContentPackage,
+			Git: &porchconfigv1alpha1.GitRepository{
+				Repo:      *cr.Status.URL,
+				Branch:    defaultBranch,
+				SecretRef: porchconfigv1alpha1.SecretRef{Name: secretName},
+			},
+		},
+	}
+	if cr.Spec.Description != nil {
+		repo.Spec.Description = *cr.Spec.Description
+	}
+	if cr.Spec.DefaultBranch != nil {
+		repo.Spec.Git.Branch = *cr.Spec.DefaultBranch
+	}
+	if role == PorchRoleStaging {
+		repo.SetAnnotations(map[string]string{stagingAnnotationName: "true"})
+	}
+	return repo
+}
+
+func getOwnerReference(cr *infrav1alpha1.Repository) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: infrav1alpha1.GroupVersion.String(),
+		Kind:       infrav1alpha1.RepositoryKind,
+		Name:       cr.GetName(),
+		UID:        cr.GetUID(),
+		Controller: pointer.Bool(true),
+	}
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	porchconfigv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newPorchTestReconciler(t *testing.T) *reconciler {
+	scheme := runtime.NewScheme()
+	if err := infrav1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := porchconfigv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return &reconciler{APIPatchingApplicator: resource.NewAPIPatchingApplicator(fake.NewClientBuilder().WithScheme(scheme).Build())}
+}
+
+func TestSyncPorchRepo(t *testing.T) {
+	url := "http://gitea/nephio/edge01.git"
+	branch := "stable"
+	cases := map[string]struct {
+		role           string
+		url            *string
+		wantErr        bool
+		wantRegistered bool
+		wantDeployment bool
+		wantStaging    bool
+	}{
+		"NoRole": {
+			url: &url,
+		},
+		"UnknownRole": {
+			role:    "mirror",
+			url:     &url,
+			wantErr: true,
+		},
+		"NoURL": {
+			role:    "deployment",
+			wantErr: true,
+		},
+		"Blueprint": {
+			role:           "blueprint",
+			url:            &url,
+			wantRegistered: true,
+		},
+		"Deployment": {
+			role:           "deployment",
+			url:            &url,
+			wantRegistered: true,
+			wantDeployment: true,
+		},
+		"Staging": {
+			role:           "staging",
+			url:            &url,
+			wantRegistered: true,
+			wantStaging:    true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := newPorchTestReconciler(t)
+			ctx := context.Background()
+			cr := &infrav1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "default",
+					Name:        "edge01",
+					UID:         "1234",
+					Annotations: map[string]string{PorchRoleAnnotationName: tc.role},
+				},
+				Spec:   infrav1alpha1.RepositorySpec{DefaultBranch: &branch},
+				Status: infrav1alpha1.RepositoryStatus{URL: tc.url},
+			}
+			if err := r.syncPorchRepo(ctx, cr); (err != nil) != tc.wantErr {
+				t.Fatalf("syncPorchRepo() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			repo := &porchconfigv1alpha1.Repository{}
+			err := r.Get(ctx, types.NamespacedName{Namespace: "default", Name: "edge01"}, repo)
+			if diff := cmp.Diff(tc.wantRegistered, err == nil); diff != "" {
+				t.Fatalf("porch repository registered -want, +got:\n%s", diff)
+			}
+			if !tc.wantRegistered {
+				return
+			}
+			wantGit := &porchconfigv1alpha1.GitRepository{
+				Repo:      url,
+				Branch:    branch,
+				SecretRef: porchconfigv1alpha1.SecretRef{Name: "edge01-access-token-porch"},
+			}
+			if diff := cmp.Diff(wantGit, repo.Spec.Git); diff != "" {
+				t.Errorf("porch repository git -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantDeployment, repo.Spec.Deployment); diff != "" {
+				t.Errorf("porch repository deployment -want, +got:\n%s", diff)
+			}
+			_, staging := repo.GetAnnotations()[stagingAnnotationName]
+			if diff := cmp.Diff(tc.wantStaging, staging); diff != "" {
+				t.Errorf("porch repository staging -want, +got:\n%s", diff)
+			}
+			if !metav1.IsControlledBy(repo, cr) {
+				t.Errorf("porch repository is not controlled by the repository")
+			}
+			token := &infrav1alpha1.Token{}
+			if err := r.Get(ctx, types.NamespacedName{Namespace: "default", Name: "edge01-access-token-porch"}, token); err != nil {
+				t.Fatalf("cannot get token: %v", err)
+			}
+
+			// deleting the registration removes both the porch repository and
+			// the token
+			if err := r.deletePorchRepo(ctx, cr); err != nil {
+				t.Fatalf("deletePorchRepo() unexpected error: %v", err)
+			}
+			if err := r.Get(ctx, types.NamespacedName{Namespace: "default", Name: "edge01"}, repo); resource.IgnoreNotFound(err) != nil || err == nil {
+				t.Errorf("porch repository not deleted: %v", err)
+			}
+			if err := r.Get(ctx, types.NamespacedName{Namespace: "default", Name: "edge01-access-token-porch"}, token); resource.IgnoreNotFound(err) != nil || err == nil {
+				t.Errorf("token not deleted: %v", err)
+			}
+		})
+	}
+}
+
+func TestDeletePorchRepoNotOwned(t *testing.T) {
+	r := newPorchTestReconciler(t)
+	ctx := context.Background()
+	// a porch repository that was registered by hand is kept
+	repo := &porchconfigv1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "edge01"}}
+	if err := r.Create(ctx, repo); err != nil {
+		t.Fatal(err)
+	}
+	cr := &infrav1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "default",
+		Name:        "edge01",
+		UID:         "1234",
+		Annotations: map[string]string{PorchRoleAnnotationName: string(PorchRoleBlueprint)},
+	}}
+	if err := r.deletePorchRepo(ctx, cr); err != nil {
+		t.Fatalf("deletePorchRepo() unexpected error: %v", err)
+	}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: "default", Name: "edge01"}, repo); err != nil {
+		t.Errorf("porch repository not owned by the repository was deleted: %v", err)
+	}
+}
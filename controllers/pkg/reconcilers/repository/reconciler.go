@@ -20,18 +20,20 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 
-	"code.gitea.io/sdk/gitea"
+	porchconfigv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
 	commonv1alpha1 "github.com/nephio-project/api/common/v1alpha1"
 	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
-	"github.com/nephio-project/nephio/controllers/pkg/giteaclient"
+	"github.com/nephio-project/nephio/controllers/pkg/gitclient"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
 	ctrlconfig "github.com/nephio-project/nephio/controllers/pkg/reconcilers/config"
 	reconcilerinterface "github.com/nephio-project/nephio/controllers/pkg/reconcilers/reconciler-interface"
 	"github.com/nephio-project/nephio/controllers/pkg/resource"
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -43,21 +45,36 @@ func init() {
 const (
 	finalizer       = "infra.nephio.org/finalizer"
 	errUpdateStatus = "cannot update status"
+
+	// OwnerAnnotationName selects the organization owning the repository, when
+	// not set the repository is owned by the authenticated user
+	OwnerAnnotationName = "repository.nephio.org/owner"
+	// CreateOwnerAnnotationName allows the organization to be created when it
+	// does not exist
+	CreateOwnerAnnotationName = "repository.nephio.org/create-owner"
+	// TeamAnnotationName selects the team of the organization that is granted
+	// access to the repository
+	TeamAnnotationName = "repository.nephio.org/team"
+	// TeamPermissionAnnotationName sets the permission of the team: read,
+	// write (default) or admin
+	TeamPermissionAnnotationName = "repository.nephio.org/team-permission"
 )
 
 //+kubebuilder:rbac:groups=infra.nephio.org,resources=repositories,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=infra.nephio.org,resources=repositories/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=infra.nephio.org,resources=tokens,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=config.porch.kpt.dev,resources=repositories,verbs=get;list;watch;create;update;patch;delete
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c interface{}) (map[schema.GroupVersionKind]chan event.GenericEvent, error) {
 	cfg, ok := c.(*ctrlconfig.ControllerConfig)
-	// Sending the porchclient to gitea, this will be used to get
-	// the secret objects for gitea client authentication. The client
+	// Sending the porchclient to the git client, this will be used to get
+	// the secret objects for git client authentication. The client
 	// of the manager of this controller cannot be used at this point.
 	// Should this be conditional ? Only if we have repo/token reconciler
 
 	var e error
-	r.giteaClient, e = giteaclient.GetClient(ctx, resource.NewAPIPatchingApplicator(cfg.PorchClient))
+	r.gitClient, e = gitclient.GetClient(ctx, resource.NewAPIPatchingApplicator(cfg.PorchClient))
 	if e != nil {
 		return nil, e
 	}
@@ -69,20 +86,27 @@ func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c i
 	if err := infrav1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
 		return nil, err
 	}
+	if err := porchconfigv1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
+		return nil, err
+	}
 
 	r.APIPatchingApplicator = resource.NewAPIPatchingApplicator(mgr.GetClient())
 	r.finalizer = resource.NewAPIFinalizer(mgr.GetClient(), finalizer)
+	r.backoff = gitclient.NewBackoff()
 
 	return nil, ctrl.NewControllerManagedBy(mgr).
 		Named("RepositoryController").
 		For(&infrav1alpha1.Repository{}).
+		Owns(&porchconfigv1alpha1.Repository{}).
+		Owns(&infrav1alpha1.Token{}).
 		Complete(r)
 }
 
 type reconciler struct {
 	resource.APIPatchingApplicator
-	giteaClient giteaclient.GiteaClient
-	finalizer   *resource.APIFinalizer
+	gitClient gitprovider.GitProvider
+	finalizer *resource.APIFinalizer
+	backoff   *gitclient.Backoff
 }
 
 func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -99,33 +123,45 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{}, nil
 	}
 
-	// check if client exists otherwise retry
-	if !r.giteaClient.IsInitialized() {
-		err := fmt.Errorf("gitea server unreachable")
+	// check if client is connected otherwise retry once the git client had
+	// the chance to reconnect
+	if !r.gitClient.IsInitialized() {
+		err := fmt.Errorf("git server unreachable: %s", gitclient.GetStatus(r.gitClient))
 		log.Error(err, "cannot connect to git server")
 		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
-		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+		return ctrl.Result{RequeueAfter: gitclient.GetRetryInterval(r.gitClient)}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 	}
 
 	if resource.WasDeleted(cr) {
-		// TODO DELETION POLICY: "orphan" deletion policy
 		// repo being deleted
-		// Delete the repo from the git server
-		// when successful remove the finalizer
+		// Delete the repo from the git server or orphan it based on the
+		// deletion policy, when successful remove the finalizer
+		if cr.Spec.Lifecycle.DeletionPolicy == commonv1alpha1.DeletionOrphan {
+			// the git repo is kept and porch keeps using it
+			if err := r.orphanPorchRepo(ctx, cr); err != nil {
+				log.Error(err, "cannot orphan porch registration")
+				return r.retry(ctx, cr, err)
+			}
+			log.Info("repo orphaned", "name", cr.GetAnnotations()[ExternalNameAnnotationName])
+		} else if err := r.deletePorchRepo(ctx, cr); err != nil {
+			log.Error(err, "cannot delete porch registration")
+			return r.retry(ctx, cr, err)
+		}
 		if cr.Spec.Lifecycle.DeletionPolicy == commonv1alpha1.DeletionDelete {
-			if err := r.deleteRepo(ctx, r.giteaClient, cr); err != nil {
+			if err := r.deleteRepo(ctx, r.gitClient, cr); err != nil {
 				log.Error(err, "cannot delete repo in git server")
-				return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+				return r.retry(ctx, cr, err)
 			}
 		}
 
 		if err := r.finalizer.RemoveFinalizer(ctx, cr); err != nil {
 			log.Error(err, "cannot remove finalizer")
 			cr.SetConditions(infrav1alpha1.Failed(err.Error()))
-			return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+			return r.retry(ctx, cr, err)
 		}
 
 		log.Info("Successfully deleted resource")
+		r.backoff.Reset(req.String())
 		return ctrl.Result{Requeue: false}, nil
 	}
 
@@ -133,30 +169,83 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	if err := r.finalizer.AddFinalizer(ctx, cr); err != nil {
 		log.Error(err, "cannot add finalizer")
 		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
-		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+		return r.retry(ctx, cr, err)
 	}
 
 	// upsert repo in git server
-	if err := r.upsertRepo(ctx, r.giteaClient, cr); err != nil {
-		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	orig := cr.DeepCopy()
+	if err := r.upsertRepo(ctx, r.gitClient, cr); err != nil {
+		return r.retry(ctx, cr, err)
 	}
+	if err := r.recordExternalName(ctx, orig, cr); err != nil {
+		log.Error(err, "cannot record external name")
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return r.retry(ctx, cr, err)
+	}
+	r.backoff.Reset(req.String())
 	cr.SetConditions(infrav1alpha1.Ready())
-	return ctrl.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	return ctrl.Result{RequeueAfter: getResyncInterval(cr)}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 }
 
-func (r *reconciler) upsertRepo(ctx context.Context, giteaClient giteaclient.GiteaClient, cr *infrav1alpha1.Repository) error {
+// retry updates the status of the failed repository and requeues it after the
+// backoff of the class of the error, so errors that need a change of the
+// credentials or the git server are not retried in a tight loop
+func (r *reconciler) retry(ctx context.Context, cr *infrav1alpha1.Repository, err error) (ctrl.Result, error) {
+	requeueAfter := r.backoff.Next(client.ObjectKeyFromObject(cr).String(), err)
+	log.FromContext(ctx).Info("retry", "reason", gitclient.ConditionReason(err), "after", requeueAfter)
+	return ctrl.Result{RequeueAfter: requeueAfter}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+}
+
+func (r *reconciler) upsertRepo(ctx context.Context, gitClient gitprovider.GitProvider, cr *infrav1alpha1.Repository) error {
 	log := log.FromContext(ctx)
-	u, _, err := giteaClient.GetMyUserInfo()
+	u, err := gitClient.GetMyUserInfo()
 	if err != nil {
 		log.Error(err, "cannot get user info")
+		cr.SetConditions(gitclient.Failed(err.Error(), err))
+		return err
+	}
+
+	owner, err := r.ensureOwner(ctx, gitClient, u, cr)
+	if err != nil {
+		return err
+	}
+
+	upstream, err := getUpstream(cr)
+	if err != nil {
 		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
 		return err
 	}
 
-	_, _, err = giteaClient.GetRepo(u.UserName, cr.GetName())
+	existing, err := gitClient.GetRepo(owner, cr.GetName())
+	if err != nil && !gitprovider.IsNotFound(err) {
+		// only a repository that does not exist is created, other errors are
+		// retried with the backoff of their class
+		log.Error(err, "cannot get repo")
+		cr.SetConditions(gitclient.Failed("cannot get repo", err))
+		return err
+	}
+	if err != nil && upstream != nil {
+		// import or mirror the repo from its upstream
+		log.Info("repository", "upstream", upstream.url, "mode", upstream.mode)
+		repo, err := r.migrateRepo(ctx, gitClient, owner, upstream, cr)
+		if err != nil {
+			log.Error(err, "cannot migrate repo")
+			// Here we don't provide the full error since the message change every time and this will re-trigger
+			// a new reconcile loop
+			cr.SetConditions(gitclient.Failed("cannot migrate repo", err))
+			return err
+		}
+		log.Info("repo migrated", "name", cr.GetName(), "owner", owner)
+		resource.AddAnnotations(cr, map[string]string{ExternalNameAnnotationName: getExternalName(owner, cr)})
+		cr.Status.URL = &repo.CloneURL
+		return r.syncRepo(ctx, gitClient, u, owner, cr)
+	}
 	if err != nil {
 		// create repo
-		createRepo := gitea.CreateRepoOption{Name: cr.GetName()}
+		createRepo := gitprovider.CreateRepoOptions{Name: cr.GetName()}
+		if owner != u.UserName {
+			createRepo.Owner = owner
+		}
 		if cr.Spec.Description != nil {
 			createRepo.Description = *cr.Spec.Description
 		}
@@ -179,24 +268,30 @@ func (r *reconciler) upsertRepo(ctx context.Context, giteaClient giteaclient.Git
 			createRepo.DefaultBranch = *cr.Spec.DefaultBranch
 		}
 		if cr.Spec.TrustModel != nil {
-			createRepo.TrustModel = gitea.TrustModel(*cr.Spec.TrustModel)
+			createRepo.TrustModel = string(*cr.Spec.TrustModel)
 		}
 		createRepo.AutoInit = true
 		log.Info("repository", "config", createRepo)
 
-		repo, _, err := giteaClient.CreateRepo(createRepo)
+		repo, err := gitClient.CreateRepo(createRepo)
 		if err != nil {
 			log.Error(err, "cannot create repo")
 			// Here we don't provide the full error since the message change every time and this will re-trigger
 			// a new reconcile loop
-			cr.SetConditions(infrav1alpha1.Failed("cannot create repo"))
+			cr.SetConditions(gitclient.Failed("cannot create repo", err))
 			return err
 		}
-		log.Info("repo created", "name", cr.GetName())
+		log.Info("repo created", "name", cr.GetName(), "owner", owner)
+		resource.AddAnnotations(cr, map[string]string{ExternalNameAnnotationName: getExternalName(owner, cr)})
 		cr.Status.URL = &repo.CloneURL
-		return nil
+		return r.syncRepo(ctx, gitClient, u, owner, cr)
 	}
-	editRepo := gitea.EditRepoOption{Name: pointer.String(cr.GetName())}
+	if err := adoptRepo(ctx, owner, cr); err != nil {
+		log.Error(err, "cannot adopt repo")
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return err
+	}
+	editRepo := gitprovider.EditRepoOptions{}
 	if cr.Spec.Description != nil {
 		editRepo.Description = cr.Spec.Description
 	} else {
@@ -207,35 +302,148 @@ func (r *reconciler) upsertRepo(ctx context.Context, giteaClient giteaclient.Git
 	} else {
 		editRepo.Private = nil
 	}
-	repo, _, err := giteaClient.EditRepo(u.UserName, cr.GetName(), editRepo)
+	editRepo.DefaultBranch = cr.Spec.DefaultBranch
+	// only a mirror has an interval, the upstream of an existing repository
+	// that is not a mirror is not used
+	if upstream != nil && upstream.mirrorInterval != "" && existing != nil && existing.Mirror {
+		editRepo.MirrorInterval = &upstream.mirrorInterval
+	}
+	repo, err := gitClient.EditRepo(owner, cr.GetName(), editRepo)
 	if err != nil {
 		log.Error(err, "cannot update repo")
 		// Here we don't provide the full error since the message change every time and this will re-trigger
 		// a new reconcile loop
-		cr.SetConditions(infrav1alpha1.Failed("cannot update repo"))
+		cr.SetConditions(gitclient.Failed("cannot update repo", err))
 		return err
 	}
-	log.Info("repo updated", "name", cr.GetName())
+	log.Info("repo updated", "name", cr.GetName(), "owner", owner)
 	cr.Status.URL = &repo.CloneURL
 
-	return nil
+	return r.syncRepo(ctx, gitClient, u, owner, cr)
+}
+
+// syncRepo syncs the access of the team, the settings of the repository and
+// its registration in porch
+func (r *reconciler) syncRepo(ctx context.Context, gitClient gitprovider.GitProvider, u *gitprovider.User, owner string, cr *infrav1alpha1.Repository) error {
+	if err := r.addTeamRepo(ctx, gitClient, owner, cr); err != nil {
+		return err
+	}
+	if err := r.syncSettings(ctx, gitClient, u, owner, cr); err != nil {
+		return err
+	}
+	return r.syncPorchRepo(ctx, cr)
 }
 
-func (r *reconciler) deleteRepo(ctx context.Context, giteaClient giteaclient.GiteaClient, cr *infrav1alpha1.Repository) error {
+// ensureOwner returns the owner of the repository. When the repository is
+// owned by an organization, the organization is created if it does not exist
+// and the repository allows it.
+func (r *reconciler) ensureOwner(ctx context.Context, gitClient gitprovider.GitProvider, u *gitprovider.User, cr *infrav1alpha1.Repository) (string, error) {
 	log := log.FromContext(ctx)
-	u, _, err := giteaClient.GetMyUserInfo()
+	owner := getOwner(u, cr)
+	if owner == u.UserName {
+		if cr.GetAnnotations()[TeamAnnotationName] != "" {
+			err := fmt.Errorf("a team requires the repository to be owned by an organization")
+			cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+			return "", err
+		}
+		return owner, nil
+	}
+	if _, err := gitClient.GetOrg(owner); err != nil {
+		if !gitprovider.IsNotFound(err) {
+			log.Error(err, "cannot get organization", "owner", owner)
+			cr.SetConditions(gitclient.Failed("cannot get organization", err))
+			return "", err
+		}
+		if cr.GetAnnotations()[CreateOwnerAnnotationName] != "true" {
+			log.Error(err, "cannot get organization", "owner", owner)
+			cr.SetConditions(gitclient.Failed(fmt.Sprintf("organization %s not found", owner), err))
+			return "", err
+		}
+		if _, err := gitClient.CreateOrg(gitprovider.CreateOrgOptions{Name: owner}); err != nil {
+			log.Error(err, "cannot create organization", "owner", owner)
+			cr.SetConditions(gitclient.Failed("cannot create organization", err))
+			return "", err
+		}
+		log.Info("organization created", "owner", owner)
+	}
+	return owner, nil
+}
+
+// addTeamRepo grants the team selected by the repository access to it
+func (r *reconciler) addTeamRepo(ctx context.Context, gitClient gitprovider.GitProvider, owner string, cr *infrav1alpha1.Repository) error {
+	log := log.FromContext(ctx)
+	team := cr.GetAnnotations()[TeamAnnotationName]
+	if team == "" {
+		return nil
+	}
+	permission, err := getTeamPermission(cr)
 	if err != nil {
-		log.Error(err, "cannot get user info")
 		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
 		return err
 	}
+	if err := gitClient.AddTeamRepo(gitprovider.AddTeamRepoOptions{
+		Org:        owner,
+		Team:       team,
+		Repo:       cr.GetName(),
+		Permission: permission,
+	}); err != nil {
+		log.Error(err, "cannot add team to repo", "team", team)
+		cr.SetConditions(gitclient.Failed("cannot add team to repo", err))
+		return err
+	}
+	return nil
+}
 
-	_, err = giteaClient.DeleteRepo(u.UserName, cr.GetName())
-	if err != nil {
+// getOwner returns the organization selected by the repository or the user
+// when no organization is selected
+func getOwner(u *gitprovider.User, cr *infrav1alpha1.Repository) string {
+	if owner := cr.GetAnnotations()[OwnerAnnotationName]; owner != "" {
+		return owner
+	}
+	return u.UserName
+}
+
+func getTeamPermission(cr *infrav1alpha1.Repository) (gitprovider.Permission, error) {
+	switch permission := gitprovider.Permission(cr.GetAnnotations()[TeamPermissionAnnotationName]); permission {
+	case "":
+		return gitprovider.PermissionWrite, nil
+	case gitprovider.PermissionRead, gitprovider.PermissionWrite, gitprovider.PermissionAdmin:
+		return permission, nil
+	default:
+		return "", fmt.Errorf("unsupported team permission %s", permission)
+	}
+}
+
+// deleteRepo deletes the git repository managed by the repository. A git
+// repository that was not created or adopted by the repository is kept, and
+// a git repository that no longer exists is deleted already.
+func (r *reconciler) deleteRepo(ctx context.Context, gitClient gitprovider.GitProvider, cr *infrav1alpha1.Repository) error {
+	log := log.FromContext(ctx)
+	externalName, ok := cr.GetAnnotations()[ExternalNameAnnotationName]
+	if !ok {
+		u, err := gitClient.GetMyUserInfo()
+		if err != nil {
+			log.Error(err, "cannot get user info")
+			cr.SetConditions(gitclient.Failed(err.Error(), err))
+			return err
+		}
+		externalName = getExternalName(getOwner(u, cr), cr)
+	}
+	if !isManaged(externalName, cr) {
+		log.Info("repo not managed, not deleted", "name", externalName)
+		return nil
+	}
+
+	owner, name, _ := strings.Cut(externalName, "/")
+	if err := gitClient.DeleteRepo(owner, name); err != nil {
+		if gitprovider.IsNotFound(err) {
+			log.Info("repo already deleted", "name", name, "owner", owner)
+			return nil
+		}
 		log.Error(err, "cannot delete repo")
-		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		cr.SetConditions(gitclient.Failed("cannot delete repo", err))
 		return err
 	}
-	log.Info("repo deleted", "name", cr.GetName())
+	log.Info("repo deleted", "name", name, "owner", owner)
 	return nil
 }
@@ -17,29 +17,32 @@ package repository
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"testing"
 
 	"github.com/go-logr/logr"
-	"github.com/nephio-project/nephio/controllers/pkg/giteaclient"
+	"github.com/google/go-cmp/cmp"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
 	"github.com/nephio-project/nephio/controllers/pkg/resource"
 	"github.com/nephio-project/nephio/testing/mockeryutils"
+	"github.com/stretchr/testify/mock"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
-	"code.gitea.io/sdk/gitea"
 	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
 )
 
 type fields struct {
 	APIPatchingApplicator resource.APIPatchingApplicator
-	giteaClient           giteaclient.GiteaClient
+	gitClient             gitprovider.GitProvider
 	finalizer             *resource.APIFinalizer
 	l                     logr.Logger
 }
 type args struct {
-	ctx         context.Context
-	giteaClient giteaclient.GiteaClient
-	cr          *infrav1alpha1.Repository
+	ctx       context.Context
+	gitClient gitprovider.GitProvider
+	cr        *infrav1alpha1.Repository
 }
 type repoTest struct {
 	name    string
@@ -49,7 +52,6 @@ type repoTest struct {
 	wantErr bool
 }
 
-
 func TestUpsertRepo(t *testing.T) {
 	dummyString := "Dummy String"
 	dummyBool := true
@@ -61,18 +63,18 @@ func TestUpsertRepo(t *testing.T) {
 			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil, log.FromContext(context.Background())},
 			args:   args{nil, nil, &infrav1alpha1.Repository{}},
 			mocks: []mockeryutils.MockHelper{
-				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{nil, nil, fmt.Errorf("error getting User Information")}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{nil, fmt.Errorf("error getting User Information")}},
 			},
 			wantErr: true,
 		},
 		{
 			name:   "Repo exists, cr spec fields blank",
 			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil, log.FromContext(context.Background())},
-			args:   args{nil, nil, &infrav1alpha1.Repository{Status: infrav1alpha1.RepositoryStatus{}}},
+			args:   args{nil, nil, &infrav1alpha1.Repository{ObjectMeta: v1.ObjectMeta{Annotations: map[string]string{ExternalNameAnnotationName: "gitea/"}}, Status: infrav1alpha1.RepositoryStatus{}}},
 			mocks: []mockeryutils.MockHelper{
-				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitea.User{UserName: "gitea"}, nil, nil}},
-				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitea.Repository{}, nil, nil}},
-				{MethodName: "EditRepo", ArgType: []string{"string", "string", "gitea.EditRepoOption"}, RetArgList: []interface{}{&gitea.Repository{}, nil, nil}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitprovider.Repository{}, nil}},
+				{MethodName: "EditRepo", ArgType: []string{"string", "string", "gitprovider.EditRepoOptions"}, RetArgList: []interface{}{&gitprovider.Repository{}, nil}},
 			},
 			wantErr: false,
 		},
@@ -83,6 +85,7 @@ func TestUpsertRepo(t *testing.T) {
 				nil,
 				nil,
 				&infrav1alpha1.Repository{
+					ObjectMeta: v1.ObjectMeta{Annotations: map[string]string{ExternalNameAnnotationName: "gitea/"}},
 					Spec: infrav1alpha1.RepositorySpec{
 						Description: &dummyString,
 						Private:     &dummyBool,
@@ -91,9 +94,9 @@ func TestUpsertRepo(t *testing.T) {
 				},
 			},
 			mocks: []mockeryutils.MockHelper{
-				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitea.User{UserName: "gitea"}, nil, nil}},
-				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitea.Repository{}, nil, nil}},
-				{MethodName: "EditRepo", ArgType: []string{"string", "string", "gitea.EditRepoOption"}, RetArgList: []interface{}{&gitea.Repository{}, nil, nil}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitprovider.Repository{}, nil}},
+				{MethodName: "EditRepo", ArgType: []string{"string", "string", "gitprovider.EditRepoOptions"}, RetArgList: []interface{}{&gitprovider.Repository{}, nil}},
 			},
 			wantErr: false,
 		},
@@ -103,13 +106,67 @@ func TestUpsertRepo(t *testing.T) {
 			args: args{
 				nil,
 				nil,
-				&infrav1alpha1.Repository{},
+				&infrav1alpha1.Repository{ObjectMeta: v1.ObjectMeta{Annotations: map[string]string{ExternalNameAnnotationName: "gitea/"}}},
 			},
 			mocks: []mockeryutils.MockHelper{
-				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitea.User{UserName: "gitea"}, nil, nil}},
-				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitea.Repository{}, nil, nil}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitprovider.Repository{}, nil}},
 				{MethodName: "EditRepo", ArgType: []string{"string", "string",
-					"gitea.EditRepoOption"}, RetArgList: []interface{}{&gitea.Repository{}, nil, fmt.Errorf("error updating repo")}},
+					"gitprovider.EditRepoOptions"}, RetArgList: []interface{}{&gitprovider.Repository{}, fmt.Errorf("error updating repo")}},
+			},
+			wantErr: true,
+		},
+		{
+			name:   "Repo exists, reconciled before the external name was recorded",
+			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil, log.FromContext(context.Background())},
+			args:   args{nil, nil, &infrav1alpha1.Repository{Status: infrav1alpha1.RepositoryStatus{URL: &dummyString}}},
+			mocks: []mockeryutils.MockHelper{
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitprovider.Repository{}, nil}},
+				{MethodName: "EditRepo", ArgType: []string{"string", "string", "gitprovider.EditRepoOptions"}, RetArgList: []interface{}{&gitprovider.Repository{}, nil}},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "Repo exists, not managed",
+			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil, log.FromContext(context.Background())},
+			args:   args{nil, nil, &infrav1alpha1.Repository{}},
+			mocks: []mockeryutils.MockHelper{
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitprovider.Repository{}, nil}},
+			},
+			wantErr: true,
+		},
+		{
+			name:   "Repo exists, managed by another repository",
+			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil, log.FromContext(context.Background())},
+			args:   args{nil, nil, &infrav1alpha1.Repository{ObjectMeta: v1.ObjectMeta{Annotations: map[string]string{ExternalNameAnnotationName: "edge/"}}}},
+			mocks: []mockeryutils.MockHelper{
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitprovider.Repository{}, nil}},
+			},
+			wantErr: true,
+		},
+		{
+			name:   "Repo exists, adopted",
+			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil, log.FromContext(context.Background())},
+			args:   args{nil, nil, &infrav1alpha1.Repository{ObjectMeta: v1.ObjectMeta{Annotations: map[string]string{AdoptAnnotationName: "true"}}}},
+			mocks: []mockeryutils.MockHelper{
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitprovider.Repository{}, nil}},
+				{MethodName: "EditRepo", ArgType: []string{"string", "string", "gitprovider.EditRepoOptions"}, RetArgList: []interface{}{&gitprovider.Repository{}, nil}},
+			},
+			wantErr: false,
+		},
+		{
+			// only a repository that does not exist is created, CreateRepo is
+			// not mocked so a call fails the test
+			name:   "Get repo unavailable",
+			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil, log.FromContext(context.Background())},
+			args:   args{nil, nil, &infrav1alpha1.Repository{}},
+			mocks: []mockeryutils.MockHelper{
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{nil, gitprovider.NewError(http.StatusBadGateway, fmt.Errorf("502 Bad Gateway"))}},
 			},
 			wantErr: true,
 		},
@@ -133,9 +190,9 @@ func TestUpsertRepo(t *testing.T) {
 				},
 			},
 			mocks: []mockeryutils.MockHelper{
-				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitea.User{UserName: "gitea"}, nil, nil}},
-				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitea.Repository{}, nil, fmt.Errorf("repo does not exist")}},
-				{MethodName: "CreateRepo", ArgType: []string{"gitea.CreateRepoOption"}, RetArgList: []interface{}{&gitea.Repository{}, nil, nil}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitprovider.Repository{}, gitprovider.NewError(http.StatusNotFound, fmt.Errorf("repo does not exist"))}},
+				{MethodName: "CreateRepo", ArgType: []string{"gitprovider.CreateRepoOptions"}, RetArgList: []interface{}{&gitprovider.Repository{}, nil}},
 			},
 			wantErr: false,
 		},
@@ -148,9 +205,9 @@ func TestUpsertRepo(t *testing.T) {
 				&infrav1alpha1.Repository{},
 			},
 			mocks: []mockeryutils.MockHelper{
-				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitea.User{UserName: "gitea"}, nil, nil}},
-				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitea.Repository{}, nil, fmt.Errorf("repo does not exist")}},
-				{MethodName: "CreateRepo", ArgType: []string{"gitea.CreateRepoOption"}, RetArgList: []interface{}{&gitea.Repository{}, nil, nil}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitprovider.Repository{}, gitprovider.NewError(http.StatusNotFound, fmt.Errorf("repo does not exist"))}},
+				{MethodName: "CreateRepo", ArgType: []string{"gitprovider.CreateRepoOptions"}, RetArgList: []interface{}{&gitprovider.Repository{}, nil}},
 			},
 			wantErr: false,
 		},
@@ -163,9 +220,203 @@ func TestUpsertRepo(t *testing.T) {
 				&infrav1alpha1.Repository{},
 			},
 			mocks: []mockeryutils.MockHelper{
-				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitea.User{UserName: "gitea"}, nil, nil}},
-				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitea.Repository{}, nil, fmt.Errorf("repo does not exist")}},
-				{MethodName: "CreateRepo", ArgType: []string{"gitea.CreateRepoOption"}, RetArgList: []interface{}{&gitea.Repository{}, nil, fmt.Errorf("repo creation fails")}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitprovider.Repository{}, gitprovider.NewError(http.StatusNotFound, fmt.Errorf("repo does not exist"))}},
+				{MethodName: "CreateRepo", ArgType: []string{"gitprovider.CreateRepoOptions"}, RetArgList: []interface{}{&gitprovider.Repository{}, fmt.Errorf("repo creation fails")}},
+			},
+			wantErr: true,
+		},
+		{
+			name:   "Org repo: org does not exist",
+			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil, log.FromContext(context.Background())},
+			args: args{
+				nil,
+				nil,
+				&infrav1alpha1.Repository{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{OwnerAnnotationName: "edge"},
+					},
+				},
+			},
+			mocks: []mockeryutils.MockHelper{
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "GetOrg", ArgType: []string{"string"}, RetArgList: []interface{}{nil, gitprovider.NewError(http.StatusNotFound, fmt.Errorf("org does not exist"))}},
+			},
+			wantErr: true,
+		},
+		{
+			name:   "Org repo: org unavailable",
+			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil, log.FromContext(context.Background())},
+			args: args{
+				nil,
+				nil,
+				&infrav1alpha1.Repository{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{
+							OwnerAnnotationName:       "edge",
+							CreateOwnerAnnotationName: "true",
+						},
+					},
+				},
+			},
+			mocks: []mockeryutils.MockHelper{
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "GetOrg", ArgType: []string{"string"}, RetArgList: []interface{}{nil, gitprovider.NewError(http.StatusForbidden, fmt.Errorf("forbidden"))}},
+			},
+			wantErr: true,
+		},
+		{
+			name:   "Org repo: org and team created",
+			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil, log.FromContext(context.Background())},
+			args: args{
+				nil,
+				nil,
+				&infrav1alpha1.Repository{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{
+							OwnerAnnotationName:          "edge",
+							CreateOwnerAnnotationName:    "true",
+							TeamAnnotationName:           "ops",
+							TeamPermissionAnnotationName: "admin",
+						},
+					},
+				},
+			},
+			mocks: []mockeryutils.MockHelper{
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "GetOrg", ArgType: []string{"string"}, RetArgList: []interface{}{nil, gitprovider.NewError(http.StatusNotFound, fmt.Errorf("org does not exist"))}},
+				{MethodName: "CreateOrg", ArgType: []string{"gitprovider.CreateOrgOptions"}, RetArgList: []interface{}{&gitprovider.Organization{Name: "edge"}, nil}},
+				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{nil, gitprovider.NewError(http.StatusNotFound, fmt.Errorf("repo does not exist"))}},
+				{MethodName: "CreateRepo", ArgType: []string{"gitprovider.CreateRepoOptions"}, RetArgList: []interface{}{&gitprovider.Repository{Owner: "edge"}, nil}},
+				{MethodName: "AddTeamRepo", ArgType: []string{"gitprovider.AddTeamRepoOptions"}, RetArgList: []interface{}{nil}},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "Org repo: invalid team permission",
+			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil, log.FromContext(context.Background())},
+			args: args{
+				nil,
+				nil,
+				&infrav1alpha1.Repository{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{
+							OwnerAnnotationName:          "edge",
+							TeamAnnotationName:           "ops",
+							TeamPermissionAnnotationName: "owner",
+							ExternalNameAnnotationName:   "edge/",
+						},
+					},
+				},
+			},
+			mocks: []mockeryutils.MockHelper{
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "GetOrg", ArgType: []string{"string"}, RetArgList: []interface{}{&gitprovider.Organization{Name: "edge"}, nil}},
+				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitprovider.Repository{}, nil}},
+				{MethodName: "EditRepo", ArgType: []string{"string", "string", "gitprovider.EditRepoOptions"}, RetArgList: []interface{}{&gitprovider.Repository{}, nil}},
+			},
+			wantErr: true,
+		},
+		{
+			name:   "Upstream repo: migrated",
+			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil, log.FromContext(context.Background())},
+			args: args{
+				nil,
+				nil,
+				&infrav1alpha1.Repository{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{
+							UpstreamURLAnnotationName:    "https://github.com/nephio-project/catalog.git",
+							UpstreamModeAnnotationName:   "mirror",
+							MirrorIntervalAnnotationName: "8h",
+						},
+					},
+				},
+			},
+			mocks: []mockeryutils.MockHelper{
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{nil, gitprovider.NewError(http.StatusNotFound, fmt.Errorf("repo does not exist"))}},
+				{MethodName: "MigrateRepo", ArgType: []string{"gitprovider.MigrateRepoOptions"}, RetArgList: []interface{}{&gitprovider.Repository{Mirror: true}, nil}},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "Upstream repo: migration fails",
+			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil, log.FromContext(context.Background())},
+			args: args{
+				nil,
+				nil,
+				&infrav1alpha1.Repository{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{UpstreamURLAnnotationName: "https://github.com/nephio-project/catalog.git"},
+					},
+				},
+			},
+			mocks: []mockeryutils.MockHelper{
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{nil, gitprovider.NewError(http.StatusNotFound, fmt.Errorf("repo does not exist"))}},
+				{MethodName: "MigrateRepo", ArgType: []string{"gitprovider.MigrateRepoOptions"}, RetArgList: []interface{}{nil, fmt.Errorf("upstream not reachable")}},
+			},
+			wantErr: true,
+		},
+		{
+			name:   "Upstream repo: mirror interval updated",
+			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil, log.FromContext(context.Background())},
+			args: args{
+				nil,
+				nil,
+				&infrav1alpha1.Repository{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{
+							UpstreamURLAnnotationName:    "https://github.com/nephio-project/catalog.git",
+							UpstreamModeAnnotationName:   "mirror",
+							MirrorIntervalAnnotationName: "30m",
+							ExternalNameAnnotationName:   "gitea/",
+						},
+					},
+				},
+			},
+			mocks: []mockeryutils.MockHelper{
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "GetRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitprovider.Repository{Mirror: true}, nil}},
+				{MethodName: "EditRepo", ArgType: []string{"string", "string", "gitprovider.EditRepoOptions"}, RetArgList: []interface{}{&gitprovider.Repository{Mirror: true, MirrorInterval: "30m0s"}, nil}},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "Upstream repo: mirror interval requires a mirror",
+			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil, log.FromContext(context.Background())},
+			args: args{
+				nil,
+				nil,
+				&infrav1alpha1.Repository{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{
+							UpstreamURLAnnotationName:    "https://github.com/nephio-project/catalog.git",
+							MirrorIntervalAnnotationName: "30m",
+						},
+					},
+				},
+			},
+			mocks: []mockeryutils.MockHelper{
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+			},
+			wantErr: true,
+		},
+		{
+			name:   "User repo: team requires an org",
+			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil, log.FromContext(context.Background())},
+			args: args{
+				nil,
+				nil,
+				&infrav1alpha1.Repository{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{TeamAnnotationName: "ops"},
+					},
+				},
+			},
+			mocks: []mockeryutils.MockHelper{
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
 			},
 			wantErr: true,
 		}}
@@ -173,20 +424,60 @@ func TestUpsertRepo(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			r := &reconciler{
 				APIPatchingApplicator: tt.fields.APIPatchingApplicator,
-				giteaClient:           tt.fields.giteaClient,
+				gitClient:             tt.fields.gitClient,
 				finalizer:             tt.fields.finalizer,
 			}
 
 			initMockeryMocks(&tt)
 
-			if err := r.upsertRepo(tt.args.ctx, tt.args.giteaClient, tt.args.cr); (err != nil) != tt.wantErr {
+			if err := r.upsertRepo(tt.args.ctx, tt.args.gitClient, tt.args.cr); (err != nil) != tt.wantErr {
 				t.Errorf("upsertRepo() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
 
+func TestUpsertRepoMirrorInterval(t *testing.T) {
+	cases := map[string]struct {
+		mirror bool
+		want   *string
+	}{
+		"Mirror":    {mirror: true, want: pointer.String("30m0s")},
+		"NotMirror": {mirror: false},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &infrav1alpha1.Repository{
+				ObjectMeta: v1.ObjectMeta{
+					Annotations: map[string]string{
+						UpstreamURLAnnotationName:    "https://github.com/nephio-project/catalog.git",
+						UpstreamModeAnnotationName:   "mirror",
+						MirrorIntervalAnnotationName: "30m",
+						ExternalNameAnnotationName:   "gitea/",
+					},
+				},
+			}
+			gitClient := new(gitprovider.MockGitProvider)
+			gitClient.On("GetMyUserInfo").Return(&gitprovider.User{UserName: "gitea"}, nil)
+			gitClient.On("GetRepo", mock.Anything, mock.Anything).Return(&gitprovider.Repository{Mirror: tc.mirror}, nil)
+			var got *string
+			gitClient.On("EditRepo", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				got = args.Get(2).(gitprovider.EditRepoOptions).MirrorInterval
+			}).Return(&gitprovider.Repository{}, nil)
+
+			r := &reconciler{APIPatchingApplicator: resource.NewAPIPatchingApplicator(nil), gitClient: gitClient}
+			if err := r.upsertRepo(context.Background(), gitClient, cr); err != nil {
+				t.Fatalf("upsertRepo() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("mirror interval -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestDeleteRepo(t *testing.T) {
+	url := "http://gitea/gitea/repo-name.git"
 	tests := []repoTest{
 		{
 			name:   "User Info and Delete Repo both OK",
@@ -198,11 +489,12 @@ func TestDeleteRepo(t *testing.T) {
 					ObjectMeta: v1.ObjectMeta{
 						Name: "repo-name",
 					},
+					Status: infrav1alpha1.RepositoryStatus{URL: &url},
 				},
 			},
 			mocks: []mockeryutils.MockHelper{
-				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitea.User{UserName: "gitea"}, nil, nil}},
-				{MethodName: "DeleteRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitea.Response{}, nil, nil}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+				{MethodName: "DeleteRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{nil}},
 			},
 			wantErr: false,
 		}, {
@@ -215,10 +507,11 @@ func TestDeleteRepo(t *testing.T) {
 					ObjectMeta: v1.ObjectMeta{
 						Name: "repo-name",
 					},
+					Status: infrav1alpha1.RepositoryStatus{URL: &url},
 				},
 			},
 			mocks: []mockeryutils.MockHelper{
-				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitea.User{UserName: "gitea"}, nil, fmt.Errorf("Error getting User Information")}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, fmt.Errorf("Error getting User Information")}},
 			},
 			wantErr: true,
 		}, {
@@ -229,36 +522,107 @@ func TestDeleteRepo(t *testing.T) {
 				nil,
 				&infrav1alpha1.Repository{
 					ObjectMeta: v1.ObjectMeta{
-						Name: "repo-name",
+						Name:        "repo-name",
+						Annotations: map[string]string{ExternalNameAnnotationName: "org/repo-name"},
 					},
 				},
 			},
 			mocks: []mockeryutils.MockHelper{
-				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitea.User{UserName: "gitea"}, nil, nil}},
-				{MethodName: "DeleteRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{&gitea.Response{}, fmt.Errorf("Error deleting repo")}},
+				{MethodName: "DeleteRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{fmt.Errorf("Error deleting repo")}},
 			},
 			wantErr: true,
+		}, {
+			name:   "Repo already deleted",
+			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil, log.FromContext(context.Background())},
+			args: args{
+				nil,
+				nil,
+				&infrav1alpha1.Repository{
+					ObjectMeta: v1.ObjectMeta{
+						Name:        "repo-name",
+						Annotations: map[string]string{ExternalNameAnnotationName: "org/repo-name"},
+					},
+				},
+			},
+			mocks: []mockeryutils.MockHelper{
+				{MethodName: "DeleteRepo", ArgType: []string{"string", "string"}, RetArgList: []interface{}{gitprovider.NewError(http.StatusNotFound, fmt.Errorf("not found"))}},
+			},
+			wantErr: false,
+		}, {
+			// the repository never created nor adopted the git repository,
+			// DeleteRepo is not mocked so a call fails the test
+			name:   "Repo not managed",
+			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil, log.FromContext(context.Background())},
+			args: args{
+				nil,
+				nil,
+				&infrav1alpha1.Repository{
+					ObjectMeta: v1.ObjectMeta{
+						Name: "repo-name",
+					},
+				},
+			},
+			mocks: []mockeryutils.MockHelper{
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
+			},
+			wantErr: false,
 		}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			r := &reconciler{
 				APIPatchingApplicator: tt.fields.APIPatchingApplicator,
-				giteaClient:           tt.fields.giteaClient,
+				gitClient:             tt.fields.gitClient,
 				finalizer:             tt.fields.finalizer,
 			}
 
 			initMockeryMocks(&tt)
 
-			if err := r.deleteRepo(tt.args.ctx, tt.args.giteaClient, tt.args.cr); (err != nil) != tt.wantErr {
+			if err := r.deleteRepo(tt.args.ctx, tt.args.gitClient, tt.args.cr); (err != nil) != tt.wantErr {
 				t.Errorf("deleteRepo() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
 
+func TestUpsertRepoFailedReason(t *testing.T) {
+	cases := map[string]struct {
+		err  error
+		want string
+	}{
+		"Unauthorized": {
+			err:  gitprovider.NewError(http.StatusUnauthorized, fmt.Errorf("401 Unauthorized")),
+			want: string(gitprovider.ErrorClassUnauthorized),
+		},
+		"RateLimited": {
+			err:  gitprovider.NewError(http.StatusTooManyRequests, fmt.Errorf("429 Too Many Requests")),
+			want: string(gitprovider.ErrorClassRateLimited),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			g := gitprovider.NewMockGitProvider(t)
+			g.EXPECT().GetMyUserInfo().Return(&gitprovider.User{UserName: "gitea"}, nil)
+			g.EXPECT().GetRepo("gitea", "edge01").Return(nil, gitprovider.NewError(http.StatusNotFound, fmt.Errorf("404 Not Found")))
+			g.EXPECT().CreateRepo(gitprovider.CreateRepoOptions{Name: "edge01", AutoInit: true}).Return(nil, tc.err)
+			cr := &infrav1alpha1.Repository{ObjectMeta: v1.ObjectMeta{Name: "edge01"}}
+			r := &reconciler{}
+			if err := r.upsertRepo(context.Background(), g, cr); err == nil {
+				t.Fatalf("upsertRepo() expected error")
+			}
+			got := cr.GetCondition(infrav1alpha1.ConditionTypeReady)
+			if diff := cmp.Diff(tc.want, got.Reason); diff != "" {
+				t.Errorf("ready condition reason -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff("cannot create repo", got.Message); diff != "" {
+				t.Errorf("ready condition message -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
 func initMockeryMocks(tt *repoTest) {
-	mockGClient := new(giteaclient.MockGiteaClient)
-	tt.args.giteaClient = mockGClient
-	tt.fields.giteaClient = mockGClient
+	mockGClient := new(gitprovider.MockGitProvider)
+	tt.args.gitClient = mockGClient
+	tt.fields.gitClient = mockGClient
 	mockeryutils.InitMocks(&mockGClient.Mock, tt.mocks)
 }
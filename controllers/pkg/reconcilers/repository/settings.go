@@ -0,0 +1,395 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/gitclient"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// SettingsAnnotationName holds the settings of the repository in yaml or
+	// json, see Settings
+	SettingsAnnotationName = "repository.nephio.org/settings"
+
+	// webhookSecretKey is the key of the webhook secret in the secret
+	// referenced by a webhook
+	webhookSecretKey = "secret"
+
+	// settingsResyncInterval is the interval at which a repository with
+	// settings is reconciled again, so changes made directly on the git server
+	// are reverted
+	settingsResyncInterval = 10 * time.Minute
+)
+
+// Condition types reporting the status of each setting of the repository
+const (
+	ConditionTypeBranchProtections infrav1alpha1.ConditionType = "BranchProtectionsSynced"
+	ConditionTypeWebhooks          infrav1alpha1.ConditionType = "WebhooksSynced"
+	ConditionTypeCollaborators     infrav1alpha1.ConditionType = "CollaboratorsSynced"
+	ConditionTypeDeployKeys        infrav1alpha1.ConditionType = "DeployKeysSynced"
+)
+
+// Settings are the declarative settings of the repository. A setting that
+// is not provided is not managed, a setting that is provided is the complete
+// list of that setting on the git server, so items that are not listed are
+// removed, e.g. `webhooks: []` removes all webhooks.
+type Settings struct {
+	BranchProtections []BranchProtection `json:"branchProtections,omitempty"`
+	Webhooks          []Webhook          `json:"webhooks,omitempty"`
+	Collaborators     []Collaborator     `json:"collaborators,omitempty"`
+	DeployKeys        []DeployKey        `json:"deployKeys,omitempty"`
+}
+
+// BranchProtection protects a branch of the repository
+type BranchProtection struct {
+	Branch string `json:"branch"`
+	// EnablePush allows pushing to the branch, when push allowlists are
+	// provided only the listed users and teams can push
+	EnablePush         bool     `json:"enablePush,omitempty"`
+	PushAllowlistUsers []string `json:"pushAllowlistUsers,omitempty"`
+	PushAllowlistTeams []string `json:"pushAllowlistTeams,omitempty"`
+	// RequiredApprovals is the number of approvals required to merge
+	RequiredApprovals int64 `json:"requiredApprovals,omitempty"`
+}
+
+// Webhook of the repository, identified by its url
+type Webhook struct {
+	URL string `json:"url"`
+	// ContentType is json (default) or form
+	ContentType string `json:"contentType,omitempty"`
+	// SecretName is the name of a secret in the namespace of the repository
+	// holding the secret of the webhook in the key secret
+	SecretName string   `json:"secretName,omitempty"`
+	Events     []string `json:"events,omitempty"`
+	// Active defaults to true
+	Active *bool `json:"active,omitempty"`
+}
+
+// Collaborator is a user with access to the repository
+type Collaborator struct {
+	UserName string `json:"userName"`
+	// Permission is read, write (default) or admin
+	Permission gitprovider.Permission `json:"permission,omitempty"`
+}
+
+// DeployKey is an ssh key with access to the repository, identified by its
+// title
+type DeployKey struct {
+	Title string `json:"title"`
+	Key   string `json:"key"`
+	// ReadOnly defaults to true
+	ReadOnly *bool `json:"readOnly,omitempty"`
+}
+
+// getSettings returns the settings of the repository, nil if the repository
+// has no settings
+func getSettings(cr *infrav1alpha1.Repository) (*Settings, error) {
+	s, ok := cr.GetAnnotations()[SettingsAnnotationName]
+	if !ok {
+		return nil, nil
+	}
+	settings := &Settings{}
+	if err := yaml.Unmarshal([]byte(s), settings); err != nil {
+		return nil, errors.Wrap(err, "cannot parse repository settings")
+	}
+	return settings, nil
+}
+
+// getResyncInterval returns the interval after which a reconciled repository
+// is reconciled again to correct the drift of its settings, 0 when the
+// repository has no settings
+func getResyncInterval(cr *infrav1alpha1.Repository) time.Duration {
+	if _, ok := cr.GetAnnotations()[SettingsAnnotationName]; !ok {
+		return 0
+	}
+	return settingsResyncInterval
+}
+
+// syncSettings corrects the drift of every provided setting of the
+// repository and reports the result of each setting in its own condition
+func (r *reconciler) syncSettings(ctx context.Context, gitClient gitprovider.GitProvider, u *gitprovider.User, owner string, cr *infrav1alpha1.Repository) error {
+	log := log.FromContext(ctx)
+	settings, err := getSettings(cr)
+	if err != nil {
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return err
+	}
+	if settings == nil {
+		return nil
+	}
+
+	var failed []string
+	var syncErr error
+	sync := func(t infrav1alpha1.ConditionType, provided bool, fn func() error) {
+		if !provided {
+			return
+		}
+		if err := fn(); err != nil {
+			log.Error(err, "cannot sync repository setting", "setting", t)
+			failed = append(failed, string(t))
+			if syncErr == nil {
+				syncErr = err
+			}
+			cr.SetConditions(settingFailed(t, err))
+			return
+		}
+		cr.SetConditions(settingSynced(t))
+	}
+	sync(ConditionTypeBranchProtections, settings.BranchProtections != nil, func() error {
+		return syncBranchProtections(gitClient, owner, cr.GetName(), settings.BranchProtections)
+	})
+	sync(ConditionTypeWebhooks, settings.Webhooks != nil, func() error {
+		return r.syncWebhooks(ctx, gitClient, owner, cr, settings.Webhooks)
+	})
+	sync(ConditionTypeCollaborators, settings.Collaborators != nil, func() error {
+		return syncCollaborators(gitClient, u, owner, cr.GetName(), settings.Collaborators)
+	})
+	sync(ConditionTypeDeployKeys, settings.DeployKeys != nil, func() error {
+		return syncDeployKeys(gitClient, owner, cr.GetName(), settings.DeployKeys)
+	})
+	if len(failed) > 0 {
+		// the repository is retried according to the first failed setting
+		msg := fmt.Sprintf("cannot sync repository settings: %s", strings.Join(failed, ", "))
+		cr.SetConditions(gitclient.Failed(msg, syncErr))
+		return errors.Wrap(syncErr, msg)
+	}
+	return nil
+}
+
+func syncBranchProtections(gitClient gitprovider.GitProvider, owner, repo string, desired []BranchProtection) error {
+	existing, err := gitClient.ListBranchProtections(owner, repo)
+	if err != nil {
+		return err
+	}
+	current := map[string]*gitprovider.BranchProtection{}
+	for _, bp := range existing {
+		current[bp.Branch] = bp
+	}
+	for _, d := range desired {
+		bp := gitprovider.BranchProtection{
+			Branch:             d.Branch,
+			EnablePush:         d.EnablePush,
+			PushAllowlistUsers: d.PushAllowlistUsers,
+			PushAllowlistTeams: d.PushAllowlistTeams,
+			RequiredApprovals:  d.RequiredApprovals,
+		}
+		c, ok := current[d.Branch]
+		delete(current, d.Branch)
+		switch {
+		case !ok:
+			if err := gitClient.CreateBranchProtection(owner, repo, bp); err != nil {
+				return err
+			}
+		case c.EnablePush != bp.EnablePush ||
+			c.RequiredApprovals != bp.RequiredApprovals ||
+			!sameStrings(c.PushAllowlistUsers, bp.PushAllowlistUsers) ||
+			!sameStrings(c.PushAllowlistTeams, bp.PushAllowlistTeams):
+			if err := gitClient.EditBranchProtection(owner, repo, bp); err != nil {
+				return err
+			}
+		}
+	}
+	for branch := range current {
+		if err := gitClient.DeleteBranchProtection(owner, repo, branch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncWebhooks corrects the drift of the webhooks, the secret of a webhook
+// cannot be read from the git server so it is only updated when another
+// field of the webhook changes
+func (r *reconciler) syncWebhooks(ctx context.Context, gitClient gitprovider.GitProvider, owner string, cr *infrav1alpha1.Repository, desired []Webhook) error {
+	existing, err := gitClient.ListWebhooks(owner, cr.GetName())
+	if err != nil {
+		return err
+	}
+	current := map[string]*gitprovider.Webhook{}
+	for _, hook := range existing {
+		current[hook.URL] = hook
+	}
+	for _, d := range desired {
+		hook := gitprovider.Webhook{
+			URL:         d.URL,
+			ContentType: d.ContentType,
+			Events:      d.Events,
+			Active:      d.Active == nil || *d.Active,
+		}
+		if hook.ContentType == "" {
+			hook.ContentType = "json"
+		}
+		c, ok := current[d.URL]
+		delete(current, d.URL)
+		if ok && c.ContentType == hook.ContentType && c.Active == hook.Active && sameStrings(c.Events, hook.Events) {
+			continue
+		}
+		if d.SecretName != "" {
+			secret := &corev1.Secret{}
+			if err := r.Get(ctx, types.NamespacedName{Namespace: cr.GetNamespace(), Name: d.SecretName}, secret); err != nil {
+				return errors.Wrapf(err, "cannot get secret of webhook %s", d.URL)
+			}
+			hook.Secret = string(secret.Data[webhookSecretKey])
+		}
+		if !ok {
+			if err := gitClient.CreateWebhook(owner, cr.GetName(), hook); err != nil {
+				return err
+			}
+			continue
+		}
+		hook.ID = c.ID
+		if err := gitClient.EditWebhook(owner, cr.GetName(), hook); err != nil {
+			return err
+		}
+	}
+	for _, hook := range current {
+		if err := gitClient.DeleteWebhook(owner, cr.GetName(), hook.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncCollaborators corrects the drift of the collaborators, the
+// authenticated user is never removed since it manages the repository
+func syncCollaborators(gitClient gitprovider.GitProvider, u *gitprovider.User, owner, repo string, desired []Collaborator) error {
+	existing, err := gitClient.ListCollaborators(owner, repo)
+	if err != nil {
+		return err
+	}
+	current := map[string]*gitprovider.Collaborator{}
+	for _, c := range existing {
+		if c.UserName != u.UserName {
+			current[c.UserName] = c
+		}
+	}
+	for _, d := range desired {
+		collaborator := gitprovider.Collaborator{UserName: d.UserName, Permission: d.Permission}
+		if collaborator.Permission == "" {
+			collaborator.Permission = gitprovider.PermissionWrite
+		}
+		c, ok := current[d.UserName]
+		delete(current, d.UserName)
+		if ok && c.Permission == collaborator.Permission {
+			continue
+		}
+		if err := gitClient.SetCollaborator(owner, repo, collaborator); err != nil {
+			return err
+		}
+	}
+	for userName := range current {
+		if err := gitClient.DeleteCollaborator(owner, repo, userName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncDeployKeys corrects the drift of the deploy keys, since deploy keys
+// cannot be updated a changed key is replaced
+func syncDeployKeys(gitClient gitprovider.GitProvider, owner, repo string, desired []DeployKey) error {
+	existing, err := gitClient.ListDeployKeys(owner, repo)
+	if err != nil {
+		return err
+	}
+	current := map[string]*gitprovider.DeployKey{}
+	for _, key := range existing {
+		current[key.Title] = key
+	}
+	for _, d := range desired {
+		key := gitprovider.DeployKey{Title: d.Title, Key: d.Key, ReadOnly: d.ReadOnly == nil || *d.ReadOnly}
+		c, ok := current[d.Title]
+		delete(current, d.Title)
+		if ok {
+			if c.ReadOnly == key.ReadOnly && sameKey(c.Key, key.Key) {
+				continue
+			}
+			if err := gitClient.DeleteDeployKey(owner, repo, c.ID); err != nil {
+				return err
+			}
+		}
+		if err := gitClient.CreateDeployKey(owner, repo, key); err != nil {
+			return err
+		}
+	}
+	for _, key := range current {
+		if err := gitClient.DeleteDeployKey(owner, repo, key.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sameStrings returns true if both lists hold the same strings in any order
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string{}, a...)
+	b = append([]string{}, b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// sameKey compares the type and the key material of two ssh keys, ignoring
+// the comment, which git servers do not always preserve
+func sameKey(a, b string) bool {
+	fa, fb := strings.Fields(a), strings.Fields(b)
+	if len(fa) < 2 || len(fb) < 2 {
+		return strings.TrimSpace(a) == strings.TrimSpace(b)
+	}
+	return fa[0] == fb[0] && fa[1] == fb[1]
+}
+
+func settingSynced(t infrav1alpha1.ConditionType) infrav1alpha1.Condition {
+	return infrav1alpha1.Condition{Condition: metav1.Condition{
+		Type:               string(t),
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             string(infrav1alpha1.ConditionReasonReady),
+	}}
+}
+
+func settingFailed(t infrav1alpha1.ConditionType, err error) infrav1alpha1.Condition {
+	return infrav1alpha1.Condition{Condition: metav1.Condition{
+		Type:               string(t),
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             string(gitclient.ConditionReason(err)),
+		Message:            err.Error(),
+	}}
+}
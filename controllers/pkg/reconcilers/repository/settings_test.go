@@ -0,0 +1,197 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetSettings(t *testing.T) {
+	cases := map[string]struct {
+		annotations map[string]string
+		want        *Settings
+		wantResync  time.Duration
+		wantErr     bool
+	}{
+		"NoSettings": {
+			want: nil,
+		},
+		"Settings": {
+			annotations: map[string]string{SettingsAnnotationName: `
+branchProtections:
+- branch: main
+  requiredApprovals: 1
+webhooks: []
+`},
+			want: &Settings{
+				BranchProtections: []BranchProtection{{Branch: "main", RequiredApprovals: 1}},
+				Webhooks:          []Webhook{},
+			},
+			wantResync: settingsResyncInterval,
+		},
+		"Invalid": {
+			annotations: map[string]string{SettingsAnnotationName: "branchProtections: a"},
+			wantResync:  settingsResyncInterval,
+			wantErr:     true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &infrav1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+			got, err := getSettings(cr)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("getSettings() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("getSettings() -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantResync, getResyncInterval(cr)); diff != "" {
+				t.Errorf("getResyncInterval() -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSyncBranchProtections(t *testing.T) {
+	g := gitprovider.NewMockGitProvider(t)
+	g.EXPECT().ListBranchProtections("edge", "edge01").Return([]*gitprovider.BranchProtection{
+		{Branch: "main", RequiredApprovals: 2},
+		{Branch: "stable", EnablePush: true, PushAllowlistUsers: []string{"b", "a"}},
+		{Branch: "old"},
+	}, nil)
+	g.EXPECT().EditBranchProtection("edge", "edge01", gitprovider.BranchProtection{Branch: "main", RequiredApprovals: 1}).Return(nil)
+	g.EXPECT().CreateBranchProtection("edge", "edge01", gitprovider.BranchProtection{Branch: "release"}).Return(nil)
+	g.EXPECT().DeleteBranchProtection("edge", "edge01", "old").Return(nil)
+
+	err := syncBranchProtections(g, "edge", "edge01", []BranchProtection{
+		{Branch: "main", RequiredApprovals: 1},
+		// the order of the allowlist does not matter
+		{Branch: "stable", EnablePush: true, PushAllowlistUsers: []string{"a", "b"}},
+		{Branch: "release"},
+	})
+	if err != nil {
+		t.Errorf("syncBranchProtections() unexpected error: %v", err)
+	}
+}
+
+func TestSyncWebhooks(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ci"},
+		Data:       map[string][]byte{"secret": []byte("s3cr3t")},
+	}
+	r := &reconciler{APIPatchingApplicator: resource.NewAPIPatchingApplicator(fake.NewClientBuilder().WithObjects(secret).Build())}
+	cr := &infrav1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "edge01"}}
+	inactive := false
+
+	g := gitprovider.NewMockGitProvider(t)
+	g.EXPECT().ListWebhooks("edge", "edge01").Return([]*gitprovider.Webhook{
+		{ID: 1, URL: "http://porch", ContentType: "json", Events: []string{"push"}, Active: true},
+		{ID: 2, URL: "http://ci", ContentType: "json", Events: []string{"push"}, Active: true},
+		{ID: 3, URL: "http://old", ContentType: "json", Active: true},
+	}, nil)
+	g.EXPECT().EditWebhook("edge", "edge01", gitprovider.Webhook{ID: 2, URL: "http://ci", ContentType: "json", Secret: "s3cr3t", Events: []string{"push"}, Active: false}).Return(nil)
+	g.EXPECT().CreateWebhook("edge", "edge01", gitprovider.Webhook{URL: "http://new", ContentType: "form", Events: []string{"create"}, Active: true}).Return(nil)
+	g.EXPECT().DeleteWebhook("edge", "edge01", int64(3)).Return(nil)
+
+	err := r.syncWebhooks(context.Background(), g, "edge", cr, []Webhook{
+		{URL: "http://porch", Events: []string{"push"}},
+		{URL: "http://ci", SecretName: "ci", Events: []string{"push"}, Active: &inactive},
+		{URL: "http://new", ContentType: "form", Events: []string{"create"}},
+	})
+	if err != nil {
+		t.Errorf("syncWebhooks() unexpected error: %v", err)
+	}
+}
+
+func TestSyncCollaborators(t *testing.T) {
+	g := gitprovider.NewMockGitProvider(t)
+	g.EXPECT().ListCollaborators("edge", "edge01").Return([]*gitprovider.Collaborator{
+		{UserName: "nephio", Permission: gitprovider.PermissionAdmin},
+		{UserName: "alice", Permission: gitprovider.PermissionRead},
+		{UserName: "bob", Permission: gitprovider.PermissionWrite},
+	}, nil)
+	g.EXPECT().SetCollaborator("edge", "edge01", gitprovider.Collaborator{UserName: "alice", Permission: gitprovider.PermissionWrite}).Return(nil)
+	g.EXPECT().DeleteCollaborator("edge", "edge01", "bob").Return(nil)
+
+	// the authenticated user is never removed
+	err := syncCollaborators(g, &gitprovider.User{UserName: "nephio"}, "edge", "edge01", []Collaborator{{UserName: "alice"}})
+	if err != nil {
+		t.Errorf("syncCollaborators() unexpected error: %v", err)
+	}
+}
+
+func TestSyncDeployKeys(t *testing.T) {
+	g := gitprovider.NewMockGitProvider(t)
+	g.EXPECT().ListDeployKeys("edge", "edge01").Return([]*gitprovider.DeployKey{
+		{ID: 1, Title: "flux", Key: "ssh-ed25519 AAAA", ReadOnly: true},
+		{ID: 2, Title: "ci", Key: "ssh-ed25519 BBBB", ReadOnly: true},
+		{ID: 3, Title: "old", Key: "ssh-ed25519 CCCC", ReadOnly: true},
+	}, nil)
+	g.EXPECT().DeleteDeployKey("edge", "edge01", int64(2)).Return(nil)
+	g.EXPECT().CreateDeployKey("edge", "edge01", gitprovider.DeployKey{Title: "ci", Key: "ssh-ed25519 DDDD", ReadOnly: true}).Return(nil)
+	g.EXPECT().DeleteDeployKey("edge", "edge01", int64(3)).Return(nil)
+
+	err := syncDeployKeys(g, "edge", "edge01", []DeployKey{
+		// the comment of the key is ignored
+		{Title: "flux", Key: "ssh-ed25519 AAAA flux@edge"},
+		{Title: "ci", Key: "ssh-ed25519 DDDD"},
+	})
+	if err != nil {
+		t.Errorf("syncDeployKeys() unexpected error: %v", err)
+	}
+}
+
+func TestSyncSettingsConditions(t *testing.T) {
+	cr := &infrav1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{
+		Name: "edge01",
+		Annotations: map[string]string{SettingsAnnotationName: `
+branchProtections: []
+collaborators: []
+`},
+	}}
+	g := gitprovider.NewMockGitProvider(t)
+	g.EXPECT().ListBranchProtections("edge", "edge01").Return(nil, nil)
+	g.EXPECT().ListCollaborators("edge", "edge01").Return(nil, fmt.Errorf("forbidden"))
+
+	r := &reconciler{}
+	if err := r.syncSettings(context.Background(), g, &gitprovider.User{UserName: "nephio"}, "edge", cr); err == nil {
+		t.Errorf("syncSettings() expected error")
+	}
+	got := map[string]metav1.ConditionStatus{}
+	for _, c := range cr.Status.Conditions {
+		got[c.Type] = c.Status
+	}
+	want := map[string]metav1.ConditionStatus{
+		string(ConditionTypeBranchProtections):   metav1.ConditionTrue,
+		string(ConditionTypeCollaborators):       metav1.ConditionFalse,
+		string(infrav1alpha1.ConditionTypeReady): metav1.ConditionFalse,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("conditions -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// UpstreamURLAnnotationName creates the repository from the upstream git
+	// repository at the url instead of creating an empty repository
+	UpstreamURLAnnotationName = "repository.nephio.org/upstream-url"
+	// UpstreamModeAnnotationName is import (default) to copy the upstream
+	// repository once or mirror to keep the repository in sync with it
+	UpstreamModeAnnotationName = "repository.nephio.org/upstream-mode"
+	// UpstreamSecretAnnotationName is the name of a secret in the namespace of
+	// the repository with the credentials of the upstream repository in the
+	// keys username and password or token
+	UpstreamSecretAnnotationName = "repository.nephio.org/upstream-secret"
+	// MirrorIntervalAnnotationName is the interval at which a mirror is
+	// synced with the upstream repository, e.g. 8h, the git server default is
+	// used when not provided
+	MirrorIntervalAnnotationName = "repository.nephio.org/mirror-interval"
+)
+
+// UpstreamMode defines how the repository follows the upstream repository
+type UpstreamMode string
+
+const (
+	UpstreamModeImport UpstreamMode = "import"
+	UpstreamModeMirror UpstreamMode = "mirror"
+)
+
+// upstream is the upstream repository the repository is created from
+type upstream struct {
+	url            string
+	mode           UpstreamMode
+	secretName     string
+	mirrorInterval string
+}
+
+// getUpstream returns the upstream of the repository, nil if the repository
+// has no upstream
+func getUpstream(cr *infrav1alpha1.Repository) (*upstream, error) {
+	annotations := cr.GetAnnotations()
+	url := annotations[UpstreamURLAnnotationName]
+	if url == "" {
+		return nil, nil
+	}
+	u := &upstream{
+		url:        url,
+		mode:       UpstreamMode(annotations[UpstreamModeAnnotationName]),
+		secretName: annotations[UpstreamSecretAnnotationName],
+	}
+	switch u.mode {
+	case "":
+		u.mode = UpstreamModeImport
+	case UpstreamModeImport, UpstreamModeMirror:
+	default:
+		return nil, fmt.Errorf("unsupported upstream mode %s", u.mode)
+	}
+	if interval := annotations[MirrorIntervalAnnotationName]; interval != "" {
+		if u.mode != UpstreamModeMirror {
+			return nil, fmt.Errorf("a mirror interval requires the upstream mode %s", UpstreamModeMirror)
+		}
+		d, err := time.ParseDuration(interval)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid mirror interval")
+		}
+		// git servers report the interval in the go duration format, so we
+		// normalize it to detect drift
+		u.mirrorInterval = d.String()
+	}
+	return u, nil
+}
+
+// migrateRepo creates the repository from its upstream repository
+func (r *reconciler) migrateRepo(ctx context.Context, gitClient gitprovider.GitProvider, owner string, u *upstream, cr *infrav1alpha1.Repository) (*gitprovider.Repository, error) {
+	migrateRepo := gitprovider.MigrateRepoOptions{
+		Owner:          owner,
+		Name:           cr.GetName(),
+		CloneURL:       u.url,
+		Mirror:         u.mode == UpstreamModeMirror,
+		MirrorInterval: u.mirrorInterval,
+	}
+	if cr.Spec.Description != nil {
+		migrateRepo.Description = *cr.Spec.Description
+	}
+	if cr.Spec.Private != nil {
+		migrateRepo.Private = *cr.Spec.Private
+	}
+	if u.secretName != "" {
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: cr.GetNamespace(), Name: u.secretName}, secret); err != nil {
+			return nil, errors.Wrap(err, "cannot get upstream secret")
+		}
+		migrateRepo.AuthUserName = string(secret.Data["username"])
+		migrateRepo.AuthPassword = string(secret.Data["password"])
+		migrateRepo.AuthToken = string(secret.Data["token"])
+	}
+	return gitClient.MigrateRepo(migrateRepo)
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetUpstream(t *testing.T) {
+	url := "https://github.com/nephio-project/catalog.git"
+	cases := map[string]struct {
+		annotations map[string]string
+		want        *upstream
+		wantErr     bool
+	}{
+		"NoUpstream": {
+			want: nil,
+		},
+		"Import": {
+			annotations: map[string]string{UpstreamURLAnnotationName: url},
+			want:        &upstream{url: url, mode: UpstreamModeImport},
+		},
+		"Mirror": {
+			annotations: map[string]string{
+				UpstreamURLAnnotationName:    url,
+				UpstreamModeAnnotationName:   "mirror",
+				UpstreamSecretAnnotationName: "github",
+				MirrorIntervalAnnotationName: "90m",
+			},
+			want: &upstream{url: url, mode: UpstreamModeMirror, secretName: "github", mirrorInterval: "1h30m0s"},
+		},
+		"UnknownMode": {
+			annotations: map[string]string{UpstreamURLAnnotationName: url, UpstreamModeAnnotationName: "fork"},
+			wantErr:     true,
+		},
+		"InvalidInterval": {
+			annotations: map[string]string{
+				UpstreamURLAnnotationName:    url,
+				UpstreamModeAnnotationName:   "mirror",
+				MirrorIntervalAnnotationName: "daily",
+			},
+			wantErr: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &infrav1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+			got, err := getUpstream(cr)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("getUpstream() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(upstream{})); diff != "" {
+				t.Errorf("getUpstream() -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMigrateRepo(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "github"},
+		Data:       map[string][]byte{"username": []byte("nephio"), "token": []byte("s3cr3t")},
+	}
+	r := &reconciler{APIPatchingApplicator: resource.NewAPIPatchingApplicator(fake.NewClientBuilder().WithObjects(secret).Build())}
+	private := true
+	cr := &infrav1alpha1.Repository{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "catalog"},
+		Spec:       infrav1alpha1.RepositorySpec{Private: &private},
+	}
+	u := &upstream{url: "https://github.com/nephio-project/catalog.git", mode: UpstreamModeMirror, secretName: "github", mirrorInterval: "8h0m0s"}
+
+	g := gitprovider.NewMockGitProvider(t)
+	g.EXPECT().MigrateRepo(gitprovider.MigrateRepoOptions{
+		Owner:          "edge",
+		Name:           "catalog",
+		Private:        true,
+		CloneURL:       "https://github.com/nephio-project/catalog.git",
+		AuthUserName:   "nephio",
+		AuthToken:      "s3cr3t",
+		Mirror:         true,
+		MirrorInterval: "8h0m0s",
+	}).Return(&gitprovider.Repository{Name: "catalog", Mirror: true}, nil)
+
+	if _, err := r.migrateRepo(context.Background(), g, "edge", u, cr); err != nil {
+		t.Errorf("migrateRepo() unexpected error: %v", err)
+	}
+
+	// a missing secret fails before the git server is called
+	u.secretName = "gitlab"
+	if _, err := r.migrateRepo(context.Background(), g, "edge", u, cr); err == nil {
+		t.Errorf("migrateRepo() expected error")
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"context"
+	"fmt"
+
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// ExternalNameAnnotationName records the name of the access token in the
+	// git server managed by the token. It is set by the reconciler once the
+	// access token is created, rotated or adopted and is also recorded on the
+	// secret holding the access token.
+	ExternalNameAnnotationName = "token.nephio.org/external-name"
+	// AdoptAnnotationName set to true allows the token to take over an
+	// existing access token it does not manage yet, together with the
+	// orphaned secret holding its value
+	AdoptAnnotationName = "token.nephio.org/adopt"
+)
+
+// adoptToken ensures the existing access token with the name is managed by
+// the token. An access token that is not managed yet is only taken over when
+// the token has the adopt annotation, together with the secret that was
+// orphaned with it. As the value of an access token can only be read when it
+// is created, an adopted access token without secret is replaced.
+func (r *reconciler) adoptToken(ctx context.Context, cr *infrav1alpha1.Token, name string, secret *corev1.Secret) error {
+	if cr.GetAnnotations()[ExternalNameAnnotationName] == name {
+		return nil
+	}
+	switch {
+	case secret != nil && metav1.IsControlledBy(secret, cr):
+		// tokens that were reconciled before the external name was recorded
+	case cr.GetAnnotations()[AdoptAnnotationName] != "true":
+		return fmt.Errorf("token %s already exists, set the annotation %s to adopt it", name, AdoptAnnotationName)
+	case secret == nil:
+		log.FromContext(ctx).Info("token adopted without secret, it is replaced", "name", name)
+	case metav1.GetControllerOf(secret) != nil || secret.GetAnnotations()[ExternalNameAnnotationName] != name:
+		return fmt.Errorf("cannot adopt token %s, the secret %s is not the orphaned secret holding its value", name, secret.GetName())
+	default:
+		secret.SetOwnerReferences(append(secret.GetOwnerReferences(), getOwnerReference(cr)))
+		if err := r.Update(ctx, secret); err != nil {
+			return errors.Wrap(err, "cannot adopt secret")
+		}
+		log.FromContext(ctx).Info("token adopted", "name", name)
+	}
+	resource.AddAnnotations(cr, map[string]string{ExternalNameAnnotationName: name})
+	return nil
+}
+
+// recordExternalName persists the external name recorded on the token during
+// the reconcile. Only the annotations are patched, so the status of the token
+// is kept to be updated at the end of the reconcile.
+func (r *reconciler) recordExternalName(ctx context.Context, orig, cr *infrav1alpha1.Token) error {
+	externalName := cr.GetAnnotations()[ExternalNameAnnotationName]
+	if externalName == "" || orig.GetAnnotations()[ExternalNameAnnotationName] == externalName {
+		return nil
+	}
+	patched := orig.DeepCopy()
+	resource.AddAnnotations(patched, map[string]string{ExternalNameAnnotationName: externalName})
+	if err := r.Patch(ctx, patched, client.MergeFrom(orig)); err != nil {
+		return errors.Wrap(err, "cannot record external name")
+	}
+	cr.SetResourceVersion(patched.GetResourceVersion())
+	return nil
+}
+
+// orphanSecret detaches the secret from the token, so it is not garbage
+// collected with it and the access token it holds stays usable. The name of
+// the access token is recorded on the secret, a new token adopting the access
+// token takes the secret over again.
+func (r *reconciler) orphanSecret(ctx context.Context, cr *infrav1alpha1.Token) error {
+	secret, err := r.getSecret(ctx, cr)
+	if err != nil {
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return err
+	}
+	if secret == nil || !metav1.IsControlledBy(secret, cr) {
+		return nil
+	}
+	refs := []metav1.OwnerReference{}
+	for _, ref := range secret.GetOwnerReferences() {
+		if ref.UID != cr.GetUID() {
+			refs = append(refs, ref)
+		}
+	}
+	secret.SetOwnerReferences(refs)
+	name := getTokenName(cr, secret)
+	resource.AddAnnotations(secret, map[string]string{ExternalNameAnnotationName: name})
+	if err := r.Update(ctx, secret); err != nil {
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return errors.Wrap(err, "cannot orphan secret")
+	}
+	log.FromContext(ctx).Info("token orphaned", "name", name)
+	return nil
+}
+
+func getOwnerReference(cr *infrav1alpha1.Token) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: infrav1alpha1.GroupVersion.String(),
+		Kind:       infrav1alpha1.TokenKind,
+		Name:       cr.GetName(),
+		UID:        cr.GetUID(),
+		Controller: pointer.Bool(true),
+	}
+}
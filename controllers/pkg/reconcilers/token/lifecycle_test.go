@@ -0,0 +1,110 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestOrphanAndAdoptToken(t *testing.T) {
+	ctx := context.Background()
+	cr := &infrav1alpha1.Token{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "edge01", UID: "1234"}}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "default",
+			Name:            "edge01",
+			OwnerReferences: []metav1.OwnerReference{getOwnerReference(cr)},
+		},
+		Data: map[string][]byte{"token": []byte("s3cr3t")},
+	}
+	r := &reconciler{APIPatchingApplicator: resource.NewAPIPatchingApplicator(fake.NewClientBuilder().WithObjects(secret).Build())}
+
+	// a token whose secret it controls was reconciled before the external
+	// name was recorded
+	if err := r.adoptToken(ctx, cr.DeepCopy(), "edge01", secret); err != nil {
+		t.Fatalf("adoptToken() unexpected error: %v", err)
+	}
+
+	if err := r.orphanSecret(ctx, cr); err != nil {
+		t.Fatalf("orphanSecret() unexpected error: %v", err)
+	}
+	got := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: "default", Name: "edge01"}, got); err != nil {
+		t.Fatalf("orphaned secret not found: %v", err)
+	}
+	if metav1.GetControllerOf(got) != nil {
+		t.Errorf("orphaned secret is still controlled")
+	}
+	if diff := cmp.Diff("edge01", got.GetAnnotations()[ExternalNameAnnotationName]); diff != "" {
+		t.Errorf("external name -want, +got:\n%s", diff)
+	}
+
+	// a new token only takes the orphaned token over with the adopt annotation
+	adopting := &infrav1alpha1.Token{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "edge01", UID: "5678"}}
+	if err := r.adoptToken(ctx, adopting, "edge01", got); err == nil {
+		t.Fatalf("adoptToken() expected error without the adopt annotation")
+	}
+	adopting.SetAnnotations(map[string]string{AdoptAnnotationName: "true"})
+	if err := r.adoptToken(ctx, adopting, "edge01", got); err != nil {
+		t.Fatalf("adoptToken() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff("edge01", adopting.GetAnnotations()[ExternalNameAnnotationName]); diff != "" {
+		t.Errorf("external name -want, +got:\n%s", diff)
+	}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: "default", Name: "edge01"}, got); err != nil {
+		t.Fatalf("adopted secret not found: %v", err)
+	}
+	if !metav1.IsControlledBy(got, adopting) {
+		t.Errorf("secret is not controlled by the adopting token")
+	}
+
+	// the secret of a token that is not orphaned cannot be adopted
+	other := &infrav1alpha1.Token{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "default",
+		Name:        "edge01",
+		UID:         "9012",
+		Annotations: map[string]string{AdoptAnnotationName: "true"},
+	}}
+	if err := r.adoptToken(ctx, other, "edge01", got); err == nil {
+		t.Errorf("adoptToken() expected error for a secret controlled by another token")
+	}
+}
+
+func TestAdoptTokenWithoutSecret(t *testing.T) {
+	r := &reconciler{APIPatchingApplicator: resource.NewAPIPatchingApplicator(fake.NewClientBuilder().Build())}
+	cr := &infrav1alpha1.Token{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "default",
+		Name:        "edge01",
+		Annotations: map[string]string{AdoptAnnotationName: "true"},
+	}}
+	// the token is adopted to be replaced, as its value is unknown
+	if err := r.adoptToken(context.Background(), cr, "edge01", nil); err != nil {
+		t.Errorf("adoptToken() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff("edge01", cr.GetAnnotations()[ExternalNameAnnotationName]); diff != "" {
+		t.Errorf("external name -want, +got:\n%s", diff)
+	}
+}
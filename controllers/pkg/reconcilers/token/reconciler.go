@@ -20,11 +20,12 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"time"
 
-	"code.gitea.io/sdk/gitea"
 	commonv1alpha1 "github.com/nephio-project/api/common/v1alpha1"
 	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
-	"github.com/nephio-project/nephio/controllers/pkg/giteaclient"
+	"github.com/nephio-project/nephio/controllers/pkg/gitclient"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
 	ctrlconfig "github.com/nephio-project/nephio/controllers/pkg/reconcilers/config"
 	reconcilerinterface "github.com/nephio-project/nephio/controllers/pkg/reconcilers/reconciler-interface"
 	"github.com/nephio-project/nephio/controllers/pkg/resource"
@@ -32,8 +33,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/utils/pointer"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -50,17 +52,18 @@ const (
 
 //+kubebuilder:rbac:groups=infra.nephio.org,resources=tokens,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=infra.nephio.org,resources=tokens/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c interface{}) (map[schema.GroupVersionKind]chan event.GenericEvent, error) {
 	cfg, ok := c.(*ctrlconfig.ControllerConfig)
-	// Sending the porchclient to gitea, this will be used to get
-	// the secret objects for gitea client authentication. The client
+	// Sending the porchclient to the git client, this will be used to get
+	// the secret objects for git client authentication. The client
 	// of the manager of this controller cannot be used at this point.
 	// Should this be conditional ? Only if we have repo/token reconciler
 
 	var e error
-	r.giteaClient, e = giteaclient.GetClient(ctx, resource.NewAPIPatchingApplicator(cfg.PorchClient))
+	r.gitClient, e = gitclient.GetClient(ctx, resource.NewAPIPatchingApplicator(cfg.PorchClient))
 	if e != nil {
 		return nil, e
 	}
@@ -75,6 +78,7 @@ func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c i
 
 	r.APIPatchingApplicator = resource.NewAPIPatchingApplicator(mgr.GetClient())
 	r.finalizer = resource.NewAPIFinalizer(mgr.GetClient(), finalizer)
+	r.backoff = gitclient.NewBackoff()
 
 	return nil, ctrl.NewControllerManagedBy(mgr).
 		Named("TokenController").
@@ -84,8 +88,9 @@ func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c i
 
 type reconciler struct {
 	resource.APIPatchingApplicator
-	giteaClient giteaclient.GiteaClient
-	finalizer   *resource.APIFinalizer
+	gitClient gitprovider.GitProvider
+	finalizer *resource.APIFinalizer
+	backoff   *gitclient.Backoff
 }
 
 func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -102,33 +107,48 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{}, nil
 	}
 
-	// check if client exists otherwise retry
-	if !r.giteaClient.IsInitialized() {
-		err := fmt.Errorf("gitea server unreachable")
+	// check if client is connected otherwise retry once the git client had
+	// the chance to reconnect
+	if !r.gitClient.IsInitialized() {
+		err := fmt.Errorf("git server unreachable: %s", gitclient.GetStatus(r.gitClient))
 		log.Error(err, "cannot connect to git server")
 		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
-		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+		return ctrl.Result{RequeueAfter: gitclient.GetRetryInterval(r.gitClient)}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 	}
 
 	if resource.WasDeleted(cr) {
-		// TODO DELETION POLICY: "orphan" deletion policy
 		// token being deleted
-		// Delete the token from the git server
-		// when successful remove the finalizer
-		if cr.Spec.Lifecycle.DeletionPolicy == commonv1alpha1.DeletionDelete {
-			if err := r.deleteToken(ctx, r.giteaClient, cr); err != nil {
+		// Delete the token from the git server or orphan it based on the
+		// deletion policy, when successful remove the finalizer
+		switch cr.Spec.Lifecycle.DeletionPolicy {
+		case commonv1alpha1.DeletionDelete:
+			if err := r.deletePendingToken(ctx, r.gitClient, cr); err != nil {
+				log.Error(err, "cannot delete previous token in git server")
+				// the full error is not provided since the message changes every
+				// time, which would trigger a new reconcile
+				cr.SetConditions(gitclient.Failed("cannot delete previous token", err))
+				return r.retry(ctx, cr, err)
+			}
+			if err := r.deleteToken(ctx, r.gitClient, cr); err != nil {
 				log.Error(err, "cannot delete token in git server")
-				return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+				return r.retry(ctx, cr, err)
+			}
+		case commonv1alpha1.DeletionOrphan:
+			// the token is kept in the git server together with its secret
+			if err := r.orphanSecret(ctx, cr); err != nil {
+				log.Error(err, "cannot orphan secret")
+				return r.retry(ctx, cr, err)
 			}
 		}
 
 		if err := r.finalizer.RemoveFinalizer(ctx, cr); err != nil {
 			log.Error(err, "cannot remove finalizer")
 			cr.SetConditions(infrav1alpha1.Failed(err.Error()))
-			return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+			return r.retry(ctx, cr, err)
 		}
 
 		log.Info("Successfully deleted resource")
+		r.backoff.Reset(req.String())
 		return ctrl.Result{Requeue: false}, nil
 	}
 
@@ -136,95 +156,307 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	if err := r.finalizer.AddFinalizer(ctx, cr); err != nil {
 		log.Error(err, "cannot add finalizer")
 		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
-		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+		return r.retry(ctx, cr, err)
 	}
 
 	// create token and secret
-	if err := r.createToken(ctx, r.giteaClient, cr); err != nil {
-		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	orig := cr.DeepCopy()
+	requeueAfter, err := r.createToken(ctx, r.gitClient, cr)
+	if err != nil {
+		return r.retry(ctx, cr, err)
 	}
+	if err := r.recordExternalName(ctx, orig, cr); err != nil {
+		log.Error(err, "cannot record external name")
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return r.retry(ctx, cr, err)
+	}
+	r.backoff.Reset(req.String())
 	cr.SetConditions(infrav1alpha1.Ready())
-	return ctrl.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	return ctrl.Result{RequeueAfter: requeueAfter}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+}
+
+// retry updates the status of the failed token and requeues it after the
+// backoff of the class of the error, so errors that need a change of the
+// credentials or the git server are not retried in a tight loop
+func (r *reconciler) retry(ctx context.Context, cr *infrav1alpha1.Token, err error) (ctrl.Result, error) {
+	requeueAfter := r.backoff.Next(client.ObjectKeyFromObject(cr).String(), err)
+	log.FromContext(ctx).Info("retry", "reason", gitclient.ConditionReason(err), "after", requeueAfter)
+	return ctrl.Result{RequeueAfter: requeueAfter}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 }
 
-func (r *reconciler) createToken(ctx context.Context, giteaClient giteaclient.GiteaClient, cr *infrav1alpha1.Token) error {
+// createToken creates the access token and the secret holding it. The access
+// token is replaced when its secret was lost, its scopes changed or its
+// rotation period elapsed. It returns when the token has to be reconciled
+// again to rotate it or to delete the replaced access token.
+func (r *reconciler) createToken(ctx context.Context, gitClient gitprovider.GitProvider, cr *infrav1alpha1.Token) (time.Duration, error) {
+	return r.syncToken(ctx, gitClient, cr, time.Now())
+}
+
+func (r *reconciler) syncToken(ctx context.Context, gitClient gitprovider.GitProvider, cr *infrav1alpha1.Token, now time.Time) (time.Duration, error) {
 	log := log.FromContext(ctx)
-	tokens, _, err := giteaClient.ListAccessTokens(gitea.ListAccessTokensOptions{})
+	rot, err := getRotation(cr)
+	if err != nil {
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return 0, err
+	}
+	tokens, err := gitClient.ListAccessTokens()
 	if err != nil {
 		log.Error(err, "cannot list tokens")
+		cr.SetConditions(gitclient.Failed("cannot list tokens", err))
+		return 0, err
+	}
+	secret, err := r.getSecret(ctx, cr)
+	if err != nil {
+		log.Error(err, "cannot get secret")
 		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
-		return err
+		return 0, err
 	}
-	tokenFound := false
-	for _, token := range tokens {
-		if token.Name == cr.GetTokenName() {
-			tokenFound = true
-			break
+	var status *tokenStatus
+	if secret != nil {
+		if status, err = getTokenStatus(secret); err != nil {
+			cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+			return 0, err
 		}
 	}
-	if !tokenFound {
-		u, _, err := giteaClient.GetMyUserInfo()
-		if err != nil {
-			log.Error(err, "cannot get user info")
+
+	name := getTokenName(cr, secret)
+	current := findToken(tokens, name)
+	reason := "token created"
+	if current != nil {
+		if err := r.adoptToken(ctx, cr, name, secret); err != nil {
+			log.Error(err, "cannot adopt token")
 			cr.SetConditions(infrav1alpha1.Failed(err.Error()))
-			return err
+			return 0, err
+		}
+		if status == nil {
+			reason = "secret lost"
+		} else {
+			reason = rotationReason(rot, status, now)
+		}
+	}
+	if reason != "" {
+		if status, err = r.rotateToken(ctx, gitClient, cr, rot, tokens, current, status, now); err != nil {
+			log.Error(err, "cannot rotate token")
+			cr.SetConditions(rotationFailed("cannot rotate token", err), gitclient.Failed("cannot rotate token", err))
+			return 0, err
 		}
+		log.Info("token rotated", "name", cr.GetAnnotations()[ExternalNameAnnotationName], "reason", reason)
+	}
+	if err := r.deletePreviousToken(ctx, gitClient, cr, tokens, status, now); err != nil {
+		log.Error(err, "cannot delete previous token")
+		cr.SetConditions(rotationFailed("cannot delete previous token", err), gitclient.Failed("cannot delete previous token", err))
+		return 0, err
+	}
+	cr.SetConditions(rotationScheduled(rot, status))
+	return nextReconcile(rot, status, now), nil
+}
 
-		token, _, err := giteaClient.CreateAccessToken(gitea.CreateAccessTokenOption{
-			Name: cr.GetTokenName(),
-			Scopes: []gitea.AccessTokenScope{
-				gitea.AccessTokenScopeRepo,
-			},
-		})
-		if err != nil {
-			log.Error(err, "cannot create token")
-			cr.SetConditions(infrav1alpha1.Failed(err.Error()))
-			return err
+// rotateToken creates a new access token and updates the secret with it at
+// once. The current access token stays valid for the grace period, unless
+// its secret was lost, as nobody can use it then.
+func (r *reconciler) rotateToken(ctx context.Context, gitClient gitprovider.GitProvider, cr *infrav1alpha1.Token, rot *rotation, tokens []*gitprovider.AccessToken, current *gitprovider.AccessToken, status *tokenStatus, now time.Time) (*tokenStatus, error) {
+	log := log.FromContext(ctx)
+	// only a single previous token is tracked, so a previous token that is
+	// still in its grace period is deleted right away
+	if status != nil && status.PreviousToken != "" {
+		if err := deleteAccessToken(gitClient, tokens, status.PreviousToken); err != nil {
+			return nil, errors.Wrap(err, "cannot delete previous token")
 		}
-		log.Info("token created", "name", cr.GetName())
-		secret := &corev1.Secret{
-			TypeMeta: metav1.TypeMeta{
-				APIVersion: corev1.SchemeGroupVersion.Identifier(),
-				Kind:       reflect.TypeFor[corev1.Secret]().Name(),
-			},
-			ObjectMeta: metav1.ObjectMeta{
-				Namespace:   cr.GetNamespace(),
-				Name:        cr.GetName(),
-				Annotations: cr.GetAnnotations(),
-				OwnerReferences: []metav1.OwnerReference{
-					{
-						APIVersion: cr.APIVersion,
-						Kind:       cr.Kind,
-						Name:       cr.Name,
-						UID:        cr.UID,
-						Controller: pointer.Bool(true),
-					},
-				},
-			},
-			Data: map[string][]byte{
-				"username": []byte(u.UserName),
-				"password": []byte(token.Token), // needed for porch
-				"token":    []byte(token.Token), // needed for configsync
-			},
-			Type: corev1.SecretTypeBasicAuth,
-		}
-		if err := r.Apply(ctx, secret); err != nil {
-			cr.SetConditions(infrav1alpha1.Failed(err.Error()))
-			log.Error(err, "cannot create secret")
-			return err
+	}
+	u, err := gitClient.GetMyUserInfo()
+	if err != nil {
+		log.Error(err, "cannot get user info")
+		return nil, err
+	}
+
+	name := cr.GetTokenName()
+	if findToken(tokens, name) != nil {
+		name = fmt.Sprintf("%s-%d", name, now.Unix())
+	}
+	token, err := gitClient.CreateAccessToken(gitprovider.CreateAccessTokenOptions{
+		Name:      name,
+		Scopes:    rot.scopes,
+		ExpiresAt: rot.expiresAt(now),
+	})
+	if err != nil {
+		log.Error(err, "cannot create token")
+		return nil, err
+	}
+	log.Info("token created", "name", name)
+
+	newStatus := &tokenStatus{CreatedAt: metav1.NewTime(now), Scopes: rot.scopes}
+	if current != nil && status != nil {
+		deleteAt := metav1.NewTime(now.Add(rot.gracePeriod))
+		newStatus.PreviousToken = current.Name
+		newStatus.PreviousTokenDeleteAt = &deleteAt
+	}
+	if err := r.applySecret(ctx, cr, u, token, newStatus); err != nil {
+		log.Error(err, "cannot create secret")
+		// the new token is not used by anyone, so it is not kept
+		if err := gitClient.DeleteAccessToken(name); err != nil {
+			log.Error(err, "cannot delete unused token", "name", name)
 		}
-		log.Info("secret for token created", "name", cr.GetName())
+		return nil, err
 	}
-	return nil
+	log.Info("secret for token created", "name", cr.GetName())
+	resource.AddAnnotations(cr, map[string]string{ExternalNameAnnotationName: name})
+
+	if current != nil && status == nil {
+		if err := gitClient.DeleteAccessToken(current.Name); err != nil {
+			return nil, errors.Wrap(err, "cannot delete token of lost secret")
+		}
+	}
+	return newStatus, nil
 }
 
-func (r *reconciler) deleteToken(ctx context.Context, giteaClient giteaclient.GiteaClient, cr *infrav1alpha1.Token) error {
-	_, err := giteaClient.DeleteAccessToken(cr.GetTokenName())
+// deletePreviousToken deletes the replaced access token once its grace
+// period ended
+func (r *reconciler) deletePreviousToken(ctx context.Context, gitClient gitprovider.GitProvider, cr *infrav1alpha1.Token, tokens []*gitprovider.AccessToken, status *tokenStatus, now time.Time) error {
+	if status.PreviousToken == "" || (status.PreviousTokenDeleteAt != nil && now.Before(status.PreviousTokenDeleteAt.Time)) {
+		return nil
+	}
+	if err := deleteAccessToken(gitClient, tokens, status.PreviousToken); err != nil {
+		return err
+	}
+	log.FromContext(ctx).Info("previous token deleted", "name", status.PreviousToken)
+	status.PreviousToken = ""
+	status.PreviousTokenDeleteAt = nil
+	secret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.Identifier(),
+			Kind:       reflect.TypeFor[corev1.Secret]().Name(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   cr.GetNamespace(),
+			Name:        cr.GetName(),
+			Annotations: map[string]string{statusAnnotationName: status.String()},
+		},
+	}
+	return errors.Wrap(r.Apply(ctx, secret), "cannot update token status")
+}
+
+// deletePendingToken deletes a replaced access token whose grace period did
+// not end yet when the token is deleted
+func (r *reconciler) deletePendingToken(ctx context.Context, gitClient gitprovider.GitProvider, cr *infrav1alpha1.Token) error {
+	secret, err := r.getSecret(ctx, cr)
+	if err != nil || secret == nil {
+		return err
+	}
+	status, err := getTokenStatus(secret)
+	if err != nil || status.PreviousToken == "" {
+		return err
+	}
+	tokens, err := gitClient.ListAccessTokens()
 	if err != nil {
-		log.FromContext(ctx).Error(err, "cannot delete token")
-		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
 		return err
 	}
-	log.FromContext(ctx).Info("token deleted", "name", cr.GetTokenName())
+	return deleteAccessToken(gitClient, tokens, status.PreviousToken)
+}
+
+// applySecret updates the username, password and token of the secret at once
+func (r *reconciler) applySecret(ctx context.Context, cr *infrav1alpha1.Token, u *gitprovider.User, token *gitprovider.AccessToken, status *tokenStatus) error {
+	annotations := map[string]string{}
+	for k, v := range cr.GetAnnotations() {
+		annotations[k] = v
+	}
+	annotations[ExternalNameAnnotationName] = token.Name
+	annotations[statusAnnotationName] = status.String()
+	secret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.Identifier(),
+			Kind:       reflect.TypeFor[corev1.Secret]().Name(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       cr.GetNamespace(),
+			Name:            cr.GetName(),
+			Annotations:     annotations,
+			OwnerReferences: []metav1.OwnerReference{getOwnerReference(cr)},
+		},
+		Data: map[string][]byte{
+			"username": []byte(u.UserName),
+			"password": []byte(token.Token), // needed for porch
+			"token":    []byte(token.Token), // needed for configsync
+		},
+		Type: corev1.SecretTypeBasicAuth,
+	}
+	if err := r.Apply(ctx, secret); err != nil {
+		return errors.Wrap(err, "cannot apply secret")
+	}
+	return nil
+}
+
+func (r *reconciler) getSecret(ctx context.Context, cr *infrav1alpha1.Token) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: cr.GetNamespace(), Name: cr.GetName()}, secret); err != nil {
+		if resource.IgnoreNotFound(err) != nil {
+			return nil, errors.Wrap(err, "cannot get secret")
+		}
+		return nil, nil
+	}
+	return secret, nil
+}
+
+// getTokenName returns the name of the access token held by the token. The
+// name of the initial access token is derived from the token, rotated access
+// tokens are recorded on the token and the secret.
+func getTokenName(cr *infrav1alpha1.Token, secret *corev1.Secret) string {
+	if name := cr.GetAnnotations()[ExternalNameAnnotationName]; name != "" {
+		return name
+	}
+	if secret != nil {
+		if name := secret.GetAnnotations()[ExternalNameAnnotationName]; name != "" {
+			return name
+		}
+	}
+	return cr.GetTokenName()
+}
+
+func findToken(tokens []*gitprovider.AccessToken, name string) *gitprovider.AccessToken {
+	for _, token := range tokens {
+		if token.Name == name {
+			return token
+		}
+	}
+	return nil
+}
+
+// deleteAccessToken deletes the access token when it exists
+func deleteAccessToken(gitClient gitprovider.GitProvider, tokens []*gitprovider.AccessToken, name string) error {
+	if findToken(tokens, name) == nil {
+		return nil
+	}
+	return gitClient.DeleteAccessToken(name)
+}
+
+// deleteToken deletes the access token managed by the token. An access token
+// that was not created or adopted by the token is kept, and an access token
+// that no longer exists is deleted already.
+func (r *reconciler) deleteToken(ctx context.Context, gitClient gitprovider.GitProvider, cr *infrav1alpha1.Token) error {
+	log := log.FromContext(ctx)
+	name, ok := cr.GetAnnotations()[ExternalNameAnnotationName]
+	if !ok {
+		// tokens that were reconciled before the external name was recorded
+		// manage the access token of the secret they control
+		secret, err := r.getSecret(ctx, cr)
+		if err != nil {
+			cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+			return err
+		}
+		if secret == nil || !metav1.IsControlledBy(secret, cr) {
+			log.Info("token not managed, not deleted", "name", cr.GetTokenName())
+			return nil
+		}
+		name = getTokenName(cr, secret)
+	}
+	if err := gitClient.DeleteAccessToken(name); err != nil {
+		if gitprovider.IsNotFound(err) {
+			log.Info("token already deleted", "name", name)
+			return nil
+		}
+		log.Error(err, "cannot delete token")
+		cr.SetConditions(gitclient.Failed("cannot delete token", err))
+		return err
+	}
+	log.Info("token deleted", "name", name)
 	return nil
 }
@@ -17,28 +17,29 @@ package token
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"testing"
 
-	"code.gitea.io/sdk/gitea"
 
 	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
-	"github.com/nephio-project/nephio/controllers/pkg/giteaclient"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
 	"github.com/nephio-project/nephio/controllers/pkg/resource"
 	"github.com/nephio-project/nephio/testing/mockeryutils"
 	"github.com/stretchr/testify/mock"
 	"github.com/nephio-project/nephio/controllers/pkg/mocks/external/client"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 type fields struct {
 	APIPatchingApplicator resource.APIPatchingApplicator
-	giteaClient           giteaclient.GiteaClient
+	gitClient             gitprovider.GitProvider
 	finalizer             *resource.APIFinalizer
 }
 type args struct {
 	ctx         context.Context
-	giteaClient giteaclient.GiteaClient
+	gitClient gitprovider.GitProvider
 	cr          *infrav1alpha1.Token
 }
 type tokenTests struct {
@@ -50,41 +51,66 @@ type tokenTests struct {
 }
 
 func TestDeleteToken(t *testing.T) {
+	managed := &infrav1alpha1.Token{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "default",
+		Name:        "edge01",
+		Annotations: map[string]string{ExternalNameAnnotationName: "edge01"},
+	}}
 	tests := []tokenTests {
 		{
 			name:   "Delete Access token reports error",
 			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil},
-			args:   args{nil, nil, &infrav1alpha1.Token{}},
+			args:   args{nil, nil, managed.DeepCopy()},
 			mocks: []mockeryutils.MockHelper{
 				{MethodName: "DeleteAccessToken", 
 				ArgType: []string{"string"}, 
-				RetArgList: []interface{}{nil, fmt.Errorf("\"username\" not set: only BasicAuth allowed")}},
+				RetArgList: []interface{}{fmt.Errorf("\"username\" not set: only BasicAuth allowed")}},
 			},
 			wantErr: true,
 		},
 		{
 			name:   "Delete Access token success",
 			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil},
-			args:   args{nil, nil, &infrav1alpha1.Token{}},
+			args:   args{nil, nil, managed.DeepCopy()},
 			mocks: []mockeryutils.MockHelper{
 				{MethodName: "DeleteAccessToken", 
 				ArgType: []string{"string"}, 
-				RetArgList: []interface{}{nil, nil}},
+				RetArgList: []interface{}{nil}},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "Access token already deleted",
+			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil},
+			args:   args{nil, nil, managed.DeepCopy()},
+			mocks: []mockeryutils.MockHelper{
+				{MethodName: "DeleteAccessToken",
+				ArgType: []string{"string"},
+				RetArgList: []interface{}{gitprovider.NewError(http.StatusNotFound, fmt.Errorf("not found"))}},
 			},
 			wantErr: false,
 		},
+		{
+			// the token has no external name and controls no secret, so it
+			// never created nor adopted the access token; DeleteAccessToken
+			// is not mocked so a call fails the test
+			name:   "Access token not managed",
+			fields: fields{resource.NewAPIPatchingApplicator(fake.NewClientBuilder().Build()), nil, nil},
+			args:   args{context.Background(), nil, &infrav1alpha1.Token{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "edge01"}}},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			r := &reconciler{
 				APIPatchingApplicator: tt.fields.APIPatchingApplicator,
-				giteaClient:           tt.fields.giteaClient,
+				gitClient:             tt.fields.gitClient,
 				finalizer:             tt.fields.finalizer,
 			}
 			
 			initMockeryMocks(&tt)
 
-			if err := r.deleteToken(tt.args.ctx, tt.args.giteaClient, tt.args.cr); (err != nil) != tt.wantErr {
+			if err := r.deleteToken(tt.args.ctx, tt.args.gitClient, tt.args.cr); (err != nil) != tt.wantErr {
 				t.Errorf("deleteToken() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -104,14 +130,14 @@ func TestCreateToken(t *testing.T) {
 			args:   args{nil, nil, &infrav1alpha1.Token{}},
 			mocks: []mockeryutils.MockHelper{
 				{MethodName: "ListAccessTokens", 
-				ArgType: []string{"gitea.ListAccessTokensOptions"}, 
-				RetArgList: []interface{}{nil, nil, fmt.Errorf("\"username\" not set: only BasicAuth allowed")}},
+				ArgType: []string{}, 
+				RetArgList: []interface{}{nil, fmt.Errorf("\"username\" not set: only BasicAuth allowed")}},
 			},
 			wantErr: true,
 		},
 		{
 			name:   "Create Access token already exists",
-			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil},
+			fields: fields{resource.NewAPIPatchingApplicator(clientMock), nil, nil},
 			args:   args{nil, nil, &infrav1alpha1.Token{
 				TypeMeta: metav1.TypeMeta{
 					APIVersion: corev1.SchemeGroupVersion.Identifier(),
@@ -119,48 +145,48 @@ func TestCreateToken(t *testing.T) {
 			ObjectMeta: metav1.ObjectMeta{
 				Namespace:   "test-ns",
 				Name:        "test-token",
-				
+				Annotations: map[string]string{ExternalNameAnnotationName: "test-token-test-ns"},
 			}}},
 			mocks: []mockeryutils.MockHelper{
 				{MethodName: "ListAccessTokens", 
-				ArgType: []string{"gitea.ListAccessTokensOptions"}, 
-				RetArgList: []interface{}{[]*gitea.AccessToken{
+				ArgType: []string{}, 
+				RetArgList: []interface{}{[]*gitprovider.AccessToken{
 					{ID: 123,
 					Name: "test-token-test-ns",},
-				}, nil, nil}},
+				}, nil}},
 			},
 			wantErr: false,
 		},
 		{
 			name:   "Create Access token reports user info not found",
-			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil},
+			fields: fields{resource.NewAPIPatchingApplicator(clientMock), nil, nil},
 			args:   args{nil, nil, &infrav1alpha1.Token{}},
 			mocks: []mockeryutils.MockHelper{
 				{MethodName: "ListAccessTokens", 
-				ArgType: []string{"gitea.ListAccessTokensOptions"}, 
-				RetArgList: []interface{}{[]*gitea.AccessToken{
+				ArgType: []string{}, 
+				RetArgList: []interface{}{[]*gitprovider.AccessToken{
 					{ID: 123,
 					Name: "test-token-test-ns",},
-				}, nil, nil}},
-				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{nil, nil, fmt.Errorf("error getting User Information")}},
+				}, nil}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{nil, fmt.Errorf("error getting User Information")}},
 			},
 			wantErr: true,
 		},
 		{
 			name:   "Create Access token reports failed to create",
-			fields: fields{resource.NewAPIPatchingApplicator(nil), nil, nil},
+			fields: fields{resource.NewAPIPatchingApplicator(clientMock), nil, nil},
 			args:   args{nil, nil, &infrav1alpha1.Token{}},
 			mocks: []mockeryutils.MockHelper{
 				{MethodName: "ListAccessTokens", 
-				ArgType: []string{"gitea.ListAccessTokensOptions"}, 
-				RetArgList: []interface{}{[]*gitea.AccessToken{
+				ArgType: []string{}, 
+				RetArgList: []interface{}{[]*gitprovider.AccessToken{
 					{ID: 123,
 					Name: "test-token-test-ns",},
-				}, nil, nil}},
-				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitea.User{UserName: "gitea"}, nil, nil}},
+				}, nil}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
 				{MethodName: "CreateAccessToken", 
-				ArgType: []string{"gitea.CreateAccessTokenOption"}, 
-				RetArgList: []interface{}{&gitea.AccessToken{}, nil, fmt.Errorf("failed to create token")}},
+				ArgType: []string{"gitprovider.CreateAccessTokenOptions"}, 
+				RetArgList: []interface{}{&gitprovider.AccessToken{}, fmt.Errorf("failed to create token")}},
 			},
 			wantErr: true,
 		},
@@ -177,12 +203,12 @@ func TestCreateToken(t *testing.T) {
 				
 			}}},
 			mocks: []mockeryutils.MockHelper{
-				{MethodName: "ListAccessTokens", ArgType: []string{"gitea.ListAccessTokensOptions"}, RetArgList: []interface{}{[]*gitea.AccessToken{}, nil, nil}},
-				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitea.User{UserName: "gitea"}, nil, nil}},
+				{MethodName: "ListAccessTokens", ArgType: []string{}, RetArgList: []interface{}{[]*gitprovider.AccessToken{}, nil}},
+				{MethodName: "GetMyUserInfo", ArgType: []string{}, RetArgList: []interface{}{&gitprovider.User{UserName: "gitea"}, nil}},
 				{MethodName: "CreateAccessToken", 
-				ArgType: []string{"gitea.CreateAccessTokenOption"}, 
-				RetArgList: []interface{}{&gitea.AccessToken{ID: 123,
-					Name: "test-token-test-ns"}, nil, nil}},
+				ArgType: []string{"gitprovider.CreateAccessTokenOptions"}, 
+				RetArgList: []interface{}{&gitprovider.AccessToken{ID: 123,
+					Name: "test-token-test-ns"}, nil}},
 			},
 			wantErr: false,
 		},
@@ -191,13 +217,13 @@ func TestCreateToken(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			r := &reconciler{
 				APIPatchingApplicator: tt.fields.APIPatchingApplicator,
-				giteaClient:           tt.fields.giteaClient,
+				gitClient:             tt.fields.gitClient,
 				finalizer:             tt.fields.finalizer,
 			}
 
 			initMockeryMocks(&tt)
 
-			if err := r.createToken(tt.args.ctx, tt.args.giteaClient, tt.args.cr); (err != nil) != tt.wantErr {
+			if _, err := r.createToken(tt.args.ctx, tt.args.gitClient, tt.args.cr); (err != nil) != tt.wantErr {
 				t.Errorf("createToken() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -205,8 +231,8 @@ func TestCreateToken(t *testing.T) {
 }
 
 func initMockeryMocks(tt *tokenTests) {
-	mockGiteaClient := new(giteaclient.MockGiteaClient)
-	tt.args.giteaClient = mockGiteaClient
-	tt.fields.giteaClient = mockGiteaClient
-	mockeryutils.InitMocks(&mockGiteaClient.Mock, tt.mocks)
+	mockGitClient := new(gitprovider.MockGitProvider)
+	tt.args.gitClient = mockGitClient
+	tt.fields.gitClient = mockGitClient
+	mockeryutils.InitMocks(&mockGitClient.Mock, tt.mocks)
 }
\ No newline at end of file
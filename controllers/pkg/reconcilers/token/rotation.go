@@ -0,0 +1,202 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/gitclient"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ScopesAnnotationName is the comma separated list of scopes of the access
+	// token, repo when not provided. Changing the scopes rotates the token.
+	ScopesAnnotationName = "token.nephio.org/scopes"
+	// RotationPeriodAnnotationName is the period after which the access token
+	// is replaced by a new one, e.g. 720h. The token is not rotated when not
+	// provided.
+	RotationPeriodAnnotationName = "token.nephio.org/rotation-period"
+	// RotationGracePeriodAnnotationName is the period the replaced access
+	// token stays valid after a rotation, so consumers of the secret can pick
+	// up the new token
+	RotationGracePeriodAnnotationName = "token.nephio.org/rotation-grace-period"
+
+	// statusAnnotationName records the tokenStatus on the secret
+	statusAnnotationName = "token.nephio.org/status"
+
+	defaultGracePeriod = 10 * time.Minute
+
+	ConditionTypeRotation infrav1alpha1.ConditionType = "Rotation"
+)
+
+// rotation is the rotation configuration of the token
+type rotation struct {
+	scopes      []string
+	period      time.Duration
+	gracePeriod time.Duration
+}
+
+// tokenStatus is the state of the access token held by the secret
+type tokenStatus struct {
+	CreatedAt metav1.Time `json:"createdAt"`
+	Scopes    []string    `json:"scopes,omitempty"`
+	// PreviousToken is the name of the access token that was replaced and is
+	// deleted once its grace period ends at PreviousTokenDeleteAt
+	PreviousToken         string       `json:"previousToken,omitempty"`
+	PreviousTokenDeleteAt *metav1.Time `json:"previousTokenDeleteAt,omitempty"`
+}
+
+func getRotation(cr *infrav1alpha1.Token) (*rotation, error) {
+	annotations := cr.GetAnnotations()
+	rot := &rotation{
+		scopes:      []string{gitprovider.ScopeRepo},
+		gracePeriod: defaultGracePeriod,
+	}
+	if scopes := annotations[ScopesAnnotationName]; scopes != "" {
+		rot.scopes = []string{}
+		for _, scope := range strings.Split(scopes, ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				rot.scopes = append(rot.scopes, scope)
+			}
+		}
+		sort.Strings(rot.scopes)
+	}
+	if period := annotations[RotationPeriodAnnotationName]; period != "" {
+		d, err := time.ParseDuration(period)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid rotation period %s", period)
+		}
+		rot.period = d
+	}
+	if gracePeriod := annotations[RotationGracePeriodAnnotationName]; gracePeriod != "" {
+		d, err := time.ParseDuration(gracePeriod)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid rotation grace period %s", gracePeriod)
+		}
+		rot.gracePeriod = d
+	}
+	if rot.period > 0 && rot.gracePeriod >= rot.period {
+		return nil, fmt.Errorf("the rotation grace period %s must be shorter than the rotation period %s", rot.gracePeriod, rot.period)
+	}
+	return rot, nil
+}
+
+// expiresAt returns the expiry of an access token created at now. The token
+// expires after two rotation periods, so it stays valid when a rotation is
+// delayed.
+func (rot *rotation) expiresAt(now time.Time) *time.Time {
+	if rot.period == 0 {
+		return nil
+	}
+	t := now.Add(2 * rot.period)
+	return &t
+}
+
+// getTokenStatus returns the status recorded on the secret. Secrets that were
+// created before the status was recorded hold a repo token created with the
+// secret.
+func getTokenStatus(secret *corev1.Secret) (*tokenStatus, error) {
+	status := &tokenStatus{}
+	s, ok := secret.GetAnnotations()[statusAnnotationName]
+	if !ok {
+		return &tokenStatus{CreatedAt: secret.GetCreationTimestamp(), Scopes: []string{gitprovider.ScopeRepo}}, nil
+	}
+	if err := json.Unmarshal([]byte(s), status); err != nil {
+		return nil, errors.Wrap(err, "invalid token status")
+	}
+	return status, nil
+}
+
+func (s *tokenStatus) String() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// rotationReason returns why the access token has to be replaced, empty when
+// it is still valid
+func rotationReason(rot *rotation, status *tokenStatus, now time.Time) string {
+	if !sameScopes(rot.scopes, status.Scopes) {
+		return "scopes changed"
+	}
+	if rot.period > 0 && !now.Before(status.CreatedAt.Add(rot.period)) {
+		return "rotation period elapsed"
+	}
+	return ""
+}
+
+// nextReconcile returns when the token has to be reconciled again to rotate
+// it or to delete the previous token, 0 when nothing is scheduled
+func nextReconcile(rot *rotation, status *tokenStatus, now time.Time) time.Duration {
+	var next time.Duration
+	schedule := func(t time.Time) {
+		d := t.Sub(now)
+		if d <= 0 {
+			d = time.Second
+		}
+		if next == 0 || d < next {
+			next = d
+		}
+	}
+	if rot.period > 0 {
+		schedule(status.CreatedAt.Add(rot.period))
+	}
+	if status.PreviousTokenDeleteAt != nil {
+		schedule(status.PreviousTokenDeleteAt.Time)
+	}
+	return next
+}
+
+func rotationScheduled(rot *rotation, status *tokenStatus) infrav1alpha1.Condition {
+	msg := "no rotation period"
+	if rot.period > 0 {
+		msg = fmt.Sprintf("next rotation at %s", status.CreatedAt.Add(rot.period).UTC().Format(time.RFC3339))
+	}
+	return infrav1alpha1.Condition{Condition: metav1.Condition{
+		Type:               string(ConditionTypeRotation),
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             string(infrav1alpha1.ConditionReasonReady),
+		Message:            msg,
+	}}
+}
+
+func rotationFailed(msg string, err error) infrav1alpha1.Condition {
+	return infrav1alpha1.Condition{Condition: metav1.Condition{
+		Type:               string(ConditionTypeRotation),
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             string(gitclient.ConditionReason(err)),
+		Message:            msg,
+	}}
+}
+
+func sameScopes(a, b []string) bool {
+	a = append([]string{}, a...)
+	b = append([]string{}, b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	return strings.Join(a, ",") == strings.Join(b, ",")
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetRotation(t *testing.T) {
+	cases := map[string]struct {
+		annotations map[string]string
+		want        *rotation
+		wantErr     bool
+	}{
+		"Default": {
+			want: &rotation{scopes: []string{gitprovider.ScopeRepo}, gracePeriod: defaultGracePeriod},
+		},
+		"Rotation": {
+			annotations: map[string]string{
+				ScopesAnnotationName:              "write:repository, read:user",
+				RotationPeriodAnnotationName:      "720h",
+				RotationGracePeriodAnnotationName: "1h",
+			},
+			want: &rotation{scopes: []string{"read:user", "write:repository"}, period: 720 * time.Hour, gracePeriod: time.Hour},
+		},
+		"InvalidPeriod": {
+			annotations: map[string]string{RotationPeriodAnnotationName: "monthly"},
+			wantErr:     true,
+		},
+		"GracePeriodTooLong": {
+			annotations: map[string]string{RotationPeriodAnnotationName: "1h", RotationGracePeriodAnnotationName: "2h"},
+			wantErr:     true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &infrav1alpha1.Token{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+			got, err := getRotation(cr)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("getRotation() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(rotation{})); diff != "" {
+				t.Errorf("getRotation() -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func newTokenSecret(name string, status *tokenStatus) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "edge01",
+			Annotations: map[string]string{
+				ExternalNameAnnotationName: name,
+				statusAnnotationName:       status.String(),
+			},
+			OwnerReferences: []metav1.OwnerReference{{UID: "1234", Controller: &[]bool{true}[0]}},
+		},
+		Data: map[string][]byte{"token": []byte("secret-" + name)},
+	}
+}
+
+func getTestSecret(t *testing.T, c client.Client) (*corev1.Secret, *tokenStatus) {
+	secret := &corev1.Secret{}
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "edge01"}, secret); err != nil {
+		t.Fatalf("cannot get secret: %v", err)
+	}
+	status, err := getTokenStatus(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return secret, status
+}
+
+func TestRotateToken(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	now := created.Add(721 * time.Hour)
+	rotated := "edge01-1698714000"
+	cr := &infrav1alpha1.Token{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "default",
+		Name:      "edge01",
+		UID:       "1234",
+		Annotations: map[string]string{
+			ExternalNameAnnotationName:        "edge01",
+			RotationPeriodAnnotationName:      "720h",
+			RotationGracePeriodAnnotationName: "1h",
+		},
+	}}
+	secret := newTokenSecret("edge01", &tokenStatus{CreatedAt: metav1.NewTime(created), Scopes: []string{gitprovider.ScopeRepo}})
+	c := fake.NewClientBuilder().WithObjects(secret).Build()
+	r := &reconciler{APIPatchingApplicator: resource.NewAPIPatchingApplicator(c)}
+
+	// the rotation period elapsed, so a new token replaces the current one
+	// which stays valid during the grace period
+	expiresAt := now.Add(1440 * time.Hour)
+	g := gitprovider.NewMockGitProvider(t)
+	g.EXPECT().ListAccessTokens().Return([]*gitprovider.AccessToken{{ID: 1, Name: "edge01"}}, nil).Once()
+	g.EXPECT().GetMyUserInfo().Return(&gitprovider.User{UserName: "nephio"}, nil).Once()
+	g.EXPECT().CreateAccessToken(gitprovider.CreateAccessTokenOptions{Name: rotated, Scopes: []string{gitprovider.ScopeRepo}, ExpiresAt: &expiresAt}).
+		Return(&gitprovider.AccessToken{ID: 2, Name: rotated, Token: "secret-rotated"}, nil).Once()
+	requeue, err := r.syncToken(ctx, g, cr, now)
+	if err != nil {
+		t.Fatalf("syncToken() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(time.Hour, requeue); diff != "" {
+		t.Errorf("requeue -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(rotated, cr.GetAnnotations()[ExternalNameAnnotationName]); diff != "" {
+		t.Errorf("external name -want, +got:\n%s", diff)
+	}
+	got, status := getTestSecret(t, c)
+	for _, key := range []string{"password", "token"} {
+		if diff := cmp.Diff("secret-rotated", string(got.Data[key])); diff != "" {
+			t.Errorf("secret %s -want, +got:\n%s", key, diff)
+		}
+	}
+	if diff := cmp.Diff("edge01", status.PreviousToken); diff != "" {
+		t.Errorf("previous token -want, +got:\n%s", diff)
+	}
+	// the token is read without its type, the owner reference is built from
+	// the api of the token
+	if diff := cmp.Diff([]metav1.OwnerReference{getOwnerReference(cr)}, got.GetOwnerReferences()); diff != "" {
+		t.Errorf("owner references -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff("next rotation at 2023-11-30T01:00:00Z", cr.GetCondition(ConditionTypeRotation).Message); diff != "" {
+		t.Errorf("rotation condition -want, +got:\n%s", diff)
+	}
+
+	// the previous token is deleted once its grace period ended
+	now = now.Add(time.Hour)
+	g.EXPECT().ListAccessTokens().Return([]*gitprovider.AccessToken{{ID: 1, Name: "edge01"}, {ID: 2, Name: rotated}}, nil).Once()
+	g.EXPECT().DeleteAccessToken("edge01").Return(nil).Once()
+	requeue, err = r.syncToken(ctx, g, cr, now)
+	if err != nil {
+		t.Fatalf("syncToken() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(719*time.Hour, requeue); diff != "" {
+		t.Errorf("requeue -want, +got:\n%s", diff)
+	}
+	_, status = getTestSecret(t, c)
+	if diff := cmp.Diff("", status.PreviousToken); diff != "" {
+		t.Errorf("previous token -want, +got:\n%s", diff)
+	}
+}
+
+func TestRotateTokenScopesChanged(t *testing.T) {
+	now := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	cr := &infrav1alpha1.Token{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "default",
+		Name:      "edge01",
+		UID:       "1234",
+		Annotations: map[string]string{
+			ExternalNameAnnotationName: "edge01",
+			ScopesAnnotationName:       "write:repository",
+		},
+	}}
+	secret := newTokenSecret("edge01", &tokenStatus{CreatedAt: metav1.NewTime(now), Scopes: []string{gitprovider.ScopeRepo}})
+	c := fake.NewClientBuilder().WithObjects(secret).Build()
+	r := &reconciler{APIPatchingApplicator: resource.NewAPIPatchingApplicator(c)}
+
+	g := gitprovider.NewMockGitProvider(t)
+	g.EXPECT().ListAccessTokens().Return([]*gitprovider.AccessToken{{ID: 1, Name: "edge01"}}, nil)
+	g.EXPECT().GetMyUserInfo().Return(&gitprovider.User{UserName: "nephio"}, nil)
+	g.EXPECT().CreateAccessToken(gitprovider.CreateAccessTokenOptions{Name: "edge01-1696118400", Scopes: []string{"write:repository"}}).
+		Return(&gitprovider.AccessToken{ID: 2, Name: "edge01-1696118400", Token: "secret-rotated"}, nil)
+	requeue, err := r.syncToken(context.Background(), g, cr, now)
+	if err != nil {
+		t.Fatalf("syncToken() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(defaultGracePeriod, requeue); diff != "" {
+		t.Errorf("requeue -want, +got:\n%s", diff)
+	}
+	_, status := getTestSecret(t, c)
+	if diff := cmp.Diff([]string{"write:repository"}, status.Scopes); diff != "" {
+		t.Errorf("scopes -want, +got:\n%s", diff)
+	}
+}
+
+func TestRegenerateLostSecret(t *testing.T) {
+	now := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	cr := &infrav1alpha1.Token{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "default",
+		Name:        "edge01",
+		UID:         "1234",
+		Annotations: map[string]string{ExternalNameAnnotationName: "edge01"},
+	}}
+	c := fake.NewClientBuilder().Build()
+	r := &reconciler{APIPatchingApplicator: resource.NewAPIPatchingApplicator(c)}
+
+	// nobody can use the token of the lost secret, so it is deleted right away
+	g := gitprovider.NewMockGitProvider(t)
+	g.EXPECT().ListAccessTokens().Return([]*gitprovider.AccessToken{{ID: 1, Name: "edge01"}}, nil)
+	g.EXPECT().GetMyUserInfo().Return(&gitprovider.User{UserName: "nephio"}, nil)
+	g.EXPECT().CreateAccessToken(gitprovider.CreateAccessTokenOptions{Name: "edge01-1696118400", Scopes: []string{gitprovider.ScopeRepo}}).
+		Return(&gitprovider.AccessToken{ID: 2, Name: "edge01-1696118400", Token: "secret-regenerated"}, nil)
+	g.EXPECT().DeleteAccessToken("edge01").Return(nil)
+	requeue, err := r.syncToken(context.Background(), g, cr, now)
+	if err != nil {
+		t.Fatalf("syncToken() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(time.Duration(0), requeue); diff != "" {
+		t.Errorf("requeue -want, +got:\n%s", diff)
+	}
+	got, status := getTestSecret(t, c)
+	if diff := cmp.Diff("secret-regenerated", string(got.Data["token"])); diff != "" {
+		t.Errorf("secret token -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff("edge01-1696118400", got.GetAnnotations()[ExternalNameAnnotationName]); diff != "" {
+		t.Errorf("secret external name -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff("", status.PreviousToken); diff != "" {
+		t.Errorf("previous token -want, +got:\n%s", diff)
+	}
+}
@@ -17,113 +17,46 @@
 package specializerreconciler
 
 import (
-	"context"
-	"fmt"
-
-	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
 	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
-	"github.com/go-logr/logr"
-	"github.com/nephio-project/nephio/controllers/pkg/resource"
 	kptfilelibv1 "github.com/nephio-project/nephio/krm-functions/lib/kptfile/v1"
-	"github.com/nephio-project/nephio/krm-functions/lib/kptrl"
-	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/log"
-	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+// Config is the dedicated controller of a specializer
 type Config struct {
-	For         corev1.ObjectReference
-	PorchClient client.Client
-	KRMfunction fn.ResourceListProcessor
+	// Name of the controller
+	Name string
+	// For is the resource of the specializer, the controller only watches the
+	// package revisions with a condition of the For resource
+	For corev1.ObjectReference
 }
 
 // +kubebuilder:rbac:groups=porch.kpt.dev,resources=packagerevisions,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=porch.kpt.dev,resources=packagerevisions/status,verbs=get;update;patch
-// SetupWithManager sets up the controller with the Manager.
-func Setup(mgr ctrl.Manager, cfg Config) error {
-	//ge := make(chan event.GenericEvent)
-	r := &reconciler{
-		Client:      mgr.GetClient(),
-		For:         cfg.For,
-		porchClient: cfg.PorchClient,
-		krmfn:       cfg.KRMfunction,
+// Setup sets up a dedicated specializer controller with the Manager, the
+// reconciler runs the specializer on the package revisions.
+func Setup(mgr ctrl.Manager, cfg Config, r reconcile.Reconciler) error {
+	if err := porchv1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
+		return err
 	}
 
-	// TBD how does the proxy cache work with the injector for updates
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&porchv1alpha1.PackageRevision{}).
+		Named(cfg.Name).
+		For(&porchv1alpha1.PackageRevision{}, builder.WithPredicates(HasConditions(cfg.For))).
 		Complete(r)
 }
 
-// reconciler reconciles a NetworkInstance object
-type reconciler struct {
-	client.Client
-	For         corev1.ObjectReference
-	porchClient client.Client
-	krmfn       fn.ResourceListProcessor
-
-	l logr.Logger
-}
-
-func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	r.l = log.FromContext(ctx).WithValues("req", req)
-	r.l.Info("reconcile specializer")
-
-	pr := &porchv1alpha1.PackageRevision{}
-	if err := r.Get(ctx, req.NamespacedName, pr); err != nil {
-		// There's no need to requeue if we no longer exist. Otherwise we'll be
-		// requeued implicitly because we return an error.
-		if resource.IgnoreNotFound(err) != nil {
-			r.l.Error(err, "cannot get resource")
-			return ctrl.Result{}, errors.Wrap(resource.IgnoreNotFound(err), "cannot get resource")
-		}
-		return ctrl.Result{}, nil
-	}
-	// we just check for forResource conditions and we don't care if it is satisfied already
-	// this allows us to refresh the allocation.
-	ct := kptfilelibv1.GetConditionType(&r.For)
-	if hasSpecificTypeConditions(pr.Status.Conditions, ct) {
-		// get package revision resourceList
-		prr := &porchv1alpha1.PackageRevisionResources{}
-		if err := r.porchClient.Get(ctx, req.NamespacedName, prr); err != nil {
-			r.l.Error(err, "cannot get package revision resources")
-			return ctrl.Result{}, errors.Wrap(err, "cannot get package revision resources")
-		}
-		// get resourceList from resources
-		rl, err := kptrl.GetResourceList(prr.Spec.Resources)
-		if err != nil {
-			r.l.Error(err, "cannot get resourceList")
-			return ctrl.Result{}, errors.Wrap(err, "cannot get resourceList")
-		}
-
-		// run the function SDK
-		_, err = r.krmfn.Process(rl)
-		if err != nil {
-			r.l.Error(err, "function run failed")
-			// TBD if we need to return here + check if kptfile is set
-			//return ctrl.Result{}, errors.Wrap(err, "function run failed")
-		}
-		for _, o := range rl.Items {
-			r.l.Info("resourceList", "data", o.String())
-			// TBD what if we create new resources
-			// update the resources with the latest info
-			prr.Spec.Resources[o.GetAnnotation(kioutil.PathAnnotation)] = o.String()
-		}
-		kptfile := rl.Items.GetRootKptfile()
-		if kptfile == nil {
-			r.l.Error(fmt.Errorf("mandatory Kptfile is missing from the package"), "")
-			return ctrl.Result{}, nil
-		}
-
-		kptf := kptfilelibv1.KptFile{Kptfile: rl.Items.GetRootKptfile()}
-		pr.Status.Conditions = getPorchConditions(kptf.GetConditions())
-		if err = r.porchClient.Update(ctx, prr); err != nil {
-			return ctrl.Result{}, err
-		}
-
-	}
-	return ctrl.Result{}, nil
+// HasConditions filters the package revisions with a condition of the For
+// resource, whether the condition is true or not
+func HasConditions(ref corev1.ObjectReference) predicate.Predicate {
+	ct := kptfilelibv1.GetConditionType(&ref)
+	return predicate.NewPredicateFuncs(func(o client.Object) bool {
+		pr, ok := o.(*porchv1alpha1.PackageRevision)
+		return ok && hasSpecificTypeConditions(pr.Status.Conditions, ct)
+	})
 }
@@ -0,0 +1,50 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package specializerreconciler
+
+import (
+	"testing"
+
+	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestHasConditions(t *testing.T) {
+	cases := map[string]struct {
+		conditionType string
+		want          bool
+	}{
+		"For":   {conditionType: "test.nephio.org/v1alpha1.A.a1", want: true},
+		"Other": {conditionType: "test.nephio.org/v1alpha1.B.b1"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			pr := &porchv1alpha1.PackageRevision{
+				ObjectMeta: metav1.ObjectMeta{Name: "pr", Namespace: "default"},
+				Status: porchv1alpha1.PackageRevisionStatus{
+					Conditions: []porchv1alpha1.Condition{{Type: tc.conditionType}},
+				},
+			}
+			if got := HasConditions(corev1.ObjectReference{APIVersion: "test.nephio.org/v1alpha1", Kind: "A"}).Generic(event.GenericEvent{Object: pr}); got != tc.want {
+				t.Errorf("want %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/readiness"
 	porchclient "github.com/nephio-project/nephio/controllers/pkg/porch/client"
 	ctrlrconfig "github.com/nephio-project/nephio/controllers/pkg/reconcilers/config"
 	reconciler "github.com/nephio-project/nephio/controllers/pkg/reconcilers/reconciler-interface"
@@ -53,11 +54,9 @@ import (
 	_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/bootstrap-secret"
 	_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/generic-specializer"
 	_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/network"
-
-	//_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/ipam-specializer"
+	_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/network-config"
 	_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/repository"
 	_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/token"
-	//_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/vlan-specializer"
 )
 
 var (
@@ -69,6 +68,9 @@ func main() {
 	var enableLeaderElection bool
 	var probeAddr string
 	var enabledReconcilersString string
+	var clusterReadiness readiness.Options
+	var clusterNamespaces, clusterCRDs string
+	var enableWebhooks bool
 
 	//klog.InitFlags(nil)
 
@@ -78,6 +80,11 @@ func main() {
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.StringVar(&enabledReconcilersString, "reconcilers", "", "reconcilers that should be enabled; use * to mean 'enable all'")
+	flag.DurationVar(&clusterReadiness.APITimeout, "cluster-api-timeout", 0, "timeout of the api server reachability check of remote clusters; 0 disables the check")
+	flag.IntVar(&clusterReadiness.MinReadyNodes, "cluster-min-ready-nodes", 0, "minimum amount of ready nodes for a remote cluster to be ready")
+	flag.StringVar(&clusterNamespaces, "cluster-required-namespaces", "", "comma separated namespaces that must exist for a remote cluster to be ready")
+	flag.StringVar(&clusterCRDs, "cluster-required-crds", "", "comma separated crds that must be installed for a remote cluster to be ready")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false, "register the admission webhooks of the enabled reconcilers; requires the serving certificate of the webhook server")
 
 	opts := zap.Options{
 		Development: true,
@@ -147,6 +154,8 @@ func main() {
 		backendAddress = address
 	}
 
+	clusterReadiness.Namespaces = parseList(clusterNamespaces)
+	clusterReadiness.CRDs = parseList(clusterCRDs)
 	ctrlCfg := &ctrlrconfig.ControllerConfig{
 		Address:         backendAddress,
 		PorchClient:     porchClient,
@@ -157,20 +166,25 @@ func main() {
 		VlanClientProxy: vlan.New(ctx, clientproxy.Config{
 			Address: backendAddress,
 		}),
+		ClusterReadiness: clusterReadiness,
+		EnableWebhooks:   enableWebhooks,
 	}
 
 	enabledReconcilers := parseReconcilers(enabledReconcilersString)
 	var enabled []string
-	for name, r := range reconciler.Reconcilers {
-		if !reconcilerIsEnabled(enabledReconcilers, name) {
-			continue
+	for name := range reconciler.Reconcilers {
+		if reconcilerIsEnabled(enabledReconcilers, name) {
+			enabled = append(enabled, name)
 		}
-		if _, err = r.SetupWithManager(ctx, mgr, ctrlCfg); err != nil {
+	}
+	// reconcilers can check which other reconcilers are enabled
+	ctrlCfg.EnabledReconcilers = enabled
+	for _, name := range enabled {
+		if _, err = reconciler.Reconcilers[name].SetupWithManager(ctx, mgr, ctrlCfg); err != nil {
 			setupLog.Error(err, "cannot setup with manager", "reconciler", name)
 			//klog.Errorf("error creating %q reconciler: %s", name, err.Error())
 			os.Exit(1)
 		}
-		enabled = append(enabled, name)
 	}
 
 	if len(enabled) == 0 {
@@ -202,6 +216,13 @@ func parseReconcilers(reconcilers string) []string {
 	return strings.Split(reconcilers, ",")
 }
 
+func parseList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
 func reconcilerIsEnabled(reconcilers []string, reconciler string) bool {
 
 	if slices.Contains(reconcilers, "*") {